@@ -3,19 +3,24 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/go-owl/owl"
 	"github.com/go-owl/owl/middleware"
 )
 
-// Example showing how to implement graceful shutdown manually.
-// This gives you full control over the shutdown process.
+// Example showing graceful shutdown with App.Run.
+// Startup failures, such as a port in use, and shutdown errors are
+// returned instead of exiting from a goroutine, so deferred cleanup runs.
 func main() {
+	if err := run(); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
 	app := owl.New(owl.AppConfig{
 		Name:    "GracefulAPI",
 		Version: "1.0.0",
@@ -32,38 +37,11 @@ func main() {
 		})
 	})
 
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:    ":8082",
-		Handler: app,
-	}
-
-	// Channel to listen for interrupt signals
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-
-	// Start server in goroutine
-	go func() {
-		log.Printf("Server starting on :8082")
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal
-	<-quit
-	log.Println("Shutting down server gracefully...")
-
-	// Create shutdown context with timeout (30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Serve until SIGINT or SIGTERM, then wait up to 30 seconds for
+	// in-flight requests. Cancel ctx to stop programmatically.
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-
-	// Attempt graceful shutdown
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
-	} else {
-		log.Println("Server stopped gracefully")
-	}
+	return app.Run(ctx, ":8082", owl.SupervisorConfig{ShutdownTimeout: 30 * time.Second})
 
 	// Try: curl http://localhost:8082/api/long-task
 	// Then Ctrl+C to see graceful shutdown waiting for request to complete
@@ -0,0 +1,170 @@
+package owl
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouteUsage holds the usage counters of a single method and route pattern.
+type RouteUsage struct {
+	Method    string    `json:"method"`
+	Pattern   string    `json:"pattern"`
+	Hits      uint64    `json:"hits"`
+	Errors    uint64    `json:"errors"` // Responses with a 5xx status
+	FirstHit  time.Time `json:"first_hit,omitempty"`
+	LastHit   time.Time `json:"last_hit,omitempty"`
+	ErrorRate float64   `json:"error_rate"`
+}
+
+// RouteReport is a snapshot of route usage produced by RouteAnalytics.
+type RouteReport struct {
+	Since     time.Time    `json:"since"`
+	Routes    []RouteUsage `json:"routes"`     // All routes that were hit, most hit first
+	Unused    []RouteUsage `json:"unused"`     // Registered routes never hit since Since
+	TopErrors []RouteUsage `json:"top_errors"` // Routes with errors, most errors first
+}
+
+// RouteAnalytics counts hits and errors per matched route pattern and
+// reports routes that were never hit, which helps pruning dead endpoints.
+//
+//	analytics := owl.NewRouteAnalytics()
+//	app.Use(analytics.Middleware)
+//	admin.Mount("/routes", analytics.Handler(app.Mux()))
+type RouteAnalytics struct {
+	mu    sync.Mutex
+	since time.Time
+	usage map[string]*RouteUsage
+}
+
+// NewRouteAnalytics returns an empty RouteAnalytics.
+func NewRouteAnalytics() *RouteAnalytics {
+	return &RouteAnalytics{since: time.Now(), usage: map[string]*RouteUsage{}}
+}
+
+// Middleware records every request that matched a route. It must be
+// installed with App.Use (or Mux.Use) so it sees the final route pattern.
+func (ra *RouteAnalytics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		rctx := RouteContext(r.Context())
+		if rctx == nil || len(rctx.RoutePatterns) == 0 {
+			return // unmatched request, e.g. a 404
+		}
+		ra.record(r.Method, rctx.RoutePattern(), sw.Status())
+	})
+}
+
+func (ra *RouteAnalytics) record(method, pattern string, status int) {
+	now := time.Now()
+	key := method + " " + pattern
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	u, ok := ra.usage[key]
+	if !ok {
+		u = &RouteUsage{Method: method, Pattern: pattern, FirstHit: now}
+		ra.usage[key] = u
+	}
+	u.Hits++
+	u.LastHit = now
+	if status >= 500 {
+		u.Errors++
+	}
+}
+
+// Reset clears all counters.
+func (ra *RouteAnalytics) Reset() {
+	ra.mu.Lock()
+	ra.since = time.Now()
+	ra.usage = map[string]*RouteUsage{}
+	ra.mu.Unlock()
+}
+
+// Report returns the current usage. Registered routes are read from
+// routes to find the ones never hit.
+func (ra *RouteAnalytics) Report(routes Routes) RouteReport {
+	ra.mu.Lock()
+	report := RouteReport{Since: ra.since}
+	for _, u := range ra.usage {
+		c := *u
+		c.ErrorRate = float64(c.Errors) / float64(c.Hits)
+		report.Routes = append(report.Routes, c)
+		if c.Errors > 0 {
+			report.TopErrors = append(report.TopErrors, c)
+		}
+	}
+	used := make(map[string]bool, len(ra.usage))
+	for k := range ra.usage {
+		used[k] = true
+	}
+	ra.mu.Unlock()
+
+	if routes != nil {
+		seen := map[string]bool{}
+		_ = Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			key := method + " " + cleanRoutePattern(route)
+			if !used[key] && !seen[key] {
+				seen[key] = true
+				report.Unused = append(report.Unused, RouteUsage{Method: method, Pattern: cleanRoutePattern(route)})
+			}
+			return nil
+		})
+	}
+
+	sort.Slice(report.Routes, func(i, j int) bool { return report.Routes[i].Hits > report.Routes[j].Hits })
+	sort.Slice(report.TopErrors, func(i, j int) bool { return report.TopErrors[i].Errors > report.TopErrors[j].Errors })
+	sort.Slice(report.Unused, func(i, j int) bool {
+		if report.Unused[i].Pattern != report.Unused[j].Pattern {
+			return report.Unused[i].Pattern < report.Unused[j].Pattern
+		}
+		return report.Unused[i].Method < report.Unused[j].Method
+	})
+	return report
+}
+
+// Handler serves the report for routes as JSON.
+func (ra *RouteAnalytics) Handler(routes Routes) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(ra.Report(routes))
+	})
+}
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Status returns the written status code, http.StatusOK if none was
+// written explicitly.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
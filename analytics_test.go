@@ -0,0 +1,36 @@
+package owl
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteAnalytics(t *testing.T) {
+	ra := NewRouteAnalytics()
+	app := New()
+	app.Use(ra.Middleware)
+	app.GET("/users/{id}", func(c *Ctx) error { return c.Text("user") })
+	app.GET("/broken", func(c *Ctx) error { return errors.New("boom") })
+	app.POST("/never", func(c *Ctx) error { return nil })
+
+	for _, path := range []string{"/users/1", "/users/2", "/broken", "/missing"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	report := ra.Report(app.Mux())
+	if len(report.Routes) != 2 || report.Routes[0].Pattern != "/users/{id}" || report.Routes[0].Hits != 2 {
+		t.Fatalf("unexpected routes: %+v", report.Routes)
+	}
+	if len(report.TopErrors) != 1 || report.TopErrors[0].Pattern != "/broken" || report.TopErrors[0].ErrorRate != 1 {
+		t.Fatalf("unexpected top errors: %+v", report.TopErrors)
+	}
+	if len(report.Unused) != 1 || report.Unused[0].Method != "POST" || report.Unused[0].Pattern != "/never" {
+		t.Fatalf("unexpected unused routes: %+v", report.Unused)
+	}
+
+	ra.Reset()
+	if r := ra.Report(nil); len(r.Routes) != 0 {
+		t.Fatalf("expected empty report after reset, got %+v", r.Routes)
+	}
+}
@@ -2,19 +2,63 @@ package owl
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // App is the main DX application.
 type App struct {
-	mux          *Mux
+	mux          RouterBackend // Routing engine (default: *Mux)
 	errorHandler ErrorHandler
-	middlewares  []Middleware
-	name         string       // Server name (default: "Owl")
-	version      string       // Server version (default: Version constant)
-	bodyLimit    int64        // Max request body size in bytes (default: 10MB)
-	server       *http.Server // HTTP server instance for shutdown
+	scope        *scope                              // Owl-style middlewares of the App, see Use
+	name         string                              // Server name (default: "Owl")
+	version      string                              // Server version (default: Version constant)
+	bodyLimit    int64                               // Max request body size in bytes (default: 10MB)
+	multipartMem int64                               // See AppConfig.MultipartMemory
+	strictJSON   bool                                // Reject unknown fields and trailing data in JSON bodies
+	validateJSON bool                                // See AppConfig.ValidateJSON
+	strictRoutes bool                                // See AppConfig.StrictRoutes
+	server       *http.Server                        // Server of the last call to Listen, see Server
+	servers      []*http.Server                      // Servers of every call to Listen, for shutdown
+	serversMu    sync.Mutex                          // Guards server and servers
+	transport    Transport                           // Custom transport (default: net/http)
+	hosts        []*hostRoute                        // Host-specific routing trees, see Host
+	routes       map[routeKey]*routeSet              // Handlers by method and path, see When
+	last         []*conditionalRoute                 // Routes of the last registration, see When
+	onDeprecated func(c *Ctx, field, message string) // See AppConfig.OnDeprecatedField
+	mounted      []*App                              // Apps attached with Mount, built along with this one
+	secrets      *secretCache                        // See AppConfig.Secrets
+	logLevels    logLevels                           // See SetLogLevel
+	state        interface{}                         // See NewWith
+	logger       *slog.Logger                        // See AppConfig.Logger
+	noBanner     bool                                // See AppConfig.DisableBanner
+	noColor      bool                                // See AppConfig.NoColor
+	devMode      bool                                // See AppConfig.DevMode
+	serverOpts   serverOptions                       // See AppConfig.ReadTimeout
+	tlsConfig    *tls.Config                         // See AppConfig.TLSConfig
+	configure    func(*http.Server)                  // See AppConfig.ConfigureServer
+	connLimiter  *connLimiter                        // See AppConfig.MaxConns, nil when unlimited
+
+	maintenanceOnce sync.Once
+	maintenance     atomic.Pointer[Maintenance] // See Maintenance
+	healthOnce      sync.Once
+	health          atomic.Pointer[Health] // See Health
+	draining        atomic.Bool            // See Drain
+	inFlight        atomic.Int64           // See InFlight
+	idle            idleConns              // See CloseIdleConns
+	hooksOnce       sync.Once
+	hooks           atomic.Pointer[Hooks] // See Hooks
+
+	buildMu sync.Mutex
+	stale   atomic.Bool // Routes or middlewares changed since handlers were compiled
+	built   atomic.Bool // Set by Build, registration is frozen
 }
 
 // AppConfig holds configuration for creating a new App.
@@ -22,6 +66,127 @@ type AppConfig struct {
 	Name      string // Server name (default: "Owl")
 	Version   string // Server version (default: owl.Version)
 	BodyLimit int64  // Max request body size in bytes (default: 10MB, 0 = unlimited)
+
+	// MultipartMemory is the part of multipart bodies kept in memory by
+	// c.Bind().MultipartForm and c.Bind().File; file parts beyond it are
+	// spooled to temporary files in os.TempDir, set with TMPDIR (default:
+	// 32MB). The temporary files are removed once the handler returns.
+	MultipartMemory int64
+
+	// StrictJSON makes c.Bind().JSON reject unknown fields and trailing
+	// data after the JSON value. Groups and routes can override both
+	// BodyLimit and StrictJSON.
+	StrictJSON bool
+
+	// ValidateJSON makes c.Bind().JSON validate bodies against the JSON
+	// Schema of the destination type (see SchemaOf) before decoding them,
+	// answering 422 Unprocessable Entity with the path of each mismatch.
+	// Groups and routes can override it.
+	ValidateJSON bool
+
+	// OnDeprecatedField is called when a client sends a field that the
+	// binding struct tags as deprecated, e.g.
+	//
+	//	OldName string `json:"old_name" deprecated:"use new_name"`
+	//
+	// A Warning header is always added to the response; the hook is the
+	// place to count usages in metrics so legacy fields can be retired.
+	OnDeprecatedField func(c *Ctx, field, message string)
+
+	// AutoHead answers HEAD requests with the GET handler, without a body,
+	// for routes that have no HEAD handler of their own.
+	AutoHead bool
+
+	// AutoOptions answers OPTIONS requests for routes without an OPTIONS
+	// handler with 204 No Content and an Allow header listing the route's
+	// methods. Middlewares such as CORS still see the request first.
+	AutoOptions bool
+
+	// TrailingSlash sets how paths differing from a route only by a
+	// trailing slash are handled (default: TrailingSlashStrict).
+	TrailingSlash TrailingSlashPolicy
+
+	// CaseInsensitive matches request paths regardless of ASCII letter
+	// case. Param values keep the case of the request, but {param:regexp}
+	// constraints are matched against the lower-cased path.
+	CaseInsensitive bool
+
+	// Timeouts and header limit of the net/http server created by Start,
+	// Listen and Supervisor, see http.Server. They are ignored by custom
+	// Transports. ReadHeaderTimeout defaults to 10s so that slow clients
+	// can't hold connections open; a negative value disables it. The others
+	// default to 0, no timeout, since WriteTimeout also cuts streamed
+	// responses short.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int // Default: http.DefaultMaxHeaderBytes (1MB)
+
+	// MaxConns and MaxConnsPerIP cap the open connections of the server,
+	// in total and by client IP, to resist connection exhaustion without a
+	// proxy (default: 0, unlimited). Connections over a cap are closed when
+	// accepted. Behind a proxy every client shares its IP, so leave
+	// MaxConnsPerIP unset there.
+	MaxConns      int
+	MaxConnsPerIP int
+
+	// ConnState is called when a connection of the server changes state,
+	// see http.Server.ConnState, e.g. to export connection metrics.
+	ConnState func(conn net.Conn, state http.ConnState)
+
+	// TLSConfig configures the TLS servers of StartTLS and
+	// Supervisor.AddTLS, e.g. MinVersion, CipherSuites or GetCertificate
+	// (default: nil, meaning the net/http defaults).
+	TLSConfig *tls.Config
+
+	// ConfigureServer customizes the net/http server created by Start,
+	// Listen and Supervisor once the settings above are applied, e.g. its
+	// ConnContext, BaseContext or ErrorLog. Handler and Addr are set by
+	// Owl and shouldn't be replaced.
+	ConfigureServer func(srv *http.Server)
+
+	// Transport replaces the net/http server used by Start (default: nil,
+	// meaning net/http).
+	Transport Transport
+
+	// StrictRoutes makes Build fail on the conflicts found by CheckRoutes,
+	// instead of logging them as warnings.
+	StrictRoutes bool
+
+	// Router replaces the routing engine (default: nil, meaning a new
+	// Mux). AutoHead, AutoOptions, TrailingSlash and CaseInsensitive are
+	// features of Mux and are ignored for other backends.
+	Router RouterBackend
+
+	// Secrets resolves secret material such as cookie keys and JWT
+	// secrets, read with App.Secret (default: nil, no secrets).
+	Secrets SecretProvider
+
+	// SecretRefresh makes Start re-read the secrets in use at this
+	// interval, calling App.OnSecretRotate callbacks for those that
+	// changed (default: 0, never).
+	SecretRefresh time.Duration
+
+	// Logger receives the logs of the App in a structured form: server
+	// startup and shutdown, Ctx.Logf, errors answered with a 5xx by the
+	// default error handler, and the access logs of middleware.Logger
+	// (default: nil, meaning human-readable lines on the standard logger).
+	Logger *slog.Logger
+
+	// DisableBanner skips the "server starting" message logged when the
+	// App starts serving.
+	DisableBanner bool
+
+	// NoColor logs the startup message without ANSI colors. Colors are
+	// also left out when the NO_COLOR environment variable is set or the
+	// standard logger doesn't write to a terminal, e.g. in containers.
+	NoColor bool
+
+	// DevMode eases local development: the route table and the middleware
+	// chain of every route are logged at startup, and Ctx.JSON indents its
+	// output. It is ignored when OWL_ENV or APP_ENV is "production".
+	DevMode bool
 }
 
 // New creates a new App with optional configuration.
@@ -29,10 +194,12 @@ func New(config ...AppConfig) *App {
 	app := &App{
 		mux:          NewMux(),
 		errorHandler: defaultErrorHandler,
-		middlewares:  []Middleware{},
+		scope:        &scope{},
 		name:         "Owl",
 		version:      Version,
 		bodyLimit:    10 * MB, // 10MB default
+		multipartMem: 32 * MB,
+		routes:       map[routeKey]*routeSet{},
 	} // Apply config if provided
 	if len(config) > 0 {
 		cfg := config[0]
@@ -42,6 +209,48 @@ func New(config ...AppConfig) *App {
 		if cfg.Version != "" {
 			app.version = cfg.Version
 		}
+		app.strictJSON = cfg.StrictJSON
+		app.validateJSON = cfg.ValidateJSON
+		if cfg.MultipartMemory > 0 {
+			app.multipartMem = cfg.MultipartMemory
+		}
+		app.onDeprecated = cfg.OnDeprecatedField
+		app.transport = cfg.Transport
+		app.strictRoutes = cfg.StrictRoutes
+		app.logger = cfg.Logger
+		app.noBanner = cfg.DisableBanner
+		app.noColor = cfg.NoColor
+		app.devMode = cfg.DevMode && !IsProduction()
+		app.serverOpts = serverOptions{
+			readTimeout:       cfg.ReadTimeout,
+			readHeaderTimeout: cfg.ReadHeaderTimeout,
+			writeTimeout:      cfg.WriteTimeout,
+			idleTimeout:       cfg.IdleTimeout,
+			maxHeaderBytes:    cfg.MaxHeaderBytes,
+			connState:         cfg.ConnState,
+		}
+		if cfg.MaxConns > 0 || cfg.MaxConnsPerIP > 0 {
+			app.connLimiter = newConnLimiter(cfg.MaxConns, cfg.MaxConnsPerIP)
+		}
+		app.tlsConfig = cfg.TLSConfig
+		app.configure = cfg.ConfigureServer
+		if cfg.Secrets != nil {
+			app.secrets = &secretCache{
+				provider:  cfg.Secrets,
+				interval:  cfg.SecretRefresh,
+				values:    map[string][]byte{},
+				callbacks: map[string][]func([]byte){},
+			}
+		}
+		if cfg.Router != nil {
+			app.mux = cfg.Router
+		}
+		if mux, ok := app.mux.(*Mux); ok {
+			mux.autoHead = cfg.AutoHead
+			mux.autoOptions = cfg.AutoOptions
+			mux.trailingSlash = cfg.TrailingSlash
+			mux.caseInsensitive = cfg.CaseInsensitive
+		}
 		if cfg.BodyLimit > 0 {
 			app.bodyLimit = cfg.BodyLimit
 		} else if cfg.BodyLimit == 0 {
@@ -57,6 +266,10 @@ func New(config ...AppConfig) *App {
 // It automatically detects and applies the correct type:
 //   - func(http.Handler) http.Handler (chi/standard middleware)
 //   - func(Handler) Handler (Owl-style middleware)
+//
+// Owl-style middlewares apply to every route of the App and its groups,
+// including routes registered before Use: handler chains are assembled by
+// Build. They must be added before Build; Use panics otherwise.
 func (a *App) Use(middlewares ...interface{}) *App {
 	for _, mw := range middlewares {
 		switch m := mw.(type) {
@@ -65,7 +278,8 @@ func (a *App) Use(middlewares ...interface{}) *App {
 			a.mux.Use(m)
 		case Middleware:
 			// Owl-style middleware
-			a.middlewares = append(a.middlewares, m)
+			a.mutate("Use")
+			a.scope.middlewares = append(a.scope.middlewares, m)
 		default:
 			panic("middleware must be either func(http.Handler) http.Handler or func(Handler) Handler")
 		}
@@ -81,112 +295,418 @@ func (a *App) SetErrorHandler(h ErrorHandler) *App {
 
 // Group creates a route group with prefix and middlewares.
 func (a *App) Group(prefix string, middlewares ...Middleware) *Group {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(a.middlewares))
-	copy(mws, a.middlewares)
-	mws = append(mws, middlewares...)
-
 	return &Group{
-		app:         a,
-		prefix:      prefix,
-		middlewares: mws,
+		app:    a,
+		mux:    a.mux,
+		prefix: prefix,
+		scope:  a.scope.child(middlewares...),
+		opts:   a.routeDefaults(),
 	}
 }
 
-// Mux returns the underlying chi Mux for advanced usage or chi-style routing.
+// Mount attaches another App along prefix. The mounted App keeps its own
+// middleware stack, BodyLimit and error handler, which makes it possible to
+// ship self-contained feature modules (auth, billing) as separate Apps.
+//
+// Middlewares registered on the parent with Use as net/http middlewares run
+// before routing and therefore also wrap the mounted App; Owl-style
+// middlewares of the parent do not.
+func (a *App) Mount(prefix string, sub *App) *App {
+	if sub == nil {
+		panic("owl: attempting to Mount() a nil App on '" + prefix + "'")
+	}
+	if sub == a {
+		panic("owl: attempting to Mount() an App onto itself")
+	}
+	a.mutate("Mount")
+	a.mounted = append(a.mounted, sub)
+	mux, ok := a.mux.(*Mux)
+	if subMux, subOK := sub.mux.(*Mux); ok && subOK && len(sub.hosts) == 0 {
+		mux.Mount(prefix, subMux)
+		return a
+	}
+	h := http.StripPrefix(strings.TrimSuffix(prefix, "/"), sub)
+	a.mux.Handle(prefix, h)
+	a.mux.Handle(strings.TrimSuffix(prefix, "/")+"/*", h)
+	return a
+}
+
+// DevMode reports whether AppConfig.DevMode is in effect, e.g. to reload
+// templates on every request during development.
+func (a *App) DevMode() bool {
+	return a.devMode
+}
+
+// Mux returns the underlying chi Mux for advanced usage or chi-style
+// routing, or nil when the App uses another RouterBackend.
 func (a *App) Mux() *Mux {
+	mux, _ := a.mux.(*Mux)
+	return mux
+}
+
+// Router returns the routing engine of the App.
+func (a *App) Router() RouterBackend {
 	return a.mux
 }
 
 // ServeHTTP implements http.Handler.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := w.(*responseWriter); !ok {
+		w = &responseWriter{ResponseWriter: w}
+	}
+	defer a.track(w)()
+	if m := a.maintenance.Load(); m != nil && m.serve(w, r) {
+		return
+	}
+	if a.logger != nil {
+		r = r.WithContext(context.WithValue(r.Context(), loggerCtxKey, a.logger))
+	}
+	if len(a.hosts) > 0 && a.serveHost(w, r) {
+		return
+	}
+	if _, ok := a.mux.(*Mux); !ok && RouteContext(r.Context()) == nil {
+		// Other backends record URL params in a routing Context too, so
+		// that Ctx.Param keeps working.
+		r = r.WithContext(context.WithValue(r.Context(), RouteCtxKey, NewRouteContext()))
+	}
 	a.mux.ServeHTTP(w, r)
 }
 
-// Start starts the HTTP server (blocking).
+// Start builds the App and starts the HTTP server (blocking). It fails
+// without serving when Build reports an error.
 func (a *App) Start(addr string) error {
-	log.Printf("\033[92m%s\033[0m v%s server starting on \033[102;30m%s\033[0m", a.name, a.version, addr)
-	return http.ListenAndServe(addr, a)
+	if err := a.Build(); err != nil {
+		return err
+	}
+	if a.transport != nil {
+		a.refreshSecrets()
+		a.started(addr)
+		return a.transport.ListenAndServe(addr, a)
+	}
+	ln, err := listenTCP(addr)
+	if err != nil {
+		return err
+	}
+	return a.Serve(ln)
+}
+
+// Run serves the App on addr like Start, until ctx is done or SIGINT or
+// SIGTERM is received, then shuts it down gracefully. Errors are returned,
+// including those of the shutdown, so callers decide how to exit; nil
+// means a clean stop. It is a Supervisor of one App, whose config sets the
+// signals and the shutdown deadline:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	if err := app.Run(ctx, ":8080", owl.SupervisorConfig{ShutdownTimeout: 10 * time.Second}); err != nil {
+//		log.Print(err)
+//		os.Exit(1)
+//	}
+func (a *App) Run(ctx context.Context, addr string, config ...SupervisorConfig) error {
+	return NewSupervisor(config...).Add(addr, a).Run(ctx)
+}
+
+// StartTLS is Start serving HTTPS with the certificate and key of the PEM
+// files certFile and keyFile. Both can be empty when AppConfig.TLSConfig
+// provides the certificates. Custom Transports don't support TLS.
+func (a *App) StartTLS(addr, certFile, keyFile string) error {
+	if a.transport != nil {
+		return errors.New("owl: StartTLS isn't supported by custom Transports")
+	}
+	if err := a.Build(); err != nil {
+		return err
+	}
+	if addr == "" {
+		addr = ":https"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return a.ServeTLS(ln, certFile, keyFile)
 }
 
 // Listen starts the HTTP server and returns it for external management.
 // Useful for frameworks like uberfx that manage server lifecycle.
 // Similar to Fiber's Listen() method.
 func (a *App) Listen(addr string) *http.Server {
+	readHeaderTimeout := a.serverOpts.readHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = 10 * time.Second
+	}
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: a,
+		Addr:              addr,
+		Handler:           a,
+		ReadTimeout:       a.serverOpts.readTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      a.serverOpts.writeTimeout,
+		IdleTimeout:       a.serverOpts.idleTimeout,
+		MaxHeaderBytes:    a.serverOpts.maxHeaderBytes,
+		TLSConfig:         a.tlsConfig.Clone(),
+		ConnState:         a.connStateHook(),
+	}
+	if a.configure != nil {
+		a.configure(srv)
 	}
-	a.server = srv // Store for Shutdown()
+	a.serversMu.Lock()
+	a.server = srv
+	a.servers = append(a.servers, srv) // Every one is shut down, see ShutdownWithContext
+	a.serversMu.Unlock()
 	return srv
 }
 
+// Server returns the net/http server created by the last call to Listen,
+// also made by Start and Supervisor, or nil. Use AppConfig.ConfigureServer
+// to change its settings before it serves.
+func (a *App) Server() *http.Server {
+	a.serversMu.Lock()
+	defer a.serversMu.Unlock()
+	return a.server
+}
+
+// httpServers returns the servers created by Listen.
+func (a *App) httpServers() []*http.Server {
+	a.serversMu.Lock()
+	defer a.serversMu.Unlock()
+	return append([]*http.Server(nil), a.servers...)
+}
+
+// serverOptions are the net/http server tunables of AppConfig.
+type serverOptions struct {
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	connState         func(net.Conn, http.ConnState)
+}
+
 // Shutdown gracefully shuts down the server.
 // Compatible with uberfx lifecycle hooks.
 // Similar to Fiber's Shutdown() method.
 func (a *App) Shutdown() error {
-	if a.server == nil {
-		return nil // No server to shutdown
+	return a.ShutdownWithContext(context.Background())
+}
+
+// ShutdownWithContext gracefully shuts down the server, or every server
+// created by Listen when the App serves several addresses, giving up when
+// ctx is done. Lifecycle managers that pass a stop deadline should use
+// this. The App is drained first, see Drain.
+func (a *App) ShutdownWithContext(ctx context.Context) error {
+	a.Drain()
+	a.stopSecrets()
+	a.runShutdownStart()
+	var err error
+	if a.transport != nil {
+		err = a.transport.Shutdown(ctx)
+	} else {
+		servers := a.httpServers()
+		errs := make([]error, len(servers))
+		var wg sync.WaitGroup
+		for i, srv := range servers {
+			wg.Add(1)
+			go func(i int, srv *http.Server) {
+				defer wg.Done()
+				errs[i] = srv.Shutdown(ctx)
+			}(i, srv)
+		}
+		wg.Wait()
+		err = errors.Join(errs...)
 	}
-	return a.server.Shutdown(context.Background())
+	a.runShutdownDone(err)
+	return err
 }
 
 // HTTP Method shortcuts for convenience
 
 // GET registers a GET handler.
 func (a *App) GET(path string, h Handler, middlewares ...Middleware) *App {
-	handler := chainMiddlewares(h, middlewares...)
-	a.mux.Get(path, a.wrapHandler(handler))
+	a.last = a.register(a.mux, []string{http.MethodGet}, path, h, a.scope.child(middlewares...), a.routeDefaults())
 	return a
 }
 
 // POST registers a POST handler.
 func (a *App) POST(path string, h Handler, middlewares ...Middleware) *App {
-	handler := chainMiddlewares(h, middlewares...)
-	a.mux.Post(path, a.wrapHandler(handler))
+	a.last = a.register(a.mux, []string{http.MethodPost}, path, h, a.scope.child(middlewares...), a.routeDefaults())
 	return a
 }
 
 // PUT registers a PUT handler.
 func (a *App) PUT(path string, h Handler, middlewares ...Middleware) *App {
-	handler := chainMiddlewares(h, middlewares...)
-	a.mux.Put(path, a.wrapHandler(handler))
+	a.last = a.register(a.mux, []string{http.MethodPut}, path, h, a.scope.child(middlewares...), a.routeDefaults())
 	return a
 }
 
 // PATCH registers a PATCH handler.
 func (a *App) PATCH(path string, h Handler, middlewares ...Middleware) *App {
-	handler := chainMiddlewares(h, middlewares...)
-	a.mux.Patch(path, a.wrapHandler(handler))
+	a.last = a.register(a.mux, []string{http.MethodPatch}, path, h, a.scope.child(middlewares...), a.routeDefaults())
 	return a
 }
 
 // DELETE registers a DELETE handler.
 func (a *App) DELETE(path string, h Handler, middlewares ...Middleware) *App {
-	handler := chainMiddlewares(h, middlewares...)
-	a.mux.Delete(path, a.wrapHandler(handler))
+	a.last = a.register(a.mux, []string{http.MethodDelete}, path, h, a.scope.child(middlewares...), a.routeDefaults())
 	return a
 }
 
+// ANY registers a handler for every HTTP method.
+func (a *App) ANY(path string, h Handler, middlewares ...Middleware) *App {
+	a.last = a.register(a.mux, []string{""}, path, h, a.scope.child(middlewares...), a.routeDefaults())
+	return a
+}
+
+// Match registers a handler for each of the given HTTP methods.
+func (a *App) Match(methods []string, path string, h Handler, middlewares ...Middleware) *App {
+	a.last = a.register(a.mux, methods, path, h, a.scope.child(middlewares...), a.routeDefaults())
+	return a
+}
+
+// routeOptions holds the per-route settings applied around a route's
+// handler. Groups and RouteBuilders take a copy from their parent, like
+// middlewares, and can override them for their own routes.
+type routeOptions struct {
+	bodyLimit    int64                  // Max request body size in bytes (0 = unlimited)
+	strictJSON   bool                   // See AppConfig.StrictJSON
+	validateJSON bool                   // See AppConfig.ValidateJSON
+	meta         map[string]interface{} // Route metadata, see Group.Meta
+	tags         []string               // Route tags, see Group.Tag
+	timeout      time.Duration          // Handler deadline, see Group.Timeout
+	attrs        []Attr                 // Observability attributes, see Group.Attr
+}
+
+// withMeta returns a copy of o with key set to value. The map is copied so
+// that routes registered earlier keep their metadata.
+func (o routeOptions) withMeta(key string, value interface{}) routeOptions {
+	meta := make(map[string]interface{}, len(o.meta)+1)
+	for k, v := range o.meta {
+		meta[k] = v
+	}
+	meta[key] = value
+	o.meta = meta
+	return o
+}
+
+// withAttr returns a copy of o with the attribute key set to value.
+func (o routeOptions) withAttr(key, value string) routeOptions {
+	attrs := make([]Attr, 0, len(o.attrs)+1)
+	for _, a := range o.attrs {
+		if a.Key != key {
+			attrs = append(attrs, a)
+		}
+	}
+	o.attrs = append(attrs, Attr{Key: key, Value: value})
+	return o
+}
+
+// withTags returns a copy of o with tags added.
+func (o routeOptions) withTags(tags ...string) routeOptions {
+	all := make([]string, 0, len(o.tags)+len(tags))
+	all = append(all, o.tags...)
+	o.tags = append(all, tags...)
+	return o
+}
+
+// routeDefaults returns the App-wide route options.
+func (a *App) routeDefaults() routeOptions {
+	return routeOptions{bodyLimit: a.bodyLimit, strictJSON: a.strictJSON, validateJSON: a.validateJSON}
+}
+
+// register registers h on mux for each method and path, with the
+// middlewares of sc chained around it once the App is built. It is the
+// common path of all Owl-style route registration and returns the
+// registered routes, one per method.
+func (a *App) register(mux RouterBackend, methods []string, path string, h Handler, sc *scope, opts routeOptions) []*conditionalRoute {
+	a.mutate("Route registration")
+	routes := make([]*conditionalRoute, len(methods))
+	for i, m := range methods {
+		routes[i] = a.handle(mux, m, path, h, sc, opts)
+	}
+	return routes
+}
+
+// handle registers h on mux for method ("" matches all methods) and path.
+// Every Owl-style registration goes through here so that path syntax only
+// understood by Owl, such as named catch-alls, is translated in one place.
+//
+// Handlers registered for the same method and path are kept together in a
+// routeSet, so that conditional routes (see When) can share a path.
+func (a *App) handle(mux RouterBackend, method, path string, h Handler, sc *scope, opts routeOptions) *conditionalRoute {
+	source := callSite()
+	a.runRoute(RouteInfo{Method: method, Path: path, Host: a.hostPattern(mux), Source: source})
+	path, wildcard := splitWildcard(path)
+	route := &conditionalRoute{h: h, scope: sc, opts: opts, wildcard: wildcard, source: source, doc: handlerDoc(h, method)}
+	key := routeKey{mux: mux, method: method, path: path}
+	if set := a.routes[key]; set != nil {
+		set.routes = append(set.routes, route)
+		return route
+	}
+	set := &routeSet{app: a, mux: mux, routes: []*conditionalRoute{route}}
+	a.routes[key] = set
+	if method == "" {
+		mux.Handle(path, set)
+	} else {
+		mux.Method(method, path, set)
+	}
+	return route
+}
+
+// splitWildcard turns a named catch-all such as "/files/*filepath" into the
+// router's "/files/*" and returns the name.
+func splitWildcard(path string) (string, string) {
+	i := strings.LastIndex(path, "/*")
+	if i < 0 || i+2 == len(path) || strings.ContainsAny(path[i+2:], "/{}") {
+		return path, ""
+	}
+	return path[:i+2], path[i+2:]
+}
+
 // wrapHandler converts DX Handler to http.HandlerFunc.
-func (a *App) wrapHandler(h Handler) http.HandlerFunc {
+func (a *App) wrapHandler(h Handler, opts routeOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Apply body limit if configured
-		if a.bodyLimit > 0 {
-			r.Body = http.MaxBytesReader(w, r.Body, a.bodyLimit)
+		if opts.bodyLimit > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, opts.bodyLimit)
 		}
 
-		c := newCtx(w, r)
-		if err := h(c); err != nil {
-			a.errorHandler(c, err)
+		if opts.timeout > 0 {
+			a.serveTimeout(w, r, h, opts)
+			return
+		}
+		a.serve(w, r, h, opts)
+	}
+}
+
+// serve runs h for the request and hands errors to the error handler.
+func (a *App) serve(w http.ResponseWriter, r *http.Request, h Handler, opts routeOptions) {
+	rctx := RouteContext(r.Context())
+	if rctx != nil {
+		rctx.routeAttrs = opts.attrs
+		rctx.logLevel, rctx.hasLogLevel = a.routeLogLevel(rctx), true
+	}
+	c := newCtx(w, r)
+	c.route = opts
+	c.app = a
+	c.rctx, c.rctxReq = rctx, r
+	if err := h(c); err != nil {
+		a.errorHandler(c, err)
+	}
+
+	// net/http only cleans up the multipart form of the request it
+	// created, not of the copies made by WithContext.
+	for _, req := range []*http.Request{r, c.Request} {
+		if req.MultipartForm != nil {
+			req.MultipartForm.RemoveAll()
 		}
 	}
 }
 
-// chainMiddlewares chains middlewares (pre-compiled at registration).
-func chainMiddlewares(h Handler, middlewares ...Middleware) Handler {
+// chainMiddlewares chains middlewares (pre-compiled by Build) and returns
+// their names, outermost first, see Named.
+func chainMiddlewares(h Handler, middlewares ...Middleware) (Handler, []string) {
+	names := make([]string, len(middlewares))
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		h = middlewares[i](h)
+		names[i] = middlewareNameOf(middlewares[i])
 	}
-	return h
+	return h, names
 }
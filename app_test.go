@@ -2,10 +2,14 @@ package owl
 
 import (
 	"bytes"
+	"context"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBodyLimit(t *testing.T) {
@@ -78,3 +82,280 @@ func TestDefaultBodyLimit(t *testing.T) {
 		t.Errorf("Expected default body limit 10MB (%d), got %d", expectedLimit, app.bodyLimit)
 	}
 }
+
+func TestShutdownWithContext(t *testing.T) {
+	app := New()
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		t.Fatalf("expected nil error without server, got %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(app)
+	app.server = srv.Config
+	srv.Start()
+	defer srv.Close()
+
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+}
+
+func TestServerTunables(t *testing.T) {
+	srv := New().Listen(":0")
+	if srv.ReadHeaderTimeout != 10*time.Second || srv.ReadTimeout != 0 || srv.WriteTimeout != 0 {
+		t.Fatalf("unexpected default timeouts %+v", srv)
+	}
+
+	srv = New(AppConfig{
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       time.Minute,
+		MaxHeaderBytes:    64 << 10,
+	}).Listen(":0")
+	if srv.ReadTimeout != 5*time.Second || srv.ReadHeaderTimeout != 2*time.Second || srv.WriteTimeout != 30*time.Second ||
+		srv.IdleTimeout != time.Minute || srv.MaxHeaderBytes != 64<<10 {
+		t.Fatalf("expected the configured tunables, got %+v", srv)
+	}
+}
+
+func TestConfigureServer(t *testing.T) {
+	errorLog := log.New(io.Discard, "", 0)
+	app := New(AppConfig{
+		WriteTimeout: time.Second,
+		ConfigureServer: func(srv *http.Server) {
+			if srv.WriteTimeout != time.Second {
+				t.Errorf("expected the tunables to be applied first, got %v", srv.WriteTimeout)
+			}
+			srv.ErrorLog = errorLog
+		},
+	})
+	if app.Server() != nil {
+		t.Fatal("expected no server before Listen")
+	}
+	srv := app.Listen(":0")
+	if app.Server() != srv || srv.ErrorLog != errorLog {
+		t.Fatalf("expected the configured server, got %+v", app.Server())
+	}
+}
+
+func TestMountApp(t *testing.T) {
+	parent := New()
+	parent.SetErrorHandler(func(c *Ctx, err error) {
+		_ = Text(c.Response, http.StatusTeapot, "parent")
+	})
+	parent.GET("/ping", func(c *Ctx) error { return c.Text("pong") })
+
+	admin := New(AppConfig{BodyLimit: 8})
+	admin.SetErrorHandler(func(c *Ctx, err error) {
+		_ = Text(c.Response, http.StatusBadRequest, "admin: "+err.Error())
+	})
+	admin.Use(Middleware(func(next Handler) Handler {
+		return func(c *Ctx) error {
+			c.SetHeader("X-Admin", "1")
+			return next(c)
+		}
+	}))
+	admin.Group("").POST("/users", func(c *Ctx) error {
+		var body string
+		if err := c.Bind().Text(&body); err != nil {
+			return err
+		}
+		return c.Text(body)
+	})
+
+	parent.Mount("/admin", admin)
+
+	w := httptest.NewRecorder()
+	parent.ServeHTTP(w, httptest.NewRequest("POST", "/admin/users", strings.NewReader("short")))
+	if w.Code != http.StatusOK || w.Body.String() != "short" || w.Header().Get("X-Admin") != "1" {
+		t.Fatalf("unexpected mounted response: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	parent.ServeHTTP(w, httptest.NewRequest("POST", "/admin/users", strings.NewReader("far too long")))
+	if w.Code != http.StatusBadRequest || !strings.HasPrefix(w.Body.String(), "admin: ") {
+		t.Fatalf("expected mounted app's body limit and error handler, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	parent.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Body.String() != "pong" {
+		t.Fatalf("parent route broken: %q", w.Body.String())
+	}
+}
+
+type testTransport struct {
+	addr     string
+	handler  http.Handler
+	shutdown bool
+}
+
+func (t *testTransport) ListenAndServe(addr string, h http.Handler) error {
+	t.addr, t.handler = addr, h
+	return nil
+}
+
+func (t *testTransport) Shutdown(ctx context.Context) error {
+	t.shutdown = true
+	return nil
+}
+
+func TestCustomTransport(t *testing.T) {
+	tr := &testTransport{}
+	app := New(AppConfig{Transport: tr})
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+
+	if err := app.Start(":0"); err != nil {
+		t.Fatal(err)
+	}
+	if tr.addr != ":0" || tr.handler != app {
+		t.Fatalf("transport not used: %+v", tr)
+	}
+
+	w := httptest.NewRecorder()
+	tr.handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "ok" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+
+	if err := app.Shutdown(); err != nil || !tr.shutdown {
+		t.Fatalf("transport shutdown not called: %v", err)
+	}
+}
+
+func TestNamedWildcardAndParamTypes(t *testing.T) {
+	app := New()
+	app.GET("/files/*filepath", func(c *Ctx) error {
+		return c.Text(c.Param("filepath"))
+	})
+	app.GET("/users/{id:int}", func(c *Ctx) error {
+		return c.Text("user " + c.Param("id"))
+	})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/files/css/site.css", 200, "css/site.css"},
+		{"/users/12", 200, "user 12"},
+		{"/users/bob", 404, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q", tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAutoHeadAndOptions(t *testing.T) {
+	app := New(AppConfig{AutoHead: true, AutoOptions: true})
+	app.GET("/users", func(c *Ctx) error {
+		c.SetHeader("X-Total", "2")
+		return c.JSON([]string{"a", "b"})
+	})
+	app.POST("/users", func(c *Ctx) error { return nil })
+	app.Match([]string{"GET", "HEAD"}, "/explicit", func(c *Ctx) error {
+		return c.Text(c.Request.Method)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("HEAD", "/users", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("X-Total") != "2" {
+		t.Fatalf("unexpected HEAD response: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	app.GET("/events", func(c *Ctx) error {
+		c.Response.Write([]byte("data: 1\n\n"))
+		return http.NewResponseController(c.Response).Flush()
+	})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("HEAD", "/events", nil))
+	if w.Code != http.StatusOK || !w.Flushed || w.Body.Len() != 0 {
+		t.Fatalf("expected HEAD responses of streams to be flushed, got %d, flushed %v", w.Code, w.Flushed)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("HEAD", "/explicit", nil))
+	if w.Body.String() != "HEAD" {
+		t.Fatalf("explicit HEAD handler should win, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/users", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for OPTIONS, got %d", w.Code)
+	}
+	if got := strings.Join(w.Header().Values("Allow"), ","); got != "GET,HEAD,OPTIONS,POST" {
+		t.Fatalf("unexpected Allow header %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown path, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	New().GET("/users", func(c *Ctx) error { return nil }).ServeHTTP(w, httptest.NewRequest("HEAD", "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("HEAD should be 405 without AutoHead, got %d", w.Code)
+	}
+}
+
+func TestRouteLimitOverrides(t *testing.T) {
+	app := New(AppConfig{BodyLimit: 16})
+	echo := func(c *Ctx) error {
+		var body string
+		if err := c.Bind().Text(&body); err != nil {
+			return err
+		}
+		return c.Text(body)
+	}
+	bind := func(c *Ctx) error {
+		var v struct{ Name string }
+		if err := c.Bind().JSON(&v); err != nil {
+			return err
+		}
+		return c.Text(v.Name)
+	}
+
+	app.POST("/login", echo)
+	app.Group("/files").BodyLimit(64).POST("/", echo)
+	api := app.Group("/api").StrictJSON(true).BodyLimit(0)
+	api.POST("/strict", bind)
+	api.Route("/lenient").StrictJSON(false).POST(bind)
+
+	big := strings.Repeat("x", 32)
+	tests := []struct {
+		path, body string
+		code       int
+	}{
+		{"/login", big, 400},
+		{"/files/", big, 200},
+		{"/files/", strings.Repeat("x", 65), 400},
+		{"/api/strict", `{"name":"owl"}`, 200},
+		{"/api/strict", `{"name":"owl","admin":true}`, 400},
+		{"/api/strict", `{"name":"owl"} {}`, 400},
+		{"/api/strict", `{"name":"` + big + `"}`, 200},
+		{"/api/lenient", `{"name":"owl","admin":true}`, 200},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.code {
+			t.Errorf("POST %s %q: got %d, want %d (%s)", tt.path, tt.body, w.Code, tt.code, w.Body.String())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for BodyLimit after routes")
+		}
+	}()
+	g := app.Group("/late")
+	g.GET("/", echo)
+	g.BodyLimit(1)
+}
@@ -0,0 +1,428 @@
+// Package auth provides session based login and logout for Owl apps, with
+// remember-me tokens and session fixation protection:
+//
+//	app.POST("/login", auth.LoginHandler(func(user, pass string) (auth.Principal, error) {
+//		u, err := db.FindUser(user)
+//		if err != nil {
+//			return auth.Principal{}, auth.ErrInvalidCredentials
+//		}
+//		if ok, _ := cred.Verify(pass, u.PasswordHash); !ok {
+//			return auth.Principal{}, auth.ErrInvalidCredentials
+//		}
+//		return auth.Principal{ID: u.ID, Name: u.Name}, nil
+//	}))
+//	app.POST("/logout", auth.LogoutHandler())
+//
+//	account := app.Group("/account", auth.Require)
+//	account.GET("/", func(c *owl.Ctx) error {
+//		p, _ := auth.User(c)
+//		return c.JSON(p)
+//	})
+//
+// Passwords are best stored with the cred module (github.com/go-owl/owl/cred).
+// The package level functions use Default, which keeps sessions in memory.
+// Apps running several instances create their own Auth with New and a
+// shared Store.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+var (
+	// ErrInvalidCredentials is returned by verify functions for a wrong
+	// user name or password. LoginHandler answers it with a 401; any other
+	// error is passed on to the App's error handler.
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+
+	// ErrNotFound is returned by a Store for unknown or expired keys.
+	ErrNotFound = errors.New("auth: session not found")
+)
+
+// Principal is the authenticated user of a request.
+type Principal struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// HasRole reports whether p has role.
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures an Auth. The zero value is usable.
+type Config struct {
+	// Store keeps sessions and remember-me tokens (default: a MemoryStore).
+	Store Store
+
+	// CookieName is the session cookie (default: "owl_auth", apart from
+	// the cookie of middleware.Session).
+	CookieName string
+
+	// RememberCookieName is the remember-me cookie (default: "owl_remember").
+	RememberCookieName string
+
+	// TTL is the lifetime of a session (default: 12h).
+	TTL time.Duration
+
+	// RememberTTL is the lifetime of a remember-me token (default: 30 days).
+	RememberTTL time.Duration
+
+	// UsernameField, PasswordField and RememberField are the form fields
+	// read by LoginHandler (default: "username", "password" and "remember").
+	UsernameField string
+	PasswordField string
+	RememberField string
+
+	// LoginRedirect is where LoginHandler sends the browser after a login,
+	// unless the form carries a local "next" path. Without it, the
+	// Principal is answered as JSON.
+	LoginRedirect string
+
+	// LogoutRedirect is where LogoutHandler sends the browser. Without it,
+	// logouts are answered with a 204.
+	LogoutRedirect string
+
+	// LoginURL is where Require redirects unauthenticated GET requests
+	// accepting HTML, with the requested path in "next". Without it, or
+	// for other requests, Require answers with a 401.
+	LoginURL string
+
+	// Secure marks cookies as https only.
+	Secure bool
+
+	// SameSite of the cookies (default: http.SameSiteLaxMode).
+	SameSite http.SameSite
+}
+
+// Auth logs users in and out and loads the Principal of requests.
+type Auth struct {
+	cfg Config
+}
+
+// New returns an Auth using cfg.
+func New(cfg Config) *Auth {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "owl_auth"
+	}
+	if cfg.RememberCookieName == "" {
+		cfg.RememberCookieName = "owl_remember"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 12 * time.Hour
+	}
+	if cfg.RememberTTL <= 0 {
+		cfg.RememberTTL = 30 * 24 * time.Hour
+	}
+	if cfg.UsernameField == "" {
+		cfg.UsernameField = "username"
+	}
+	if cfg.PasswordField == "" {
+		cfg.PasswordField = "password"
+	}
+	if cfg.RememberField == "" {
+		cfg.RememberField = "remember"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &Auth{cfg: cfg}
+}
+
+// Default is the Auth used by the package level functions.
+var Default = New(Config{})
+
+// LoginHandler logs users in with Default, see Auth.LoginHandler.
+func LoginHandler(verify func(user, pass string) (Principal, error)) owl.Handler {
+	return Default.LoginHandler(verify)
+}
+
+// LogoutHandler logs users out with Default, see Auth.LogoutHandler.
+func LogoutHandler() owl.Handler {
+	return Default.LogoutHandler()
+}
+
+// Middleware loads the Principal of requests with Default, see
+// Auth.Middleware.
+func Middleware(next owl.Handler) owl.Handler {
+	return Default.Middleware(next)
+}
+
+// Require rejects unauthenticated requests with Default, see Auth.Require.
+func Require(next owl.Handler) owl.Handler {
+	return Default.Require(next)
+}
+
+// LoginHandler checks the user name and password posted in a form with
+// verify and starts a new session for the returned Principal. The previous
+// session of the client, if any, is dropped rather than reused so that a
+// session id planted before login is worthless after it. When the remember
+// field is checked, a remember-me token is issued too.
+func (a *Auth) LoginHandler(verify func(user, pass string) (Principal, error)) owl.Handler {
+	return func(c *owl.Ctx) error {
+		r := c.Request
+		p, err := verify(r.PostFormValue(a.cfg.UsernameField), r.PostFormValue(a.cfg.PasswordField))
+		if errors.Is(err, ErrInvalidCredentials) {
+			return owl.ErrUnauthorized
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := a.end(c); err != nil {
+			return err
+		}
+		if err := a.start(c, p); err != nil {
+			return err
+		}
+		if remember(r.PostFormValue(a.cfg.RememberField)) {
+			if err := a.remember(c, p); err != nil {
+				return err
+			}
+		}
+
+		target := a.cfg.LoginRedirect
+		if next := r.PostFormValue("next"); localPath(next) {
+			target = next
+		}
+		if target == "" {
+			return c.JSON(p)
+		}
+		http.Redirect(c.Response, r, target, http.StatusSeeOther)
+		return nil
+	}
+}
+
+// LogoutHandler ends the session and revokes the remember-me token of the
+// client.
+func (a *Auth) LogoutHandler() owl.Handler {
+	return func(c *owl.Ctx) error {
+		if err := a.end(c); err != nil {
+			return err
+		}
+		if a.cfg.LogoutRedirect == "" {
+			c.Response.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		http.Redirect(c.Response, c.Request, a.cfg.LogoutRedirect, http.StatusSeeOther)
+		return nil
+	}
+}
+
+// Middleware loads the Principal of the session, if any, for User. A
+// client whose session expired but holding a valid remember-me token gets
+// a new session, and a new token: tokens are single use, so a stolen one
+// stops working once its owner comes back.
+func (a *Auth) Middleware(next owl.Handler) owl.Handler {
+	return func(c *owl.Ctx) error {
+		if _, loaded := c.Request.Context().Value(principalKey{}).(*Principal); loaded {
+			return next(c)
+		}
+		p, err := a.load(c)
+		if err != nil {
+			return err
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), principalKey{}, p))
+		return next(c)
+	}
+}
+
+// Require runs Middleware and rejects requests without a Principal, see
+// Config.LoginURL.
+func (a *Auth) Require(next owl.Handler) owl.Handler {
+	return a.Middleware(func(c *owl.Ctx) error {
+		if _, ok := User(c); ok {
+			return next(c)
+		}
+		r := c.Request
+		if a.cfg.LoginURL != "" && r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(c.Response, r, a.cfg.LoginURL+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return nil
+		}
+		return owl.ErrUnauthorized
+	})
+}
+
+// User returns the Principal loaded by Middleware or Require.
+func User(c *owl.Ctx) (Principal, bool) {
+	p, _ := c.Request.Context().Value(principalKey{}).(*Principal)
+	if p == nil {
+		return Principal{}, false
+	}
+	return *p, true
+}
+
+type principalKey struct{}
+
+// load returns the Principal of the session cookie, falling back to the
+// remember-me cookie, or nil.
+func (a *Auth) load(c *owl.Ctx) (*Principal, error) {
+	if cookie, err := c.Request.Cookie(a.cfg.CookieName); err == nil {
+		p, expires, err := a.cfg.Store.Load(sessionKey(cookie.Value))
+		if err == nil && time.Now().Before(expires) {
+			return &p, nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	cookie, err := c.Request.Cookie(a.cfg.RememberCookieName)
+	if err != nil {
+		return nil, nil
+	}
+	key := rememberKey(cookie.Value)
+	p, expires, err := a.cfg.Store.Load(key)
+	if errors.Is(err, ErrNotFound) || (err == nil && !time.Now().Before(expires)) {
+		a.clearCookie(c, a.cfg.RememberCookieName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := a.cfg.Store.Delete(key); err != nil {
+		return nil, err
+	}
+	if err := a.start(c, p); err != nil {
+		return nil, err
+	}
+	if err := a.remember(c, p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// start stores a session for p under a fresh id and sets its cookie.
+func (a *Auth) start(c *owl.Ctx, p Principal) error {
+	id, err := token()
+	if err != nil {
+		return err
+	}
+	if err := a.cfg.Store.Save(sessionKey(id), p, time.Now().Add(a.cfg.TTL)); err != nil {
+		return err
+	}
+	a.setCookie(c, a.cfg.CookieName, id, 0)
+	return nil
+}
+
+// remember issues a remember-me token for p. Only its hash is stored.
+func (a *Auth) remember(c *owl.Ctx, p Principal) error {
+	t, err := token()
+	if err != nil {
+		return err
+	}
+	if err := a.cfg.Store.Save(rememberKey(t), p, time.Now().Add(a.cfg.RememberTTL)); err != nil {
+		return err
+	}
+	a.setCookie(c, a.cfg.RememberCookieName, t, a.cfg.RememberTTL)
+	return nil
+}
+
+// end deletes the session and remember-me token of the request and clears
+// their cookies.
+func (a *Auth) end(c *owl.Ctx) error {
+	if cookie, err := c.Request.Cookie(a.cfg.CookieName); err == nil {
+		if err := a.cfg.Store.Delete(sessionKey(cookie.Value)); err != nil {
+			return err
+		}
+		a.clearCookie(c, a.cfg.CookieName)
+	}
+	if cookie, err := c.Request.Cookie(a.cfg.RememberCookieName); err == nil {
+		if err := a.cfg.Store.Delete(rememberKey(cookie.Value)); err != nil {
+			return err
+		}
+		a.clearCookie(c, a.cfg.RememberCookieName)
+	}
+	return nil
+}
+
+func (a *Auth) setCookie(c *owl.Ctx, name, value string, maxAge time.Duration) {
+	http.SetCookie(c.Response, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   a.cfg.Secure,
+		HttpOnly: true,
+		SameSite: a.cfg.SameSite,
+	})
+}
+
+func (a *Auth) clearCookie(c *owl.Ctx, name string) {
+	http.SetCookie(c.Response, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   a.cfg.Secure,
+		HttpOnly: true,
+		SameSite: a.cfg.SameSite,
+	})
+}
+
+// sessionKey hashes the session ID id, like rememberKey.
+func sessionKey(id string) string {
+	return "session:" + hashToken(id)
+}
+
+// rememberKey hashes t, so that a leaked Store doesn't leak usable tokens.
+func rememberKey(t string) string {
+	return "remember:" + hashToken(t)
+}
+
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+func token() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func remember(v string) bool {
+	switch strings.ToLower(v) {
+	case "1", "on", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// localPath reports whether p is a path on this site, to avoid open
+// redirects through the "next" field. Browsers drop tabs and newlines and
+// read backslashes as slashes, so "/\t/evil.com" would lead to
+// "//evil.com": both are rejected anywhere in p.
+func localPath(p string) bool {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return false
+	}
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; c < 0x20 || c == 0x7f || c == '\\' {
+			return false
+		}
+	}
+	u, err := url.Parse(p)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
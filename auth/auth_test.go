@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func testApp(a *Auth) *owl.App {
+	app := owl.New()
+	app.POST("/login", a.LoginHandler(func(user, pass string) (Principal, error) {
+		if user != "ada" || pass != "secret" {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return Principal{ID: "1", Name: "Ada"}, nil
+	}))
+	app.POST("/logout", a.LogoutHandler())
+	app.GET("/me", func(c *owl.Ctx) error {
+		p, _ := User(c)
+		return c.Text(p.Name)
+	}, a.Require)
+	return app
+}
+
+func send(app *owl.App, method, path string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+// cookie returns the last cookie named name set by w.
+func cookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	var found *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == name {
+			found = c
+		}
+	}
+	return found
+}
+
+func TestLoginLogout(t *testing.T) {
+	a := New(Config{})
+	app := testApp(a)
+
+	if w := send(app, "POST", "/login", url.Values{"username": {"ada"}, "password": {"nope"}}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: got %d", w.Code)
+	}
+	if w := send(app, "GET", "/me", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous: got %d", w.Code)
+	}
+
+	planted := &http.Cookie{Name: "owl_auth", Value: "planted"}
+	w := send(app, "POST", "/login", url.Values{"username": {"ada"}, "password": {"secret"}}, planted)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"1"`) {
+		t.Fatalf("login: got %d %q", w.Code, w.Body.String())
+	}
+	session := cookie(w, "owl_auth")
+	if session == nil || session.Value == "" || session.Value == "planted" || !session.HttpOnly {
+		t.Fatalf("login should issue a fresh session cookie, got %+v", session)
+	}
+	if cookie(w, "owl_remember") != nil {
+		t.Fatal("remember-me token issued without asking")
+	}
+
+	if w := send(app, "GET", "/me", nil, session); w.Code != http.StatusOK || w.Body.String() != "Ada" {
+		t.Fatalf("me: got %d %q", w.Code, w.Body.String())
+	}
+	if _, _, err := a.cfg.Store.Load("session:" + session.Value); !errors.Is(err, ErrNotFound) {
+		t.Fatal("session IDs should only be stored hashed")
+	}
+
+	if w := send(app, "POST", "/logout", nil, session); w.Code != http.StatusNoContent {
+		t.Fatalf("logout: got %d", w.Code)
+	}
+	if w := send(app, "GET", "/me", nil, session); w.Code != http.StatusUnauthorized {
+		t.Fatalf("session should be gone after logout, got %d", w.Code)
+	}
+}
+
+func TestRememberMe(t *testing.T) {
+	a := New(Config{TTL: time.Millisecond})
+	app := testApp(a)
+
+	w := send(app, "POST", "/login", url.Values{"username": {"ada"}, "password": {"secret"}, "remember": {"on"}})
+	token := cookie(w, "owl_remember")
+	if token == nil || token.MaxAge <= 0 {
+		t.Fatalf("expected a persistent remember-me cookie, got %+v", token)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	w = send(app, "GET", "/me", nil, cookie(w, "owl_auth"), token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("remember-me login: got %d", w.Code)
+	}
+	rotated := cookie(w, "owl_remember")
+	if rotated == nil || rotated.Value == token.Value {
+		t.Fatalf("remember-me token should be rotated, got %+v", rotated)
+	}
+	if cookie(w, "owl_auth") == nil {
+		t.Fatal("remember-me login should start a session")
+	}
+
+	if w := send(app, "GET", "/me", nil, token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("used remember-me token should be rejected, got %d", w.Code)
+	}
+	if w := send(app, "GET", "/me", nil, rotated); w.Code != http.StatusOK {
+		t.Fatalf("rotated token: got %d", w.Code)
+	}
+}
+
+func TestLoginRedirects(t *testing.T) {
+	a := New(Config{LoginRedirect: "/home", LoginURL: "/login"})
+	app := testApp(a)
+
+	creds := url.Values{"username": {"ada"}, "password": {"secret"}}
+	if w := send(app, "POST", "/login", creds); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/home" {
+		t.Fatalf("login redirect: got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	creds.Set("next", "/me")
+	if w := send(app, "POST", "/login", creds); w.Header().Get("Location") != "/me" {
+		t.Fatalf("next redirect: got %q", w.Header().Get("Location"))
+	}
+	for _, next := range []string{"//evil.example.com", "/\t/evil.example.com", "/\\evil.example.com", "/a\\..\\\\evil.example.com", "https://evil.example.com"} {
+		creds.Set("next", next)
+		if w := send(app, "POST", "/login", creds); w.Header().Get("Location") != "/home" {
+			t.Fatalf("external next %q should be ignored, got %q", next, w.Header().Get("Location"))
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/me?tab=1", nil)
+	r.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login?next=%2Fme%3Ftab%3D1" {
+		t.Fatalf("require redirect: got %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps sessions and remember-me tokens, keyed by opaque strings.
+// Implementations backed by Redis or SQL let several instances of an app
+// share logins.
+type Store interface {
+	// Load returns the Principal saved under key and when it expires, or
+	// ErrNotFound.
+	Load(key string) (Principal, time.Time, error)
+
+	// Save stores p under key until expires.
+	Save(key string, p Principal, expires time.Time) error
+
+	// Delete removes key. Deleting an unknown key is not an error.
+	Delete(key string) error
+}
+
+// MemoryStore is a Store keeping entries in memory. Expired entries are
+// dropped as new ones are saved.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	saves   int
+}
+
+type memoryEntry struct {
+	principal Principal
+	expires   time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(key string) (Principal, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		return Principal{}, time.Time{}, ErrNotFound
+	}
+	return e.principal, e.expires, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(key string, p Principal, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{principal: p, expires: expires}
+	if s.saves++; s.saves%1024 == 0 {
+		now := time.Now()
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -21,23 +23,74 @@ const (
 
 // Binder handles different content type bindings.
 type Binder struct {
-	request *http.Request
+	request  *http.Request
+	strict   bool // Reject unknown fields and trailing data in JSON
+	validate bool // Validate JSON against the schema of the destination, see AppConfig.ValidateJSON
+	ctx      *Ctx // Reports deprecated fields sent by the client, see Ctx.deprecatedField
 }
 
 // JSON binds request body as JSON.
 // Go's json.Decoder automatically protects against deeply nested JSON (max depth ~10000).
+// In StrictJSON mode, unknown fields and data after the JSON value are rejected.
+// In ValidateJSON mode, the body is first validated against the schema of dst
+// and mismatches are answered with 422 Unprocessable Entity.
 func (b *Binder) JSON(dst interface{}) error {
 	if b.request.Body == nil {
 		return NewHTTPError(http.StatusBadRequest, "request body is empty")
 	}
 	defer b.request.Body.Close()
 
-	dec := json.NewDecoder(b.request.Body)
+	// The body is buffered only when validated or when dst has deprecated
+	// fields, to find out which top-level keys were sent.
+	var body io.Reader = b.request.Body
+	var data []byte
+	deprecated := b.deprecatedFields(dst, "json")
+	if len(deprecated) > 0 || b.validate {
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return NewHTTPError(http.StatusBadRequest, "failed to read body: "+err.Error())
+		}
+		body = bytes.NewReader(data)
+	}
+	if b.validate {
+		if t := reflect.TypeOf(dst); t != nil && t.Kind() == reflect.Ptr {
+			var schemaErr *SchemaError
+			if err := bodySchema(t.Elem()).Validate(data); errors.As(err, &schemaErr) {
+				return NewHTTPError(http.StatusUnprocessableEntity, "invalid JSON: "+err.Error())
+			}
+			// Syntax errors are reported by the decoder below
+		}
+	}
+
+	dec := json.NewDecoder(body)
+	if b.strict {
+		dec.DisallowUnknownFields()
+	}
 
 	if err := dec.Decode(dst); err != nil {
 		return NewHTTPError(http.StatusBadRequest, "invalid JSON: "+err.Error())
 	}
 
+	if b.strict {
+		if _, err := dec.Token(); err != io.EOF {
+			return NewHTTPError(http.StatusBadRequest, "invalid JSON: unexpected data after top-level value")
+		}
+	}
+
+	if len(deprecated) > 0 {
+		var raw map[string]json.RawMessage
+		if json.Unmarshal(data, &raw) == nil {
+			b.reportDeprecated(deprecated, func(name string) bool {
+				for key := range raw {
+					if strings.EqualFold(key, name) {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	}
+
 	return nil
 }
 
@@ -102,8 +155,17 @@ func (b *Binder) readBodySafe() ([]byte, error) {
 // Supports string, int, int64, float64, bool types.
 // Example: /users?name=John&age=25 -> struct{Name string; Age int}
 func (b *Binder) Query(dst interface{}) error {
-	values := b.request.URL.Query()
-	return bindValues(values, dst)
+	var values url.Values
+	if b.ctx != nil && b.ctx.Request == b.request {
+		values = b.ctx.queryValues()
+	} else {
+		values = b.request.URL.Query()
+	}
+	if err := bindValues(values, dst); err != nil {
+		return err
+	}
+	b.reportDeprecatedValues(values, dst)
+	return nil
 }
 
 // Form binds request form data (application/x-www-form-urlencoded) to dst struct.
@@ -112,7 +174,11 @@ func (b *Binder) Form(dst interface{}) error {
 	if err := b.request.ParseForm(); err != nil {
 		return NewHTTPError(http.StatusBadRequest, "invalid form data: "+err.Error())
 	}
-	return bindValues(b.request.PostForm, dst)
+	if err := bindValues(b.request.PostForm, dst); err != nil {
+		return err
+	}
+	b.reportDeprecatedValues(b.request.PostForm, dst)
+	return nil
 }
 
 // MultipartForm binds multipart form data (for file uploads) to dst struct.
@@ -120,7 +186,7 @@ func (b *Binder) Form(dst interface{}) error {
 // Example: struct { Name string; Avatar *multipart.FileHeader }
 func (b *Binder) MultipartForm(dst interface{}, maxMemory int64) error {
 	if maxMemory == 0 {
-		maxMemory = 32 << 20 // 32MB default
+		maxMemory = b.multipartMemory()
 	}
 
 	if err := b.request.ParseMultipartForm(maxMemory); err != nil {
@@ -131,6 +197,7 @@ func (b *Binder) MultipartForm(dst interface{}, maxMemory int64) error {
 	if err := bindValues(b.request.MultipartForm.Value, dst); err != nil {
 		return err
 	}
+	b.reportDeprecatedValues(b.request.MultipartForm.Value, dst)
 
 	// Bind file uploads
 	return bindFiles(b.request.MultipartForm.File, dst)
@@ -139,6 +206,10 @@ func (b *Binder) MultipartForm(dst interface{}, maxMemory int64) error {
 // File retrieves a single uploaded file by field name.
 // Returns the file header and a reader.
 func (b *Binder) File(name string) (multipart.File, *multipart.FileHeader, error) {
+	if b.request.MultipartForm == nil {
+		// FormFile would parse with its own 32MB threshold.
+		b.request.ParseMultipartForm(b.multipartMemory())
+	}
 	file, header, err := b.request.FormFile(name)
 	if err != nil {
 		return nil, nil, NewHTTPError(http.StatusBadRequest, "failed to get file: "+err.Error())
@@ -146,6 +217,14 @@ func (b *Binder) File(name string) (multipart.File, *multipart.FileHeader, error
 	return file, header, nil
 }
 
+// multipartMemory returns the AppConfig.MultipartMemory of the App.
+func (b *Binder) multipartMemory() int64 {
+	if b.ctx != nil && b.ctx.app != nil {
+		return b.ctx.app.multipartMem
+	}
+	return 32 * MB
+}
+
 // Auto automatically detects the content type and binds accordingly.
 // Provides excellent DX by eliminating manual content-type checking.
 // Example: c.Bind().Auto(&data) - works with JSON, Form, Multipart, or XML
@@ -158,7 +237,7 @@ func (b *Binder) Auto(dst interface{}) error {
 	case strings.HasPrefix(ct, "application/x-www-form-urlencoded"):
 		return b.Form(dst)
 	case strings.HasPrefix(ct, "multipart/form-data"):
-		return b.MultipartForm(dst, 32<<20) // 32MB default
+		return b.MultipartForm(dst, 0) // AppConfig.MultipartMemory
 	case strings.HasPrefix(ct, "application/xml"), strings.HasPrefix(ct, "text/xml"):
 		return b.XML(dst)
 	default:
@@ -166,6 +245,56 @@ func (b *Binder) Auto(dst interface{}) error {
 	}
 }
 
+// deprecatedField is a struct field tagged `deprecated:"message"`, by the
+// name it is sent under.
+type deprecatedField struct {
+	name    string
+	message string
+}
+
+// deprecatedFields lists the deprecated fields of the struct dst points to,
+// named after the first of keys found in their tags. Nothing is returned
+// when deprecations aren't reported.
+func (b *Binder) deprecatedFields(dst interface{}, keys ...string) []deprecatedField {
+	if b.ctx == nil {
+		return nil
+	}
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []deprecatedField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if msg, ok := f.Tag.Lookup("deprecated"); ok {
+			fields = append(fields, deprecatedField{name: tagName(f, keys...), message: msg})
+		}
+	}
+	return fields
+}
+
+// reportDeprecated reports the deprecated fields the client sent.
+func (b *Binder) reportDeprecated(fields []deprecatedField, sent func(name string) bool) {
+	for _, f := range fields {
+		if sent(f.name) {
+			b.ctx.deprecatedField(f.name, f.message)
+		}
+	}
+}
+
+// reportDeprecatedValues reports the deprecated fields of dst present in
+// values, using the same tag lookup as bindValues.
+func (b *Binder) reportDeprecatedValues(values url.Values, dst interface{}) {
+	b.reportDeprecated(b.deprecatedFields(dst, "form", "query", "json"), func(name string) bool {
+		_, ok := values[name]
+		return ok
+	})
+}
+
 // tagName extracts the field name from struct tags, handling options like "name,omitempty"
 func tagName(field reflect.StructField, keys ...string) string {
 	for _, key := range keys {
@@ -220,70 +349,74 @@ func bindValues(values url.Values, dst interface{}) (err error) {
 			field = field.Elem()
 		}
 
-		// Handle array fields
-		if field.Kind() == reflect.Array {
-			vals := values[tag]
-			if len(vals) == 0 {
-				continue
-			}
-			n := field.Len()
-			if len(vals) < n {
-				n = len(vals)
-			}
-			for i := 0; i < n; i++ {
-				if len(vals[i]) > maxFieldLength {
-					return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
-				}
-				if err := setField(field.Index(i), vals[i]); err != nil {
-					return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
-				}
-			}
-			continue
+		if err := bindField(field, fieldType, values[tag]); err != nil {
+			return err
 		}
+	}
 
-		// Handle slices for multiple values (?tag=a&tag=b&score=1&score=2)
-		if field.Kind() == reflect.Slice {
-			vals := values[tag]
-			if len(vals) == 0 {
-				continue
-			}
-
-			elem := field.Type().Elem()
-			out := reflect.MakeSlice(field.Type(), 0, len(vals))
+	return nil
+}
 
-			for _, sv := range vals {
-				// Check value length for security
-				if len(sv) > maxFieldLength {
-					return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
-				}
+// bindField sets field, described by fieldType, from vals: every value for
+// slices and arrays, the first one otherwise.
+func bindField(field reflect.Value, fieldType reflect.StructField, vals []string) error {
+	if len(vals) == 0 {
+		return nil
+	}
 
-				ev := reflect.New(elem).Elem()
-				if err := setField(ev, sv); err != nil {
-					return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
-				}
-				out = reflect.Append(out, ev)
+	// Handle array fields
+	if field.Kind() == reflect.Array {
+		n := field.Len()
+		if len(vals) < n {
+			n = len(vals)
+		}
+		for i := 0; i < n; i++ {
+			if len(vals[i]) > maxFieldLength {
+				return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
+			}
+			if err := setField(field.Index(i), vals[i]); err != nil {
+				return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
 			}
-			field.Set(out)
-			continue
 		}
+		return nil
+	}
 
-		// Single value
-		valueStr := values.Get(tag)
-		if valueStr == "" {
-			continue
-		}
+	// Handle slices for multiple values (?tag=a&tag=b&score=1&score=2)
+	if field.Kind() == reflect.Slice {
+		elem := field.Type().Elem()
+		out := reflect.MakeSlice(field.Type(), 0, len(vals))
 
-		// Limit string length to prevent memory exhaustion
-		if len(valueStr) > maxFieldLength {
-			return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
-		}
+		for _, sv := range vals {
+			// Check value length for security
+			if len(sv) > maxFieldLength {
+				return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
+			}
 
-		// Set field based on type
-		if err := setField(field, valueStr); err != nil {
-			return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
+			ev := reflect.New(elem).Elem()
+			if err := setField(ev, sv); err != nil {
+				return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
+			}
+			out = reflect.Append(out, ev)
 		}
+		field.Set(out)
+		return nil
 	}
 
+	// Single value
+	valueStr := vals[0]
+	if valueStr == "" {
+		return nil
+	}
+
+	// Limit string length to prevent memory exhaustion
+	if len(valueStr) > maxFieldLength {
+		return NewHTTPError(http.StatusBadRequest, "field value too long: "+fieldType.Name)
+	}
+
+	// Set field based on type
+	if err := setField(field, valueStr); err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid value for field "+fieldType.Name+": "+err.Error())
+	}
 	return nil
 }
 
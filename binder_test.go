@@ -2,10 +2,12 @@ package owl
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 )
@@ -723,3 +725,134 @@ func TestBinder_MultipartForm_LargeFile(t *testing.T) {
 func contains(s, substr string) bool {
 	return bytes.Contains([]byte(s), []byte(substr))
 }
+
+func TestBindDeprecatedFields(t *testing.T) {
+	type payload struct {
+		Name    string `json:"name" form:"name"`
+		OldName string `json:"old_name" form:"old_name" deprecated:"use name"`
+		Legacy  bool   `json:"legacy" form:"legacy" deprecated:""`
+	}
+
+	var reported []string
+	app := New(AppConfig{OnDeprecatedField: func(c *Ctx, field, message string) {
+		reported = append(reported, field)
+	}})
+	app.POST("/json", func(c *Ctx) error {
+		var p payload
+		if err := c.Bind().JSON(&p); err != nil {
+			return err
+		}
+		return c.Text(p.OldName)
+	})
+	app.GET("/query", func(c *Ctx) error {
+		var p payload
+		return c.Bind().Query(&p)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/json", strings.NewReader(`{"old_name":"owl","legacy":true}`)))
+	if w.Body.String() != "owl" {
+		t.Fatalf("deprecated field not bound: %q", w.Body.String())
+	}
+	want := []string{`299 - "field \"old_name\" is deprecated: use name"`, `299 - "field \"legacy\" is deprecated"`}
+	if got := w.Header().Values("Warning"); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected Warning headers %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/json", strings.NewReader(`{"name":"owl"}`)))
+	if len(w.Header().Values("Warning")) != 0 {
+		t.Fatalf("unexpected Warning for current fields: %q", w.Header().Values("Warning"))
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/query?old_name=owl", nil))
+	if len(w.Header().Values("Warning")) != 1 {
+		t.Fatalf("expected Warning for deprecated query field, got %q", w.Header().Values("Warning"))
+	}
+
+	if strings.Join(reported, ",") != "old_name,legacy,old_name" {
+		t.Fatalf("unexpected hook calls %q", reported)
+	}
+}
+
+func BenchmarkCtxBind(b *testing.B) {
+	req := httptest.NewRequest("GET", "/?name=owl&age=3", nil)
+	c := newCtx(httptest.NewRecorder(), req)
+	var dst struct {
+		Name string `query:"name"`
+		Age  int    `query:"age"`
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Bind().Query(&dst)
+	}
+}
+
+func TestCtxBindReused(t *testing.T) {
+	c := newCtx(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if allocs := testing.AllocsPerRun(100, func() { c.Bind() }); allocs != 0 {
+		t.Fatalf("expected Bind not to allocate, got %v allocations", allocs)
+	}
+}
+
+func TestCtxQueryCached(t *testing.T) {
+	c := newCtx(httptest.NewRecorder(), httptest.NewRequest("GET", "/?a=1&b=2", nil))
+	if c.Query("a") != "1" || c.Query("b") != "2" {
+		t.Fatal("unexpected query values")
+	}
+	if allocs := testing.AllocsPerRun(100, func() { c.Query("a") }); allocs != 0 {
+		t.Fatalf("expected the parsed query to be reused, got %v allocations", allocs)
+	}
+
+	// A middleware rewriting the query is noticed.
+	c.Request.URL.RawQuery = "a=3"
+	var dst struct {
+		A int `query:"a"`
+	}
+	if err := c.Bind().Query(&dst); err != nil || dst.A != 3 || c.Query("a") != "3" {
+		t.Fatalf("expected the rewritten query, got %+v %v", dst, err)
+	}
+}
+
+func TestMultipartTempFilesRemoved(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	part, _ := mw.CreateFormFile("upload", "big.bin")
+	part.Write(bytes.Repeat([]byte("x"), 4096))
+	mw.Close()
+
+	app := New(AppConfig{MultipartMemory: 1024})
+	var spooled string
+	app.Use(Middleware(func(next Handler) Handler {
+		return func(c *Ctx) error {
+			// Copies of the request aren't cleaned up by net/http.
+			c.Request = c.Request.WithContext(context.Background())
+			return next(c)
+		}
+	}))
+	app.POST("/", func(c *Ctx) error {
+		_, header, err := c.Bind().File("upload")
+		if err != nil {
+			return err
+		}
+		entries, _ := os.ReadDir(os.Getenv("TMPDIR"))
+		if len(entries) != 1 || header.Size != 4096 {
+			t.Errorf("expected the upload to be spooled to disk, got %v", entries)
+		} else {
+			spooled = entries[0].Name()
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if spooled == "" {
+		t.Fatal("expected a temporary file")
+	}
+	if entries, _ := os.ReadDir(os.Getenv("TMPDIR")); len(entries) != 0 {
+		t.Fatalf("expected the temporary files to be removed, got %v", entries)
+	}
+}
@@ -0,0 +1,150 @@
+package owl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFrozen is the error of the panics raised by registering routes,
+// middlewares, hosts or mounts on an App once it is built, see Build.
+var ErrFrozen = errors.New("owl: the App is built and frozen")
+
+// Build freezes the App and prepares it for serving: it validates routes,
+// middlewares and configuration, then assembles the handler chain of every
+// route. Apps attached with Mount are built too. Start calls Build; callers
+// managing the server themselves, e.g. through Listen, should call it
+// first:
+//
+//	if err := app.Build(); err != nil {
+//		log.Fatal(err)
+//	}
+//	app.DumpMiddlewares(os.Stdout)
+//
+// Until Build, middlewares can still be added with App.Use, Group.Use and
+// RouteBuilder.With, and apply to routes registered earlier. Once built,
+// the handler of every route is final and requests are served without
+// locking, and registering routes, middlewares, hosts or mounts panics
+// with an error wrapping ErrFrozen that names the offending call.
+//
+// Build reports every problem it finds at once and leaves the App open
+// when it fails. The conflicts found by CheckRoutes are logged as
+// warnings, or reported too with AppConfig.StrictRoutes. Calling Build
+// again after it succeeded is a no-op.
+//
+// An App that is served without Build assembles its handler chains on the
+// first request instead, without validation.
+func (a *App) Build() error {
+	for _, sub := range a.mounted {
+		if err := sub.Build(); err != nil {
+			return err
+		}
+	}
+
+	a.buildMu.Lock()
+	defer a.buildMu.Unlock()
+	if a.built.Load() {
+		return nil
+	}
+	if err := a.validate(); err != nil {
+		return err
+	}
+	a.compileLocked()
+	a.built.Store(true)
+	return nil
+}
+
+// validate reports every problem that would break serving.
+func (a *App) validate() error {
+	var errs []error
+	if a.errorHandler == nil {
+		errs = append(errs, errors.New("owl: the error handler is nil"))
+	}
+	for key, set := range a.routes {
+		for _, route := range set.routes {
+			if route.h == nil {
+				errs = append(errs, fmt.Errorf("owl: nil handler for %s %s (%s)", displayMethod(key.method), key.path, route.source))
+			}
+			for _, mw := range route.scope.all() {
+				if mw == nil {
+					errs = append(errs, fmt.Errorf("owl: nil middleware in the chain of %s %s (%s)", displayMethod(key.method), key.path, route.source))
+					break
+				}
+			}
+		}
+	}
+	var conflictErr *RouteConflictError
+	if err := a.CheckRoutes(); errors.As(err, &conflictErr) {
+		if a.strictRoutes {
+			errs = append(errs, err)
+		} else {
+			for _, c := range conflictErr.Conflicts {
+				a.logf(LevelWarn, "route conflict", "conflict", c.String())
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compile assembles the handler chains of routes registered or affected by
+// middlewares added since the last compilation.
+func (a *App) compile() {
+	a.buildMu.Lock()
+	defer a.buildMu.Unlock()
+	if a.stale.Load() {
+		a.compileLocked()
+	}
+}
+
+func (a *App) compileLocked() {
+	for _, set := range a.routes {
+		for _, route := range set.routes {
+			h, names := chainMiddlewares(route.h, route.scope.all()...)
+			if route.wildcard != "" {
+				h = aliasWildcard(route.wildcard, h)
+			}
+			route.handler = a.wrapHandler(h, route.opts)
+			route.middlewares = names
+		}
+		set.handler = nil
+		if len(set.routes) == 1 && len(set.routes[0].when) == 0 {
+			set.handler = set.routes[0].handler
+		}
+	}
+	for _, hr := range a.hosts {
+		hr.once.Do(func() {
+			hr.handler = chain(a.mux.Middlewares(), hr.mux)
+		})
+	}
+	a.stale.Store(false)
+}
+
+// mutate records a change to routes or middlewares, which is refused once
+// the App is built.
+func (a *App) mutate(what string) {
+	if a.built.Load() {
+		panic(fmt.Errorf("%w: %s at %s must happen before Build", ErrFrozen, what, callSite()))
+	}
+	a.stale.Store(true)
+}
+
+// scope holds the Owl-style middlewares of the App, a Group, a RouteBuilder
+// or a single route. Routes keep a pointer to their scope, and the chain is
+// read from the root down when the App is built, so middlewares added to a
+// parent later still apply.
+type scope struct {
+	parent      *scope
+	middlewares []Middleware
+}
+
+// child returns a new scope below s holding middlewares.
+func (s *scope) child(middlewares ...Middleware) *scope {
+	return &scope{parent: s, middlewares: snapshot(nil, middlewares...)}
+}
+
+// all returns the middlewares of s and its parents, outermost first.
+func (s *scope) all() []Middleware {
+	if s == nil {
+		return nil
+	}
+	return snapshot(s.parent.all(), s.middlewares...)
+}
@@ -0,0 +1,138 @@
+package owl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFromEnv returns an AppConfig read from the environment variables
+// named after prefix, for twelve-factor deployments. With prefix "OWL":
+//
+//	OWL_NAME, OWL_VERSION                        strings
+//	OWL_BODY_LIMIT                               bytes, e.g. 1048576 or 10MB
+//	OWL_READ_TIMEOUT, OWL_READ_HEADER_TIMEOUT,
+//	OWL_WRITE_TIMEOUT, OWL_IDLE_TIMEOUT,
+//	OWL_SECRET_REFRESH                           durations, e.g. 30s
+//	OWL_MAX_HEADER_BYTES, OWL_MAX_CONNS,
+//	OWL_MAX_CONNS_PER_IP                         integers
+//	OWL_STRICT_JSON, OWL_VALIDATE_JSON, OWL_STRICT_ROUTES,
+//	OWL_AUTO_HEAD, OWL_AUTO_OPTIONS,
+//	OWL_CASE_INSENSITIVE, OWL_DISABLE_BANNER,
+//	OWL_NO_COLOR, OWL_DEV_MODE                   booleans, e.g. true or 1
+//
+// Unset variables keep the defaults of New. Invalid values are all
+// reported in the returned error, the other fields being set:
+//
+//	config, err := owl.ConfigFromEnv("OWL")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	config.Logger = logger
+//	app := owl.New(config)
+func ConfigFromEnv(prefix string) (AppConfig, error) {
+	config := AppConfig{BodyLimit: 10 * MB}
+	e := envReader{prefix: strings.TrimSuffix(prefix, "_") + "_"}
+
+	e.string("NAME", &config.Name)
+	e.string("VERSION", &config.Version)
+	e.size("BODY_LIMIT", &config.BodyLimit)
+	e.duration("READ_TIMEOUT", &config.ReadTimeout)
+	e.duration("READ_HEADER_TIMEOUT", &config.ReadHeaderTimeout)
+	e.duration("WRITE_TIMEOUT", &config.WriteTimeout)
+	e.duration("IDLE_TIMEOUT", &config.IdleTimeout)
+	e.duration("SECRET_REFRESH", &config.SecretRefresh)
+	e.int("MAX_HEADER_BYTES", &config.MaxHeaderBytes)
+	e.int("MAX_CONNS", &config.MaxConns)
+	e.int("MAX_CONNS_PER_IP", &config.MaxConnsPerIP)
+	e.bool("STRICT_JSON", &config.StrictJSON)
+	e.bool("VALIDATE_JSON", &config.ValidateJSON)
+	e.bool("STRICT_ROUTES", &config.StrictRoutes)
+	e.bool("AUTO_HEAD", &config.AutoHead)
+	e.bool("AUTO_OPTIONS", &config.AutoOptions)
+	e.bool("CASE_INSENSITIVE", &config.CaseInsensitive)
+	e.bool("DISABLE_BANNER", &config.DisableBanner)
+	e.bool("NO_COLOR", &config.NoColor)
+	e.bool("DEV_MODE", &config.DevMode)
+
+	return config, errors.Join(e.errs...)
+}
+
+// envReader parses environment variables, collecting the errors.
+type envReader struct {
+	prefix string
+	errs   []error
+}
+
+// lookup returns the value of the variable prefix+name, if set.
+func (e *envReader) lookup(name string) (string, bool) {
+	v, ok := os.LookupEnv(e.prefix + name)
+	return strings.TrimSpace(v), ok && strings.TrimSpace(v) != ""
+}
+
+func (e *envReader) fail(name, kind, value string) {
+	e.errs = append(e.errs, fmt.Errorf("owl: %s%s: invalid %s '%s'", e.prefix, name, kind, value))
+}
+
+func (e *envReader) string(name string, dst *string) {
+	if v, ok := e.lookup(name); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) bool(name string, dst *bool) {
+	if v, ok := e.lookup(name); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.fail(name, "boolean", v)
+			return
+		}
+		*dst = b
+	}
+}
+
+func (e *envReader) int(name string, dst *int) {
+	if v, ok := e.lookup(name); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			e.fail(name, "integer", v)
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) duration(name string, dst *time.Duration) {
+	if v, ok := e.lookup(name); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.fail(name, "duration", v)
+			return
+		}
+		*dst = d
+	}
+}
+
+// size parses a byte count, with an optional KB, MB or GB unit.
+func (e *envReader) size(name string, dst *int64) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	unit, digits := int64(1), strings.ToUpper(v)
+	for suffix, n := range map[string]int64{"KB": KB, "MB": MB, "GB": GB} {
+		if d, ok := strings.CutSuffix(digits, suffix); ok {
+			unit, digits = n, strings.TrimSpace(d)
+			break
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 0 {
+		e.fail(name, "size", v)
+		return
+	}
+	*dst = n * unit
+}
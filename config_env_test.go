@@ -0,0 +1,44 @@
+package owl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv("OWL")
+	if err != nil || config.BodyLimit != 10*MB || config.Name != "" {
+		t.Fatalf("expected the defaults without variables, got %+v %v", config, err)
+	}
+
+	t.Setenv("APP_NAME", "billing")
+	t.Setenv("APP_BODY_LIMIT", "2MB")
+	t.Setenv("APP_READ_TIMEOUT", "5s")
+	t.Setenv("APP_MAX_CONNS", "1000")
+	t.Setenv("APP_AUTO_HEAD", "true")
+	config, err = ConfigFromEnv("APP_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "billing" || config.BodyLimit != 2*MB || config.ReadTimeout != 5*time.Second ||
+		config.MaxConns != 1000 || !config.AutoHead {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	t.Setenv("APP_WRITE_TIMEOUT", "soon")
+	t.Setenv("APP_DEV_MODE", "yes please")
+	t.Setenv("APP_BODY_LIMIT", "-1")
+	config, err = ConfigFromEnv("APP")
+	if err == nil {
+		t.Fatal("expected invalid values to be reported")
+	}
+	for _, want := range []string{"APP_WRITE_TIMEOUT: invalid duration 'soon'", "APP_DEV_MODE: invalid boolean", "APP_BODY_LIMIT: invalid size '-1'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if config.Name != "billing" || config.BodyLimit != 10*MB {
+		t.Fatalf("expected valid values to be kept, got %+v", config)
+	}
+}
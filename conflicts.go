@@ -0,0 +1,226 @@
+package owl
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RouteConflict describes two registrations for the same method whose
+// patterns match the same requests, so that one silently wins.
+type RouteConflict struct {
+	Method       string
+	Pattern      string
+	Source       string // Call site of the registration, "file:line"
+	OtherPattern string
+	OtherSource  string
+	Reason       string
+}
+
+func (c RouteConflict) String() string {
+	return fmt.Sprintf("%s %s (%s) conflicts with %s %s (%s): %s",
+		c.Method, c.Pattern, c.Source, c.Method, c.OtherPattern, c.OtherSource, c.Reason)
+}
+
+// RouteConflictError is returned by CheckRoutes.
+type RouteConflictError struct {
+	Conflicts []RouteConflict
+}
+
+func (e *RouteConflictError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		lines[i] = "  " + c.String()
+	}
+	return "owl: conflicting routes:\n" + strings.Join(lines, "\n")
+}
+
+// CheckRoutes reports routes registered through the App, its groups and
+// hosts that match the same requests:
+//
+//   - the same method and pattern registered twice without When,
+//   - patterns differing only in param names, such as /users/{id} and
+//     /users/{name}, where the last registration wins,
+//   - params with different regexps at the same position, such as
+//     /{id:[0-9]+} and /{n:[0-9a-f]+}, which both match /42,
+//   - static segments or params with a regexp shadowing a plain param,
+//     such as /users/new and /users/{id}, or /{id:[0-9]+} and /{slug},
+//     where the more specific pattern wins.
+//
+// Static segments are compared regardless of case with
+// AppConfig.CaseInsensitive. Build logs the conflicts as warnings naming
+// both call sites, or fails on them with AppConfig.StrictRoutes.
+func (a *App) CheckRoutes() error {
+	type entry struct {
+		key routeKey
+		set *routeSet
+	}
+	byMux := map[RouterBackend][]entry{}
+	for key, set := range a.routes {
+		byMux[key.mux] = append(byMux[key.mux], entry{key, set})
+	}
+
+	var conflicts []RouteConflict
+	for mux, entries := range byMux {
+		mx, _ := mux.(*Mux)
+		caseInsensitive := mx != nil && mx.caseInsensitive
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].key.path != entries[j].key.path {
+				return entries[i].key.path < entries[j].key.path
+			}
+			return entries[i].key.method < entries[j].key.method
+		})
+		for i, e := range entries {
+			method := displayMethod(e.key.method)
+			var first *conditionalRoute
+			for _, route := range e.set.routes {
+				if len(route.when) > 0 {
+					continue
+				}
+				if first != nil {
+					conflicts = append(conflicts, RouteConflict{
+						Method: method, Pattern: e.key.path, Source: route.source,
+						OtherPattern: e.key.path, OtherSource: first.source,
+						Reason: "registered twice, the last registration wins",
+					})
+					continue
+				}
+				first = route
+			}
+
+			for _, other := range entries[i+1:] {
+				if other.key.method != e.key.method {
+					continue
+				}
+				reason := overlap(e.key.path, other.key.path, caseInsensitive)
+				if reason == "" {
+					continue
+				}
+				conflicts = append(conflicts, RouteConflict{
+					Method: method, Pattern: e.key.path, Source: e.set.routes[0].source,
+					OtherPattern: other.key.path, OtherSource: other.set.routes[0].source,
+					Reason: reason,
+				})
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].Pattern < conflicts[j].Pattern })
+	return &RouteConflictError{Conflicts: conflicts}
+}
+
+// overlap compares two patterns segment by segment and explains how they
+// overlap, or returns "" when no request matches both. Patterns with
+// catch-alls or params embedded in a segment are not analyzed.
+func overlap(p1, p2 string, caseInsensitive bool) string {
+	s1, s2 := strings.Split(p1, "/"), strings.Split(p2, "/")
+	if len(s1) != len(s2) {
+		return ""
+	}
+
+	ambiguous, shadowed := false, false
+	for i := range s1 {
+		a, b := s1[i], s2[i]
+		if strings.Contains(a, "*") || strings.Contains(b, "*") {
+			return ""
+		}
+		aParam, aExpr, aOK := segmentParam(a)
+		bParam, bExpr, bOK := segmentParam(b)
+		if !aOK || !bOK {
+			return ""
+		}
+		switch {
+		case !aParam && !bParam:
+			if a != b && !(caseInsensitive && strings.EqualFold(a, b)) {
+				return ""
+			}
+		case aParam && bParam:
+			switch {
+			case aExpr == bExpr:
+			case aExpr == "" || bExpr == "":
+				shadowed = true // The param with a regexp is tried first
+			default:
+				ambiguous = true
+			}
+		default:
+			static, expr := a, bExpr
+			if aParam {
+				static, expr = b, aExpr
+			}
+			if expr != "" && !matchesSegment(expr, static, caseInsensitive) {
+				return ""
+			}
+			shadowed = true
+		}
+	}
+
+	switch {
+	case ambiguous:
+		return "params with different patterns match the same paths, the first matching param wins"
+	case shadowed:
+		return "a static segment or a param with a regexp shadows a param"
+	default:
+		return "patterns only differ in param names, the last registration wins"
+	}
+}
+
+// segmentParam reports whether segment is a whole-segment param and returns
+// its regexp. ok is false for segments mixing params and static text.
+func segmentParam(segment string) (param bool, expr string, ok bool) {
+	if !strings.Contains(segment, "{") {
+		return false, "", true
+	}
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") || strings.Count(segment, "{") != 1 {
+		return false, "", false
+	}
+	if _, expr, found := strings.Cut(segment[1:len(segment)-1], ":"); found {
+		return true, expr, true
+	}
+	return true, "", true
+}
+
+// matchesSegment reports whether the regexp expr of a param matches the
+// static segment, or can't tell for invalid regexps.
+func matchesSegment(expr, segment string, caseInsensitive bool) bool {
+	if caseInsensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	return err != nil || re.MatchString(segment)
+}
+
+func displayMethod(method string) string {
+	if method == "" {
+		return "*"
+	}
+	return method
+}
+
+// ownDir is the directory of this package, see callSite.
+var ownDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callSite returns the "file:line" of the first caller outside of this
+// package, which is where a route was registered.
+func callSite() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != ownDir || strings.HasSuffix(frame.File, "_test.go") {
+			dir, file := filepath.Split(frame.File)
+			return filepath.Join(filepath.Base(dir), file) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package owl
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCheckRoutes(t *testing.T) {
+	ok := func(c *Ctx) error { return nil }
+
+	app := New()
+	app.GET("/users/{id}", ok)
+	app.POST("/users/{id}", ok)
+	app.GET("/Users", ok)
+	app.GET("/users", ok)
+	app.GET("/items/new", ok)
+	app.GET("/items/{id:[0-9]+}", ok)
+	app.GET("/export", ok).When(HeaderIs("Accept", "text/csv"))
+	app.GET("/export", ok)
+	app.GET("/files/*", ok)
+	app.GET("/files/{name}", ok)
+	if err := app.CheckRoutes(); err != nil {
+		t.Fatalf("unexpected conflicts: %v", err)
+	}
+
+	app.GET("/users/{name}", ok)
+	app.GET("/users/new", ok)
+	api := app.Group("/api")
+	api.GET("/items", ok)
+	api.GET("/items", ok)
+	api.GET("/{id:[0-9]+}", ok)
+	api.GET("/{hex:[0-9a-f]+}", ok)
+	api.GET("/{slug}", ok)
+
+	err := app.CheckRoutes()
+	var conflictErr *RouteConflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 8 {
+		t.Fatalf("expected 8 conflicts, got %v", err)
+	}
+	for _, c := range conflictErr.Conflicts {
+		if !strings.Contains(c.Source, "/conflicts_test.go:") || !strings.Contains(c.OtherSource, "/conflicts_test.go:") {
+			t.Errorf("expected call sites in this file, got %q and %q", c.Source, c.OtherSource)
+		}
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"GET /api/items", "registered twice",
+		"/users/{id}", "only differ in param names",
+		"/users/new", "/api/{slug}", "shadows a param",
+		"/api/{hex:[0-9a-f]+}", "params with different patterns",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should mention %q:\n%s", want, msg)
+		}
+	}
+
+	folded := New(AppConfig{CaseInsensitive: true})
+	folded.GET("/Users", ok)
+	folded.GET("/users", ok)
+	if err := folded.CheckRoutes(); err == nil {
+		t.Fatal("expected paths differing in case to conflict with CaseInsensitive")
+	}
+}
+
+func TestBuildRouteConflicts(t *testing.T) {
+	ok := func(c *Ctx) error { return nil }
+	var logs strings.Builder
+	app := New(AppConfig{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	app.GET("/users/new", ok)
+	app.GET("/users/{id}", ok)
+	if err := app.Build(); err != nil {
+		t.Fatalf("expected conflicts to be warnings, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "shadows a param") {
+		t.Fatalf("expected a warning, got %q", logs.String())
+	}
+
+	strict := New(AppConfig{StrictRoutes: true})
+	strict.GET("/users/new", ok)
+	strict.GET("/users/{id}", ok)
+	if err := strict.Build(); err == nil || !strings.Contains(err.Error(), "shadows a param") {
+		t.Fatalf("expected Build to fail with StrictRoutes, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package owl
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// connLimiter enforces AppConfig.MaxConns and MaxConnsPerIP from the
+// ConnState callback of the server, so that it applies whichever way the
+// server is started. Connections over a limit are closed as soon as they
+// are accepted, before a request is read.
+type connLimiter struct {
+	max, perIP int
+
+	mu      sync.Mutex
+	total   int
+	byIP    map[string]int
+	tracked map[net.Conn]string // Counted connections, by their IP
+}
+
+func newConnLimiter(max, perIP int) *connLimiter {
+	return &connLimiter{max: max, perIP: perIP, byIP: map[string]int{}, tracked: map[net.Conn]string{}}
+}
+
+// connState counts conn, or closes it when over a limit. Hijacked
+// connections, such as WebSockets, are no longer counted.
+func (l *connLimiter) connState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			ip = conn.RemoteAddr().String()
+		}
+		l.mu.Lock()
+		over := (l.max > 0 && l.total >= l.max) || (l.perIP > 0 && l.byIP[ip] >= l.perIP)
+		if !over {
+			l.total++
+			l.byIP[ip]++
+			l.tracked[conn] = ip
+		}
+		l.mu.Unlock()
+		if over {
+			conn.Close()
+		}
+	case http.StateClosed, http.StateHijacked:
+		l.mu.Lock()
+		if ip, ok := l.tracked[conn]; ok {
+			delete(l.tracked, conn)
+			l.total--
+			if l.byIP[ip]--; l.byIP[ip] == 0 {
+				delete(l.byIP, ip)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// connStateHook returns the ConnState callback of the server of a, which
+// tracks idle connections for CloseIdleConns.
+func (a *App) connStateHook() func(net.Conn, http.ConnState) {
+	limiter, hook := a.connLimiter, a.serverOpts.connState
+	return func(conn net.Conn, state http.ConnState) {
+		if limiter != nil {
+			limiter.connState(conn, state)
+		}
+		a.idle.connState(conn, state)
+		if hook != nil {
+			hook(conn, state)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package owl
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnLimits(t *testing.T) {
+	for name, config := range map[string]AppConfig{
+		"MaxConns":      {MaxConns: 1},
+		"MaxConnsPerIP": {MaxConnsPerIP: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var states atomic.Int32
+			config.ConnState = func(net.Conn, http.ConnState) { states.Add(1) }
+			app := New(config)
+			app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go app.Serve(ln)
+			defer app.Shutdown()
+
+			get := func(conn net.Conn) error {
+				conn.SetDeadline(time.Now().Add(time.Second))
+				if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: owl\r\n\r\n")); err != nil {
+					return err
+				}
+				resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+				if err != nil {
+					return err
+				}
+				return resp.Body.Close()
+			}
+
+			first, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := get(first); err != nil {
+				t.Fatal(err)
+			}
+			second, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := get(second); err == nil {
+				t.Fatal("expected the connection over the limit to be closed")
+			}
+			second.Close()
+
+			first.Close()
+			for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+				third, err := net.Dial("tcp", ln.Addr().String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = get(third)
+				third.Close()
+				if err == nil {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("expected a connection to be accepted once the first one closed")
+				}
+			}
+			if states.Load() == 0 {
+				t.Fatal("expected the ConnState callback to be called")
+			}
+		})
+	}
+}
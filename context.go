@@ -37,6 +37,22 @@ func NewRouteContext() *Context {
 var (
 	// RouteCtxKey is the context.Context key to store the request context.
 	RouteCtxKey = &contextKey{"RouteContext"}
+
+	// ClaimsCtxKey is the context.Context key to store the claims of an
+	// authenticated request, see Ctx.Claims.
+	ClaimsCtxKey = &contextKey{"Claims"}
+
+	// SessionCtxKey is the context.Context key to store the *Session of a
+	// request, see Ctx.Session.
+	SessionCtxKey = &contextKey{"Session"}
+
+	// ServerTimingCtxKey is the context.Context key to store the
+	// *ServerTimings of a request, see Ctx.Timing.
+	ServerTimingCtxKey = &contextKey{"ServerTiming"}
+
+	// LocaleCtxKey is the context.Context key to store the locale of a
+	// request, see I18n.Middleware.
+	LocaleCtxKey = &contextKey{"Locale"}
 )
 
 // Context is the default routing context set on the root node of a
@@ -76,6 +92,14 @@ type Context struct {
 
 	methodsAllowed   []methodTyp // allowed methods in case of a 405
 	methodNotAllowed bool
+
+	// Static attributes declared by the matched Owl route, see
+	// Group.Attr.
+	routeAttrs []Attr
+
+	// Log level of the matched Owl route, see App.SetLogLevel.
+	logLevel    LogLevel
+	hasLogLevel bool
 }
 
 // Reset a routing context to its initial state.
@@ -92,6 +116,8 @@ func (x *Context) Reset() {
 	x.routeParams.Values = x.routeParams.Values[:0]
 	x.methodNotAllowed = false
 	x.methodsAllowed = x.methodsAllowed[:0]
+	x.routeAttrs = nil
+	x.logLevel, x.hasLogLevel = 0, false
 	x.parentCtx = nil
 }
 
@@ -106,6 +132,14 @@ func (x *Context) URLParam(key string) string {
 	return ""
 }
 
+// RouteAttrs returns the static attributes declared with Group.Attr or
+// RouteBuilder.Attr by the matched route. Like RoutePattern, it is only
+// set once routing is done, so tracing and logging middlewares should read
+// it after calling the next handler.
+func (x *Context) RouteAttrs() []Attr {
+	return x.routeAttrs
+}
+
 // RoutePattern builds the routing pattern string for the particular
 // request, at the particular point during routing. This means, the value
 // will change throughout the execution of a request in a router. That is
@@ -124,7 +158,12 @@ func (x *Context) RoutePattern() string {
 	if x == nil {
 		return ""
 	}
-	routePattern := strings.Join(x.RoutePatterns, "")
+	return cleanRoutePattern(strings.Join(x.RoutePatterns, ""))
+}
+
+// cleanRoutePattern normalizes a pattern joined across sub-routers, as
+// recorded during routing or produced by Walk.
+func cleanRoutePattern(routePattern string) string {
 	routePattern = replaceWildcards(routePattern)
 	if routePattern != "/" {
 		routePattern = strings.TrimSuffix(routePattern, "//")
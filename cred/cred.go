@@ -0,0 +1,220 @@
+// Package cred hashes and verifies passwords with argon2id or bcrypt, so
+// that handlers don't need home-grown schemes:
+//
+//	hash, err := cred.Hash(password) // store hash
+//
+//	ok, err := cred.Verify(password, hash)
+//	if ok && cred.NeedsRehash(hash) {
+//		hash, _ = cred.Hash(password) // store the upgraded hash
+//	}
+//
+// Hashes are self-describing strings: argon2id hashes use the PHC format
+// "$argon2id$v=19$m=65536,t=3,p=2$salt$key" and bcrypt hashes the usual
+// "$2a$12$..." format, so parameters can be raised over time and old
+// hashes upgraded at the next login. It pairs with the auth package:
+//
+//	app.POST("/login", auth.LoginHandler(func(user, pass string) (auth.Principal, error) {
+//		u, err := db.FindUser(user)
+//		if err != nil {
+//			return auth.Principal{}, auth.ErrInvalidCredentials
+//		}
+//		if ok, err := cred.Verify(pass, u.PasswordHash); err != nil || !ok {
+//			return auth.Principal{}, auth.ErrInvalidCredentials
+//		}
+//		return auth.Principal{ID: u.ID}, nil
+//	}))
+package cred
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm.
+type Algorithm int
+
+// Supported algorithms.
+const (
+	Argon2id Algorithm = iota
+	Bcrypt
+)
+
+// ErrUnknownFormat is returned for hashes not produced by this package,
+// including argon2id hashes with settings outside of the limits below.
+var ErrUnknownFormat = errors.New("cred: unknown hash format")
+
+// Limits of the argon2id settings, so that a crafted hash can't make
+// Verify allocate or compute without bounds.
+const (
+	MaxMemory = 1024 * 1024 // KiB, 1 GiB
+	MaxTime   = 64
+)
+
+// Params configures hashing. Zero fields take the defaults of
+// DefaultParams.
+type Params struct {
+	Algorithm Algorithm
+
+	// Argon2id settings: memory in KiB, passes over the memory, threads,
+	// and salt and key lengths in bytes.
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+
+	// BcryptCost is the bcrypt work factor.
+	BcryptCost int
+}
+
+// DefaultParams follows the OWASP recommendations for argon2id: 64 MiB of
+// memory, 3 passes and 2 threads. Bcrypt uses a cost of 12.
+var DefaultParams = Params{
+	Algorithm:  Argon2id,
+	Memory:     64 * 1024,
+	Time:       3,
+	Threads:    2,
+	SaltLen:    16,
+	KeyLen:     32,
+	BcryptCost: 12,
+}
+
+func (p Params) withDefaults() Params {
+	if p.Memory == 0 {
+		p.Memory = DefaultParams.Memory
+	}
+	if p.Time == 0 {
+		p.Time = DefaultParams.Time
+	}
+	if p.Threads == 0 {
+		p.Threads = DefaultParams.Threads
+	}
+	if p.SaltLen == 0 {
+		p.SaltLen = DefaultParams.SaltLen
+	}
+	if p.KeyLen == 0 {
+		p.KeyLen = DefaultParams.KeyLen
+	}
+	if p.BcryptCost == 0 {
+		p.BcryptCost = DefaultParams.BcryptCost
+	}
+	return p
+}
+
+// Hash hashes password with DefaultParams.
+func Hash(password string) (string, error) {
+	return DefaultParams.Hash(password)
+}
+
+// Verify reports whether password matches hash, see Params.Verify.
+func Verify(password, hash string) (bool, error) {
+	return DefaultParams.Verify(password, hash)
+}
+
+// NeedsRehash reports whether hash was made with other settings than
+// DefaultParams, see Params.NeedsRehash.
+func NeedsRehash(hash string) bool {
+	return DefaultParams.NeedsRehash(hash)
+}
+
+// Hash hashes password with a random salt.
+func (p Params) Hash(password string) (string, error) {
+	p = p.withDefaults()
+	switch p.Algorithm {
+	case Argon2id:
+		if p.Memory > MaxMemory || p.Time > MaxTime {
+			return "", errors.New("cred: argon2id settings over MaxMemory or MaxTime")
+		}
+		salt := make([]byte, p.SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Time, p.Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	case Bcrypt:
+		b, err := bcrypt.GenerateFromPassword([]byte(password), p.BcryptCost)
+		return string(b), err
+	default:
+		return "", fmt.Errorf("cred: unknown algorithm %d", p.Algorithm)
+	}
+}
+
+// Verify reports whether password matches hash, whatever the algorithm and
+// settings hash was made with. Keys are compared in constant time. An error
+// is only returned for malformed hashes.
+func (p Params) Verify(password, hash string) (bool, error) {
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	h, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), h.salt, h.Time, h.Memory, h.Threads, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+// NeedsRehash reports whether hash was made with another algorithm or
+// weaker settings than p, or can't be parsed. Callers should then hash the
+// password again after a successful Verify and store the result.
+func (p Params) NeedsRehash(hash string) bool {
+	p = p.withDefaults()
+	if isBcrypt(hash) {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || p.Algorithm != Bcrypt || cost < p.BcryptCost
+	}
+	h, err := parseArgon2id(hash)
+	if err != nil || p.Algorithm != Argon2id {
+		return true
+	}
+	return h.Memory < p.Memory || h.Time < p.Time || h.Threads < p.Threads ||
+		uint32(len(h.salt)) < p.SaltLen || uint32(len(h.key)) < p.KeyLen
+}
+
+type argon2idHash struct {
+	Params
+	salt, key []byte
+}
+
+func parseArgon2id(hash string) (argon2idHash, error) {
+	var h argon2idHash
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return h, ErrUnknownFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return h, ErrUnknownFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.Memory, &h.Time, &h.Threads); err != nil {
+		return h, ErrUnknownFormat
+	}
+	if h.Time < 1 || h.Time > MaxTime || h.Threads < 1 || h.Memory > MaxMemory {
+		return h, ErrUnknownFormat
+	}
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return h, ErrUnknownFormat
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return h, ErrUnknownFormat
+	}
+	return h, nil
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,56 @@
+package cred
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fast keeps the tests quick; real hashes use DefaultParams.
+var fast = Params{Memory: 64, Time: 1, Threads: 1}
+
+func TestHashVerify(t *testing.T) {
+	for _, p := range []Params{fast, {Algorithm: Bcrypt, BcryptCost: 4}} {
+		hash, err := p.Hash("secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := p.Verify("secret", hash); !ok || err != nil {
+			t.Fatalf("%s: expected a match, got %v, %v", hash, ok, err)
+		}
+		if ok, err := p.Verify("wrong", hash); ok || err != nil {
+			t.Fatalf("%s: expected a mismatch, got %v, %v", hash, ok, err)
+		}
+	}
+
+	hash, _ := fast.Hash("secret")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected hash %s", hash)
+	}
+	if !NeedsRehash(hash) || fast.NeedsRehash(hash) {
+		t.Error("expected weaker settings to need a rehash")
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	hash, _ := fast.Hash("secret")
+	parts := strings.Split(hash, "$")
+	for _, settings := range []string{
+		"m=64,t=0,p=1",         // argon2.IDKey panics on t=0
+		"m=64,t=1,p=0",         // and on p=0
+		"m=4294967295,t=1,p=1", // would allocate 4 TiB
+		"m=64,t=100000,p=1",
+		"m=64,t=1",
+	} {
+		parts[3] = settings
+		if _, err := fast.Verify("secret", strings.Join(parts, "$")); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("%s: expected ErrUnknownFormat, got %v", settings, err)
+		}
+	}
+	if _, err := Verify("secret", "plain"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := (Params{Memory: MaxMemory + 1}).Hash("secret"); err == nil {
+		t.Error("expected settings over the limits to be refused")
+	}
+}
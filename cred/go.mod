@@ -0,0 +1,7 @@
+module github.com/go-owl/owl/cred
+
+go 1.22
+
+require golang.org/x/crypto v0.21.0
+
+require golang.org/x/sys v0.18.0 // indirect
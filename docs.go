@@ -0,0 +1,106 @@
+package owl
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// MountDocs serves API documentation pages under prefix: Swagger UI at
+// prefix, Redoc at prefix/redoc, and the OpenAPI document they render at
+// prefix/openapi.json. spec is the document: nil for the one generated by
+// App.OpenAPI when requested, an *OpenAPI or any value encoding to JSON, or
+// a []byte holding a JSON or YAML document written by hand. Middlewares,
+// such as authentication, apply to the three routes:
+//
+//	app.MountDocs("/docs", nil, middleware.BasicAuth("docs", creds))
+//
+// The pages load Swagger UI and Redoc from the jsDelivr CDN, which a
+// Content-Security-Policy must allow.
+func (a *App) MountDocs(prefix string, spec interface{}, middlewares ...Middleware) *App {
+	prefix = strings.TrimSuffix(prefix, "/")
+	specURL := prefix + "/openapi.json"
+
+	var serveSpec Handler
+	switch spec := spec.(type) {
+	case nil:
+		serveSpec = func(c *Ctx) error {
+			return c.JSON(a.OpenAPI())
+		}
+	case []byte:
+		contentType := "application/yaml"
+		if json.Valid(spec) {
+			contentType = "application/json"
+		}
+		serveSpec = func(c *Ctx) error {
+			c.SetHeader("Content-Type", contentType)
+			_, err := c.Response.Write(spec)
+			return err
+		}
+	default:
+		b, err := json.Marshal(spec)
+		if err != nil {
+			panic(fmt.Sprintf("owl: MountDocs can't encode the spec: %v", err))
+		}
+		serveSpec = func(c *Ctx) error {
+			c.SetHeader("Content-Type", "application/json; charset=utf-8")
+			_, err := c.Response.Write(b)
+			return err
+		}
+	}
+
+	title := html.EscapeString(a.name)
+	page := func(body string) Handler {
+		return func(c *Ctx) error {
+			c.SetHeader("Cache-Control", "no-cache")
+			return c.HTML(body)
+		}
+	}
+	home := prefix
+	if home == "" {
+		home = "/"
+	}
+	a.GET(home, page(fmt.Sprintf(swaggerUIPage, title, jsString(specURL))), middlewares...)
+	a.GET(prefix+"/redoc", page(fmt.Sprintf(redocPage, title, html.EscapeString(specURL))), middlewares...)
+	a.GET(specURL, serveSpec, middlewares...)
+	return a
+}
+
+// jsString quotes s for a script.
+func jsString(s string) string {
+	b, _ := json.Marshal(s) // Escapes <, > and & too
+	return string(b)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%[1]s API</title>
+<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: %[2]s, dom_id: "#swagger-ui", deepLinking: true});
+</script>
+</body>
+</html>
+`
+
+const redocPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%[1]s API</title>
+</head>
+<body>
+<redoc spec-url="%[2]s"></redoc>
+<script src="https://cdn.jsdelivr.net/npm/redoc@2/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
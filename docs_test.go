@@ -0,0 +1,51 @@
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountDocs(t *testing.T) {
+	app := New(AppConfig{Name: "<shop>"})
+	app.GET("/items", func(c *Ctx) error { return nil })
+	deny := func(next Handler) Handler {
+		return func(c *Ctx) error {
+			if c.Header("Authorization") == "" {
+				return NewHTTPError(http.StatusUnauthorized, "login required")
+			}
+			return next(c)
+		}
+	}
+	app.MountDocs("/docs/", nil, deny)
+	app.MountDocs("/public-docs", []byte("openapi: 3.1.0\n"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer x")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/docs")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `url: "/docs/openapi.json"`) || !strings.Contains(w.Body.String(), "&lt;shop&gt; API") {
+		t.Fatalf("unexpected Swagger UI page %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/docs/redoc"); !strings.Contains(w.Body.String(), `spec-url="/docs/openapi.json"`) {
+		t.Fatalf("unexpected Redoc page %s", w.Body.String())
+	}
+	if w := get("/docs/openapi.json"); !strings.Contains(w.Body.String(), `"/items"`) {
+		t.Fatalf("expected the generated document, got %s", w.Body.String())
+	}
+	if w := get("/public-docs/openapi.json"); w.Body.String() != "openapi: 3.1.0\n" || w.Header().Get("Content-Type") != "application/yaml" {
+		t.Fatalf("expected the given document, got %q %v", w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs/openapi.json", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the middlewares to protect the docs, got %d", w.Code)
+	}
+}
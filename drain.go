@@ -0,0 +1,95 @@
+package owl
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Drain puts the App in drain mode ahead of a shutdown: readiness checks
+// fail, see Health, so that load balancers stop routing to it, and
+// responses carry Connection: close so that clients reconnect elsewhere,
+// while requests are still served. Keep-alives are disabled on the server
+// and its idle connections are closed, so that they don't hold the
+// shutdown open until the deadline. ShutdownWithContext drains the App
+// first; SupervisorConfig.DrainDelay leaves time between both.
+func (a *App) Drain() {
+	a.draining.Store(true)
+	if h := a.health.Load(); h != nil {
+		h.draining.Store(true)
+	}
+	if a.transport == nil {
+		for _, srv := range a.httpServers() {
+			srv.SetKeepAlivesEnabled(false)
+		}
+	}
+	a.CloseIdleConns()
+}
+
+// CloseIdleConns closes the keep-alive connections of the server that are
+// waiting for a request, and returns how many were closed. Clients open a
+// new connection on their next request. Connections of custom Transports
+// aren't tracked.
+func (a *App) CloseIdleConns() int {
+	return a.idle.closeAll()
+}
+
+// InFlight returns the number of requests being served by the App.
+func (a *App) InFlight() int64 {
+	return a.inFlight.Load()
+}
+
+// closeServer closes the listeners and connections of the servers, for
+// requests outliving the shutdown deadline.
+func (a *App) closeServer() error {
+	if a.transport != nil {
+		return nil
+	}
+	var errs []error
+	for _, srv := range a.httpServers() {
+		errs = append(errs, srv.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// track counts r as in flight until it is served, and asks the client to
+// close the connection in drain mode.
+func (a *App) track(w http.ResponseWriter) func() {
+	a.inFlight.Add(1)
+	if a.draining.Load() {
+		w.Header().Set("Connection", "close")
+	}
+	return func() { a.inFlight.Add(-1) }
+}
+
+// idleConns tracks the connections of a server waiting for their next
+// request, from its ConnState callback.
+type idleConns struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func (t *idleConns) connState(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if state != http.StateIdle {
+		delete(t.conns, conn)
+		return
+	}
+	if t.conns == nil {
+		t.conns = map[net.Conn]struct{}{}
+	}
+	t.conns[conn] = struct{}{}
+}
+
+func (t *idleConns) closeAll() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for conn := range t.conns {
+		conn.Close()
+	}
+	n := len(t.conns)
+	clear(t.conns)
+	return n
+}
@@ -0,0 +1,182 @@
+package owl
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error {
+		if n := app.InFlight(); n != 1 {
+			t.Errorf("expected 1 request in flight, got %d", n)
+		}
+		return c.Text("ok")
+	})
+	app.MountHealth()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Header().Get("Connection") != "" {
+		t.Fatal("expected connections to be kept before draining")
+	}
+
+	app.Drain()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 200 || w.Header().Get("Connection") != "close" {
+		t.Fatalf("expected requests to be served with Connection: close, got %d %v", w.Code, w.Header())
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness to fail while draining, got %d", w.Code)
+	}
+	if n := app.InFlight(); n != 0 {
+		t.Fatalf("expected no request in flight, got %d", n)
+	}
+}
+
+func TestSupervisorDrainDelay(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+	app.GET("/slow", func(c *Ctx) error {
+		select {
+		case <-release:
+		case <-c.Request.Context().Done():
+		}
+		return nil
+	})
+	app.MountHealth()
+
+	sup := NewSupervisor(SupervisorConfig{DrainDelay: 200 * time.Millisecond, ShutdownTimeout: 100 * time.Millisecond})
+	sup.Add("127.0.0.1:0", app)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("App didn't start: %+v", apps)
+		}
+	}
+	base := "http://" + apps[0].Addr
+
+	slow := make(chan error)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+		slow <- err
+	}()
+	for app.InFlight() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	resp, err := http.Get(base + "/readyz")
+	if err != nil {
+		t.Fatalf("expected requests to be served during the drain delay, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || !resp.Close {
+		t.Fatalf("expected a failing readiness check, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	if err := <-done; err == nil {
+		t.Fatal("expected the shutdown deadline to be reported")
+	}
+	if err := <-slow; err == nil {
+		t.Fatal("expected the request outliving the deadline to be cut short")
+	}
+}
+
+func TestCloseIdleConns(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Serve(ln)
+	defer app.Shutdown()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	get := func() (*http.Response, error) {
+		conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+		resp, err := http.ReadResponse(br, nil)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+	if _, err := get(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection turns idle once the response is flushed.
+	closed := 0
+	for deadline := time.Now().Add(time.Second); closed == 0 && time.Now().Before(deadline); {
+		closed = app.CloseIdleConns()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if closed != 1 {
+		t.Fatalf("expected 1 idle connection to be closed, got %d", closed)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := br.ReadByte(); err != io.EOF {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
+
+func TestDrainDisablesKeepAlives(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Serve(ln)
+	defer app.Shutdown()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	url := "http://" + ln.Addr().String() + "/"
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	app.Drain()
+	resp, err = client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if !resp.Close {
+		t.Fatal("expected the server to close connections while draining")
+	}
+}
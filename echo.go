@@ -0,0 +1,140 @@
+package owl
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// redactedHeaders are masked by EchoHandler.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// EchoHandler returns a debugging endpoint answering, as JSON, everything
+// Owl derives from a request: the route it matches with its params,
+// middleware chain and options, the client IP with and without trusting
+// proxy headers, the negotiated content type, and the claims and session
+// set by middlewares. It speeds up debugging integrations with proxies and
+// SDKs, which can send their usual requests below the echo prefix.
+//
+// It must be registered under a catch-all. Since it reveals the routing of
+// the App, it answers 404 unless DevMode is in effect, OWL_ENV or APP_ENV
+// names a development environment, or allow funcs are given, which must
+// all accept the request:
+//
+//	local := func(c *owl.Ctx) bool { return c.ClientIP(false) == "127.0.0.1" }
+//	app.Group("/_owl", requireAdmin).ANY("/echo/*", app.EchoHandler(local))
+//
+// A request to /_owl/echo/users/42 then describes how GET /users/42 is
+// routed. Credentials in headers and session values are redacted.
+func (a *App) EchoHandler(allow ...func(c *Ctx) bool) Handler {
+	return func(c *Ctx) error {
+		if len(allow) == 0 && !a.devMode && !IsDevelopment() {
+			return ErrNotFound
+		}
+		for _, fn := range allow {
+			if !fn(c) {
+				return ErrNotFound
+			}
+		}
+
+		r := c.Request
+		path := "/" + strings.TrimPrefix(c.Param("*"), "/")
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		headers := r.Header.Clone()
+		for _, h := range redactedHeaders {
+			if len(headers.Values(h)) > 0 {
+				headers.Set(h, "[redacted]")
+			}
+		}
+		result := map[string]interface{}{
+			"method":      r.Method,
+			"path":        path,
+			"query":       r.URL.Query(),
+			"host":        r.Host,
+			"scheme":      scheme,
+			"proto":       r.Proto,
+			"remote_addr": r.RemoteAddr,
+			"headers":     headers,
+			"client_ip": map[string]string{
+				"direct":  c.ClientIP(false),
+				"proxied": c.ClientIP(true),
+			},
+			"negotiated": negotiate(r.Header.Get("Accept"), "application/json", "application/xml", "text/xml", "text/html", "text/plain"),
+			"route":      a.echoRoute(r, path),
+		}
+		if claims := c.Claims(); claims != nil {
+			result["claims"] = claims
+		}
+		if s := c.Session(); s != nil {
+			values := s.Values()
+			for k := range values {
+				values[k] = "[redacted]"
+			}
+			result["session"] = map[string]interface{}{"id_set": s.ID() != "", "values": values}
+		}
+		return c.JSON(result)
+	}
+}
+
+// echoRoute describes the route serving method and path for EchoHandler,
+// or returns nil when none matches.
+func (a *App) echoRoute(r *http.Request, path string) map[string]interface{} {
+	mux, ok := a.mux.(*Mux)
+	if !ok {
+		return nil
+	}
+	rctx := NewRouteContext()
+	pattern := mux.Find(rctx, r.Method, path)
+	if pattern == "" {
+		return nil
+	}
+	params := map[string]string{}
+	for i, key := range rctx.URLParams.Keys {
+		params[key] = rctx.URLParams.Values[i]
+	}
+	route := map[string]interface{}{"pattern": pattern, "params": params}
+	if chain, ok := a.MiddlewareChain(r.Method, pattern); ok {
+		route["middlewares"] = chain
+	}
+
+	set := a.routes[routeKey{mux: a.mux, method: r.Method, path: pattern}]
+	if set == nil {
+		set = a.routes[routeKey{mux: a.mux, path: pattern}]
+	}
+	if set == nil {
+		return route
+	}
+	matched := set.routes[len(set.routes)-1]
+	for _, cr := range set.routes {
+		if len(cr.when) > 0 && cr.matches(r) {
+			matched = cr
+			break
+		}
+	}
+	meta := make(map[string]string, len(matched.opts.meta))
+	for k, v := range matched.opts.meta {
+		meta[k] = fmt.Sprint(v)
+	}
+	attrs := make(map[string]string, len(matched.opts.attrs))
+	for _, attr := range matched.opts.attrs {
+		attrs[attr.Key] = attr.Value
+	}
+	tags := append([]string{}, matched.opts.tags...)
+	sort.Strings(tags)
+	route["meta"] = meta
+	route["tags"] = tags
+	route["attrs"] = attrs
+	route["body_limit"] = matched.opts.bodyLimit
+	route["timeout"] = matched.opts.timeout.String()
+	route["conditional"] = len(matched.when) > 0
+	route["source"] = matched.source
+
+	rctx.RoutePatterns = []string{pattern}
+	route["log_level"] = a.routeLogLevel(rctx)
+	return route
+}
@@ -0,0 +1,91 @@
+package owl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEchoHandler(t *testing.T) {
+	app := New(AppConfig{DevMode: true})
+	app.Group("/users").Tag("users").Meta("owner", "team-a").GET("/{id}", func(c *Ctx) error { return nil })
+	withSession := func(next Handler) Handler {
+		return func(c *Ctx) error {
+			s := NewSession("abc", map[string]interface{}{"user_id": 7})
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), SessionCtxKey, s))
+			return next(c)
+		}
+	}
+	app.Group("/_owl").ANY("/echo/*", app.EchoHandler(), withSession)
+
+	r := httptest.NewRequest("GET", "/_owl/echo/users/42?expand=1", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	r.Header.Set("Accept", "application/xml;q=0.5, application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	var echo struct {
+		Path       string              `json:"path"`
+		Query      map[string][]string `json:"query"`
+		Headers    map[string][]string `json:"headers"`
+		ClientIP   map[string]string   `json:"client_ip"`
+		Negotiated string              `json:"negotiated"`
+		Session    struct {
+			Values map[string]interface{} `json:"values"`
+		} `json:"session"`
+		Route struct {
+			Pattern string            `json:"pattern"`
+			Params  map[string]string `json:"params"`
+			Tags    []string          `json:"tags"`
+			Meta    map[string]string `json:"meta"`
+		} `json:"route"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &echo); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+	if echo.Path != "/users/42" || echo.Query["expand"][0] != "1" || echo.Negotiated != "application/json" {
+		t.Fatalf("unexpected request details: %s", w.Body.String())
+	}
+	if echo.Route.Pattern != "/users/{id}" || echo.Route.Params["id"] != "42" || echo.Route.Tags[0] != "users" || echo.Route.Meta["owner"] != "team-a" {
+		t.Fatalf("unexpected route: %+v", echo.Route)
+	}
+	if echo.ClientIP["proxied"] != "203.0.113.7" || echo.ClientIP["direct"] == "203.0.113.7" {
+		t.Fatalf("unexpected client IPs: %v", echo.ClientIP)
+	}
+	if echo.Headers["Authorization"][0] != "[redacted]" {
+		t.Fatalf("expected credentials to be redacted, got %v", echo.Headers["Authorization"])
+	}
+	if echo.Session.Values["user_id"] != "[redacted]" {
+		t.Fatalf("expected session values to be redacted, got %v", echo.Session.Values)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/_owl/echo/nowhere", nil))
+	if !json.Valid(w.Body.Bytes()) || w.Code != 200 {
+		t.Fatalf("expected unmatched paths to be echoed, got %d %s", w.Code, w.Body.String())
+	}
+
+	t.Setenv("OWL_ENV", "")
+	t.Setenv("APP_ENV", "")
+	prod := New()
+	prod.ANY("/echo/*", prod.EchoHandler())
+	prod.ANY("/guarded/*", prod.EchoHandler(func(c *Ctx) bool { return c.Header("X-Debug") == "1" }))
+	for _, tc := range []struct {
+		path, debug string
+		code        int
+	}{
+		{"/echo/users", "1", 404},
+		{"/guarded/users", "", 404},
+		{"/guarded/users", "1", 200},
+	} {
+		r := httptest.NewRequest("GET", tc.path, nil)
+		r.Header.Set("X-Debug", tc.debug)
+		w := httptest.NewRecorder()
+		prod.ServeHTTP(w, r)
+		if w.Code != tc.code {
+			t.Errorf("%s with X-Debug %q: got %d, want %d", tc.path, tc.debug, w.Code, tc.code)
+		}
+	}
+}
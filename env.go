@@ -0,0 +1,40 @@
+package owl
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment returns the lower-cased value of the OWL_ENV or APP_ENV
+// environment variable, the first one set winning, or "" when neither is.
+func Environment() string {
+	for _, k := range []string{"OWL_ENV", "APP_ENV"} {
+		if v := os.Getenv(k); v != "" {
+			return strings.ToLower(v)
+		}
+	}
+	return ""
+}
+
+// IsProduction reports whether OWL_ENV or APP_ENV is "production" or
+// "prod".
+func IsProduction() bool {
+	for _, k := range []string{"OWL_ENV", "APP_ENV"} {
+		switch strings.ToLower(os.Getenv(k)) {
+		case "production", "prod":
+			return true
+		}
+	}
+	return false
+}
+
+// IsDevelopment reports whether the environment is explicitly a developer
+// machine: "development", "dev" or "local". An unset environment is not,
+// so that relaxed settings can't reach production by omission.
+func IsDevelopment() bool {
+	switch Environment() {
+	case "development", "dev", "local":
+		return true
+	}
+	return false
+}
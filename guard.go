@@ -0,0 +1,70 @@
+package owl
+
+import (
+	"crypto/subtle"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Guard returns a middleware answering err for requests that don't match
+// p, instead of running the handler. Errors go through the App's
+// ErrorHandler, so every guard renders rejections the same way:
+//
+//	internal := app.Group("/internal", owl.Guard(owl.HeaderIs("X-Internal", "true"), owl.ErrForbidden))
+//
+// RequireHeader, RequireTLS and RequireJSONContentType cover the common
+// cases.
+func Guard(p Predicate, err *HTTPError) Middleware {
+	if p == nil || err == nil {
+		panic("owl: Guard needs a Predicate and an error")
+	}
+	return func(next Handler) Handler {
+		return func(c *Ctx) error {
+			if !p(c.Request) {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireHeader rejects requests with 403 Forbidden unless header key
+// equals value exactly, e.g. a secret shared with an internal caller. With
+// an empty value, the header only needs a non-empty value.
+//
+//	app.Group("/internal", owl.RequireHeader("X-Internal", os.Getenv("INTERNAL_TOKEN")))
+func RequireHeader(key, value string) Middleware {
+	p := func(r *http.Request) bool {
+		got := r.Header.Get(key)
+		if value == "" {
+			return got != ""
+		}
+		return subtle.ConstantTimeCompare([]byte(got), []byte(value)) == 1
+	}
+	return Guard(p, NewHTTPError(http.StatusForbidden, "missing or invalid "+http.CanonicalHeaderKey(key)+" header"))
+}
+
+// RequireTLS rejects plain HTTP requests with 403 Forbidden. Behind a
+// proxy terminating TLS, guard on its header instead:
+//
+//	owl.Guard(owl.HeaderIs("X-Forwarded-Proto", "https"), owl.NewHTTPError(403, "TLS required"))
+func RequireTLS() Middleware {
+	return Guard(func(r *http.Request) bool {
+		return r.TLS != nil
+	}, NewHTTPError(http.StatusForbidden, "TLS required"))
+}
+
+// RequireJSONContentType rejects requests carrying a body that isn't
+// declared as JSON, i.e. application/json or a "+json" type such as
+// application/merge-patch+json, with 415 Unsupported Media Type. Requests
+// without a body pass.
+func RequireJSONContentType() Middleware {
+	return Guard(func(r *http.Request) bool {
+		if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+			return true
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+	}, NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be application/json"))
+}
@@ -0,0 +1,59 @@
+package owl
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGuards(t *testing.T) {
+	ok := func(c *Ctx) error { return c.Text("ok") }
+
+	app := New()
+	app.Group("/internal", RequireHeader("X-Internal", "s3cret")).GET("/", ok)
+	app.Group("/traced", RequireHeader("X-Trace", "")).GET("/", ok)
+	app.Group("/secure", RequireTLS()).GET("/", ok)
+	app.Group("/api", RequireJSONContentType()).POST("/", ok).GET("/", ok)
+
+	tests := []struct {
+		name, method, path string
+		header, value      string
+		tls                bool
+		body               string
+		code               int
+	}{
+		{"header present", "GET", "/internal/", "X-Internal", "s3cret", false, "", 200},
+		{"header missing", "GET", "/internal/", "", "", false, "", 403},
+		{"header wrong", "GET", "/internal/", "X-Internal", "no", false, "", 403},
+		{"header other case", "GET", "/internal/", "X-Internal", "S3CRET", false, "", 403},
+		{"header set", "GET", "/traced/", "X-Trace", "1", false, "", 200},
+		{"header empty", "GET", "/traced/", "X-Trace", "", false, "", 403},
+		{"tls", "GET", "/secure/", "", "", true, "", 200},
+		{"plain http", "GET", "/secure/", "", "", false, "", 403},
+		{"json", "POST", "/api/", "Content-Type", "application/json; charset=utf-8", false, "{}", 200},
+		{"json suffix", "POST", "/api/", "Content-Type", "application/merge-patch+json", false, "{}", 200},
+		{"form", "POST", "/api/", "Content-Type", "application/x-www-form-urlencoded", false, "a=1", 415},
+		{"no content type", "POST", "/api/", "", "", false, "{}", 415},
+		{"no body", "GET", "/api/", "", "", false, "", 200},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.header != "" {
+			r.Header.Set(tt.header, tt.value)
+		}
+		if tt.tls {
+			r.TLS = &tls.ConnectionState{}
+		} else {
+			r.TLS = nil
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+		if w.Code >= 400 && !strings.Contains(w.Body.String(), `"success":false`) {
+			t.Errorf("%s: expected the error handler to render the rejection, got %s", tt.name, w.Body.String())
+		}
+	}
+}
@@ -2,6 +2,8 @@ package owl
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 // Ctx represents the request context.
@@ -9,6 +11,16 @@ type Ctx struct {
 	Request  *http.Request
 	Response http.ResponseWriter
 	status   int
+
+	route  routeOptions // Settings of the matched route
+	app    *App
+	binder Binder // Returned by Bind, so that it isn't allocated per call
+
+	query    url.Values // Parsed query of the request, see queryValues
+	queryRaw string     // RawQuery query was parsed from
+
+	rctx    *Context // Routing context of rctxReq, see routeContext
+	rctxReq *http.Request
 }
 
 // newCtx creates a new Ctx.
@@ -22,12 +34,34 @@ func newCtx(w http.ResponseWriter, r *http.Request) *Ctx {
 
 // Param retrieves URL path parameter.
 func (c *Ctx) Param(key string) string {
-	return URLParam(c.Request, key)
+	if rctx := c.routeContext(); rctx != nil {
+		return rctx.URLParam(key)
+	}
+	return ""
+}
+
+// routeContext returns the routing context of the request, looked up once
+// unless a middleware replaces the request.
+func (c *Ctx) routeContext() *Context {
+	if c.rctxReq != c.Request {
+		c.rctx, c.rctxReq = RouteContext(c.Request.Context()), c.Request
+	}
+	return c.rctx
 }
 
 // Query retrieves URL query parameter.
 func (c *Ctx) Query(key string) string {
-	return Query(c.Request, key)
+	return c.queryValues().Get(key)
+}
+
+// queryValues returns the parsed query of the request, parsed once unless
+// a middleware rewrites it.
+func (c *Ctx) queryValues() url.Values {
+	if c.query == nil || c.queryRaw != c.Request.URL.RawQuery {
+		c.query, _ = url.ParseQuery(c.Request.URL.RawQuery)
+		c.queryRaw = c.Request.URL.RawQuery
+	}
+	return c.query
 }
 
 // Header retrieves request header.
@@ -47,11 +81,74 @@ func (c *Ctx) Status(code int) *Ctx {
 	return c
 }
 
+// Meta returns the metadata value declared for the matched route with
+// Group.Meta or RouteBuilder.Meta, or nil.
+func (c *Ctx) Meta(key string) interface{} {
+	return c.route.meta[key]
+}
+
+// Tags returns the tags declared for the matched route with Group.Tag or
+// RouteBuilder.Tag. The returned slice must not be modified.
+func (c *Ctx) Tags() []string {
+	return c.route.tags
+}
+
+// Attrs returns the observability attributes declared for the matched
+// route with Group.Attr or RouteBuilder.Attr. The returned slice must not
+// be modified.
+func (c *Ctx) Attrs() []Attr {
+	return c.route.attrs
+}
+
+// HasTag reports whether the matched route was declared with tag.
+func (c *Ctx) HasTag(tag string) bool {
+	for _, t := range c.route.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims returns the claims stored by an authentication middleware such as
+// middleware.JWT, or nil. Their type depends on the middleware's
+// configuration:
+//
+//	claims, _ := c.Claims().(*MyClaims)
+func (c *Ctx) Claims() interface{} {
+	return c.Request.Context().Value(ClaimsCtxKey)
+}
+
+// Session returns the session loaded by the middleware.Session middleware,
+// or nil when it isn't used.
+func (c *Ctx) Session() *Session {
+	s, _ := c.Request.Context().Value(SessionCtxKey).(*Session)
+	return s
+}
+
 // Bind returns a Binder for flexible content type binding.
 // Example: c.Bind().JSON(&data), c.Bind().XML(&data)
 func (c *Ctx) Bind() *Binder {
-	return &Binder{
-		request: c.Request,
+	c.binder = Binder{
+		request:  c.Request,
+		strict:   c.route.strictJSON,
+		validate: c.route.validateJSON,
+		ctx:      c,
+	}
+	return &c.binder
+}
+
+// deprecatedField is called by the Binder when the client sent a field
+// tagged `deprecated:"message"`. It adds a Warning header to the response
+// and calls AppConfig.OnDeprecatedField.
+func (c *Ctx) deprecatedField(field, message string) {
+	warning := "field " + strconv.Quote(field) + " is deprecated"
+	if message != "" {
+		warning += ": " + message
+	}
+	c.Response.Header().Add("Warning", "299 - "+strconv.Quote(warning))
+	if c.app != nil && c.app.onDeprecated != nil {
+		c.app.onDeprecated(c, field, message)
 	}
 }
 
@@ -59,11 +156,14 @@ func (c *Ctx) Bind() *Binder {
 // Deprecated: Use c.Bind().JSON(dst) for more flexibility.
 // This method is kept for backward compatibility.
 func (c *Ctx) BindJSON(dst interface{}) error {
-	return BindJSON(c.Request, dst)
+	return c.Bind().JSON(dst)
 }
 
-// JSON sends JSON response.
+// JSON sends JSON response, indented in DevMode.
 func (c *Ctx) JSON(data interface{}) error {
+	if c.app != nil && c.app.devMode {
+		return writeJSON(c.Response, c.status, data, "  ")
+	}
 	return JSON(c.Response, c.status, data)
 }
 
@@ -72,14 +172,47 @@ func (c *Ctx) Text(text string) error {
 	return Text(c.Response, c.status, text)
 }
 
+// HTML sends HTML response.
+func (c *Ctx) HTML(html string) error {
+	return HTML(c.Response, c.status, html)
+}
+
+// CSV sends records as CSV response.
+func (c *Ctx) CSV(records [][]string) error {
+	return CSV(c.Response, c.status, records)
+}
+
 // ClientIP returns client IP address.
 func (c *Ctx) ClientIP(trustProxy bool) string {
 	return ClientIP(c.Request, trustProxy)
 }
 
+// Attr is a static key/value attribute declared on a route, see Group.Attr.
+type Attr struct {
+	Key   string
+	Value string
+}
+
 // Handler is the DX layer handler that returns an error.
 type Handler func(*Ctx) error
 
+// WrapHTTP adapts a net/http handler to a Handler, so legacy handlers can be
+// registered on Apps and Groups as they are, with prefixes and Owl-style
+// middlewares applied:
+//
+//	api.GET("/legacy", owl.WrapHTTP(legacyHandler))
+func WrapHTTP(h http.Handler) Handler {
+	return func(c *Ctx) error {
+		h.ServeHTTP(c.Response, c.Request)
+		return nil
+	}
+}
+
+// WrapHTTPFunc adapts a net/http handler function to a Handler, see WrapHTTP.
+func WrapHTTPFunc(fn func(http.ResponseWriter, *http.Request)) Handler {
+	return WrapHTTP(http.HandlerFunc(fn))
+}
+
 // Middleware wraps a Handler.
 type Middleware func(Handler) Handler
 
@@ -97,12 +230,16 @@ func defaultErrorHandler(c *Ctx, err error) {
 		_ = JSON(c.Response, httpErr.Code, map[string]interface{}{
 			"success": false,
 			"code":    httpErr.Code,
-			"message": httpErr.Message,
+			"message": c.translate(httpErr.Message),
 		})
 		return
 	}
 
 	// Unknown error -> 500
+	if c.app != nil && c.app.logger != nil {
+		c.app.logger.ErrorContext(c.Request.Context(), "handler error",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "status", http.StatusInternalServerError, "error", err)
+	}
 	_ = JSON(c.Response, http.StatusInternalServerError, map[string]interface{}{
 		"success": false,
 		"code":    http.StatusInternalServerError,
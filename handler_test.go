@@ -0,0 +1,29 @@
+package owl
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCtxParamNoAlloc(t *testing.T) {
+	app := New()
+	app.GET("/users/{id}/posts/{post}", func(c *Ctx) error {
+		if c.Param("id") != "7" || c.Param("post") != "9" {
+			t.Errorf("unexpected params %q %q", c.Param("id"), c.Param("post"))
+		}
+		if allocs := testing.AllocsPerRun(100, func() { c.Param("post") }); allocs != 0 {
+			t.Errorf("expected Param not to allocate, got %v allocations", allocs)
+		}
+
+		// A middleware replacing the request is noticed.
+		rctx := NewRouteContext()
+		rctx.URLParams.Add("id", "8")
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), RouteCtxKey, rctx))
+		if c.Param("id") != "8" {
+			t.Errorf("expected the routing context of the new request, got %q", c.Param("id"))
+		}
+		return nil
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7/posts/9", nil))
+}
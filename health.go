@@ -0,0 +1,153 @@
+package owl
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker reports whether a dependency, such as a database, is
+// usable. It should honor the deadline of ctx.
+type HealthChecker func(ctx context.Context) error
+
+// Health aggregates the named checks of an App for liveness and readiness
+// probes, see App.MountHealth. It is safe for concurrent use.
+//
+//	app.Health().Check("db", db.PingContext).Check("cache", cache.Ping)
+//	app.MountHealth()
+type Health struct {
+	draining atomic.Bool
+
+	mu      sync.RWMutex
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+type namedCheck struct {
+	name  string
+	check HealthChecker
+}
+
+// Health returns the health checks of the App. Readiness is reported as
+// draining once the App starts shutting down, see Drain, so that load
+// balancers stop routing to it.
+func (a *App) Health() *Health {
+	a.healthOnce.Do(func() {
+		h := &Health{timeout: 5 * time.Second}
+		h.draining.Store(a.draining.Load())
+		a.health.Store(h)
+	})
+	return a.health.Load()
+}
+
+// Check registers a readiness check under name, replacing any check of
+// the same name.
+func (h *Health) Check(name string, check HealthChecker) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range h.checks {
+		if c.name == name {
+			h.checks[i].check = check
+			return h
+		}
+	}
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+	return h
+}
+
+// SetTimeout sets how long each check may run before it fails
+// (default: 5s).
+func (h *Health) SetTimeout(d time.Duration) *Health {
+	h.mu.Lock()
+	h.timeout = d
+	h.mu.Unlock()
+	return h
+}
+
+// Draining reports whether the App is shutting down.
+func (h *Health) Draining() bool {
+	return h.draining.Load()
+}
+
+// HealthStatus is the result of a check, see Health.Run.
+type HealthStatus struct {
+	Status  string  `json:"status"` // "ok" or "error"
+	Error   string  `json:"error,omitempty"`
+	Latency float64 `json:"latency_ms"`
+}
+
+// Run runs the checks concurrently and returns their results by name,
+// and whether they all passed.
+func (h *Health) Run(ctx context.Context) (map[string]HealthStatus, bool) {
+	h.mu.RLock()
+	checks, timeout := append([]namedCheck(nil), h.checks...), h.timeout
+	h.mu.RUnlock()
+
+	results := make([]HealthStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := c.check(ctx)
+			results[i] = HealthStatus{Status: "ok", Latency: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				results[i].Status, results[i].Error = "error", err.Error()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	ok := true
+	byName := make(map[string]HealthStatus, len(checks))
+	for i, c := range checks {
+		byName[c.name] = results[i]
+		ok = ok && results[i].Status == "ok"
+	}
+	return byName, ok
+}
+
+// LiveHandler answers 200 while the process serves requests, for liveness
+// probes. It doesn't run the checks: a failing dependency shouldn't get
+// the process restarted.
+func (h *Health) LiveHandler() Handler {
+	return func(c *Ctx) error {
+		c.SetHeader("Cache-Control", "no-store")
+		return c.JSON(map[string]interface{}{"status": "ok"})
+	}
+}
+
+// ReadyHandler runs the checks and answers their results, with status 503
+// when one fails or the App is draining, for readiness probes.
+func (h *Health) ReadyHandler() Handler {
+	return func(c *Ctx) error {
+		c.SetHeader("Cache-Control", "no-store")
+		checks, ok := h.Run(c.Request.Context())
+		status := "ok"
+		switch {
+		case h.Draining():
+			status = "draining"
+		case !ok:
+			status = "unavailable"
+		}
+		if status != "ok" {
+			c.Status(http.StatusServiceUnavailable)
+		}
+		return c.JSON(map[string]interface{}{"status": status, "checks": checks})
+	}
+}
+
+// MountHealth registers GET /healthz, the liveness probe, and GET /readyz,
+// the readiness probe, see Health. Middlewares, such as an IP allowlist,
+// apply to both.
+func (a *App) MountHealth(middlewares ...Middleware) *App {
+	h := a.Health()
+	a.GET("/healthz", h.LiveHandler(), middlewares...)
+	a.GET("/readyz", h.ReadyHandler(), middlewares...)
+	return a
+}
@@ -0,0 +1,64 @@
+package owl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	app := New()
+	var dbErr error
+	app.Health().
+		Check("db", func(ctx context.Context) error { return dbErr }).
+		Check("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		SetTimeout(10 * time.Millisecond)
+	app.MountHealth()
+
+	get := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	if code, body := get("/healthz"); code != 200 || body["status"] != "ok" {
+		t.Fatalf("liveness: got %d %v", code, body)
+	}
+	code, body := get("/readyz")
+	checks, _ := body["checks"].(map[string]interface{})
+	db, _ := checks["db"].(map[string]interface{})
+	slow, _ := checks["slow"].(map[string]interface{})
+	if code != http.StatusServiceUnavailable || body["status"] != "unavailable" || db["status"] != "ok" || slow["error"] != "context deadline exceeded" {
+		t.Fatalf("readiness: got %d %v", code, body)
+	}
+	if _, ok := db["latency_ms"]; !ok {
+		t.Fatalf("expected check latencies, got %v", db)
+	}
+
+	app.Health().Check("slow", func(ctx context.Context) error { return nil })
+	if code, body := get("/readyz"); code != 200 || body["status"] != "ok" {
+		t.Fatalf("readiness: got %d %v", code, body)
+	}
+	dbErr = errors.New("connection refused")
+	if code, body := get("/readyz"); code != http.StatusServiceUnavailable || body["checks"].(map[string]interface{})["db"].(map[string]interface{})["error"] != "connection refused" {
+		t.Fatalf("readiness: got %d %v", code, body)
+	}
+
+	dbErr = nil
+	app.Shutdown()
+	if code, body := get("/readyz"); code != http.StatusServiceUnavailable || body["status"] != "draining" || !app.Health().Draining() {
+		t.Fatalf("expected readiness to fail while draining, got %d %v", code, body)
+	}
+	if code, _ := get("/healthz"); code != 200 {
+		t.Fatalf("expected liveness to pass while draining, got %d", code)
+	}
+}
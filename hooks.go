@@ -0,0 +1,122 @@
+package owl
+
+import (
+	"slices"
+	"sync"
+)
+
+// RouteInfo describes a route as it is registered, see Hooks.OnRoute.
+type RouteInfo struct {
+	Method string // "" for routes matching every method
+	Path   string // As registered, e.g. "/files/*filepath"
+	Host   string // Host pattern of routes registered with Host, or ""
+	Source string // file:line of the registration
+}
+
+// Hooks lets plugins run code at points of the App lifecycle: warm caches
+// once the server listens, flush buffers on shutdown, or document routes
+// as they are registered. Hooks run synchronously, in the order they were
+// added. It is safe for concurrent use.
+//
+//	app.Hooks().
+//		OnListen(func(addr string) { cache.Warm() }).
+//		OnShutdownDone(func(err error) { metrics.Flush() })
+type Hooks struct {
+	mu              sync.Mutex
+	onListen        []func(addr string)
+	onShutdownStart []func()
+	onShutdownDone  []func(err error)
+	onRoute         []func(RouteInfo)
+}
+
+// Hooks returns the lifecycle hooks of the App.
+func (a *App) Hooks() *Hooks {
+	a.hooksOnce.Do(func() {
+		a.hooks.Store(&Hooks{})
+	})
+	return a.hooks.Load()
+}
+
+// OnListen adds fn, called with the address served once the App is built
+// and its listener open, by Start, StartTLS, Serve, Run and Supervisor.
+func (h *Hooks) OnListen(fn func(addr string)) *Hooks {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onListen = append(h.onListen, fn)
+	return h
+}
+
+// OnShutdownStart adds fn, called when ShutdownWithContext starts, before
+// in-flight requests are waited for.
+func (h *Hooks) OnShutdownStart(fn func()) *Hooks {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onShutdownStart = append(h.onShutdownStart, fn)
+	return h
+}
+
+// OnShutdownDone adds fn, called once ShutdownWithContext has waited for
+// in-flight requests, with its error.
+func (h *Hooks) OnShutdownDone(fn func(err error)) *Hooks {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onShutdownDone = append(h.onShutdownDone, fn)
+	return h
+}
+
+// OnRoute adds fn, called for every route registered from then on, once
+// per method.
+func (h *Hooks) OnRoute(fn func(RouteInfo)) *Hooks {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onRoute = append(h.onRoute, fn)
+	return h
+}
+
+// runListen calls the OnListen hooks of a, if any.
+func (a *App) runListen(addr string) {
+	if h := a.hooks.Load(); h != nil {
+		h.mu.Lock()
+		hooks := slices.Clone(h.onListen)
+		h.mu.Unlock()
+		for _, fn := range hooks {
+			fn(addr)
+		}
+	}
+}
+
+// runShutdownStart calls the OnShutdownStart hooks of a, if any.
+func (a *App) runShutdownStart() {
+	if h := a.hooks.Load(); h != nil {
+		h.mu.Lock()
+		hooks := slices.Clone(h.onShutdownStart)
+		h.mu.Unlock()
+		for _, fn := range hooks {
+			fn()
+		}
+	}
+}
+
+// runShutdownDone calls the OnShutdownDone hooks of a, if any.
+func (a *App) runShutdownDone(err error) {
+	if h := a.hooks.Load(); h != nil {
+		h.mu.Lock()
+		hooks := slices.Clone(h.onShutdownDone)
+		h.mu.Unlock()
+		for _, fn := range hooks {
+			fn(err)
+		}
+	}
+}
+
+// runRoute calls the OnRoute hooks of a, if any.
+func (a *App) runRoute(info RouteInfo) {
+	if h := a.hooks.Load(); h != nil {
+		h.mu.Lock()
+		hooks := slices.Clone(h.onRoute)
+		h.mu.Unlock()
+		for _, fn := range hooks {
+			fn(info)
+		}
+	}
+}
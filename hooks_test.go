@@ -0,0 +1,56 @@
+package owl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	app := New()
+	var routes []RouteInfo
+	var events []string
+	app.Hooks().
+		OnRoute(func(info RouteInfo) { routes = append(routes, info) }).
+		OnListen(func(addr string) { events = append(events, "listen") }).
+		OnShutdownStart(func() { events = append(events, "shutdown") }).
+		OnShutdownDone(func(err error) { events = append(events, "done") })
+
+	app.GET("/users/{id}", func(c *Ctx) error { return c.Text("ok") })
+	app.Host("api.example.com").GET("/files/*path", func(c *Ctx) error { return nil })
+	if len(routes) != 2 || routes[0].Method != "GET" || routes[0].Path != "/users/{id}" || routes[0].Host != "" ||
+		routes[1].Path != "/files/*path" || routes[1].Host != "api.example.com" {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+	if !strings.Contains(routes[0].Source, "hooks_test.go:") {
+		t.Fatalf("expected the registration site, got %q", routes[0].Source)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- app.Serve(ln) }()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if resp, err := http.Get("http://" + ln.Addr().String() + "/users/1"); err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the App didn't start")
+		}
+	}
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != http.ErrServerClosed {
+		t.Fatalf("expected the server to be closed, got %v", err)
+	}
+	if strings.Join(events, ",") != "listen,shutdown,done" {
+		t.Fatalf("unexpected events %v", events)
+	}
+}
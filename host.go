@@ -0,0 +1,132 @@
+package owl
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// hostRoute is a routing tree that only serves requests for a host pattern.
+type hostRoute struct {
+	labels []string // pattern split on "."; "{name}" captures, "*" matches any label
+	mux    *Mux
+
+	once    sync.Once
+	handler http.Handler // mux wrapped in the App's net/http middlewares
+}
+
+// Host returns a route group that only matches requests whose Host header
+// matches pattern. Labels written as {name} capture that part of the host
+// and are available through c.Param, and a "*" label matches any single
+// label:
+//
+//	api := app.Host("api.example.com")
+//	api.GET("/status", status)
+//
+//	tenant := app.Host("{tenant}.example.com")
+//	tenant.GET("/", func(c *owl.Ctx) error {
+//		return c.Text("hello " + c.Param("tenant"))
+//	})
+//
+// Hosts are matched in registration order before the App's default routes,
+// and requests for unknown hosts fall through to the default routes. The
+// port and letter case of the Host header are ignored. The App's net/http
+// middlewares and error handler apply to host routes as well.
+func (a *App) Host(pattern string, middlewares ...Middleware) *Group {
+	return &Group{
+		app:   a,
+		mux:   a.hostMux(pattern),
+		scope: a.scope.child(middlewares...),
+		opts:  a.routeDefaults(),
+	}
+}
+
+// hostMux returns the routing tree for a host pattern, creating it on first
+// use so that repeated Host calls share routes.
+func (a *App) hostMux(pattern string) *Mux {
+	pattern = strings.ToLower(pattern)
+	for _, hr := range a.hosts {
+		if strings.Join(hr.labels, ".") == pattern {
+			return hr.mux
+		}
+	}
+
+	labels := strings.Split(pattern, ".")
+	for _, l := range labels {
+		if l == "" {
+			panic(fmt.Sprintf("owl: invalid host pattern '%s'", pattern))
+		}
+	}
+	a.mutate("Host")
+	hr := &hostRoute{labels: labels, mux: NewMux()}
+	if mux, ok := a.mux.(*Mux); ok {
+		hr.mux.autoHead = mux.autoHead
+		hr.mux.autoOptions = mux.autoOptions
+		hr.mux.trailingSlash = mux.trailingSlash
+		hr.mux.caseInsensitive = mux.caseInsensitive
+	}
+	hr.mux.Use(hr.setParams)
+	a.hosts = append(a.hosts, hr)
+	return hr.mux
+}
+
+// serveHost serves r with the first matching host route and reports
+// whether one matched.
+func (a *App) serveHost(w http.ResponseWriter, r *http.Request) bool {
+	host := requestHost(r)
+	for _, hr := range a.hosts {
+		if _, ok := hr.match(host); ok {
+			hr.once.Do(func() {
+				hr.handler = chain(a.mux.Middlewares(), hr.mux)
+			})
+			hr.handler.ServeHTTP(w, r)
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether host matches the pattern and returns the captured
+// labels as alternating key/value pairs.
+func (hr *hostRoute) match(host string) ([]string, bool) {
+	labels := strings.Split(host, ".")
+	if len(labels) != len(hr.labels) {
+		return nil, false
+	}
+	var params []string
+	for i, l := range hr.labels {
+		switch {
+		case len(l) > 2 && l[0] == '{' && l[len(l)-1] == '}':
+			params = append(params, l[1:len(l)-1], labels[i])
+		case l == "*":
+		case l != labels[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// setParams is the first middleware of a host mux and exposes the captured
+// host labels as URL params.
+func (hr *hostRoute) setParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if params, _ := hr.match(requestHost(r)); len(params) > 0 {
+			rctx := RouteContext(r.Context())
+			for i := 0; i < len(params); i += 2 {
+				rctx.URLParams.Add(params[i], params[i+1])
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestHost returns the lower-cased request host without port.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostRouting(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("default") })
+	app.Host("api.example.com").GET("/", func(c *Ctx) error { return c.Text("api") })
+	app.Host("{tenant}.example.com").GET("/users/{id}", func(c *Ctx) error {
+		return c.Text(c.Param("tenant") + ":" + c.Param("id"))
+	})
+
+	tests := []struct {
+		host, path string
+		code       int
+		body       string
+	}{
+		{"api.example.com", "/", 200, "api"},
+		{"API.example.com:8080", "/", 200, "api"},
+		{"acme.example.com", "/users/7", 200, "acme:7"},
+		{"acme.example.com", "/", 404, ""},
+		{"other.org", "/", 200, "default"},
+		{"a.b.example.com", "/", 200, "default"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		r.Host = tt.host
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s%s: got %d %q", tt.host, tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHostRoutingUsesAppMiddlewares(t *testing.T) {
+	app := New()
+	app.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.Host("*.example.com").GET("/", func(c *Ctx) error { return c.Text("ok") })
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Host = "x.example.com"
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Body.String() != "ok" || w.Header().Get("X-Std") != "1" {
+		t.Fatalf("unexpected response %q %v", w.Body.String(), w.Header())
+	}
+}
+
+func TestHostPattern(t *testing.T) {
+	app := New()
+	app.HandlePattern("GET api.example.com/status", func(c *Ctx) error { return c.Text("api") })
+	app.Host("api.example.com").GET("/other", func(c *Ctx) error { return c.Text("other") })
+
+	for path, body := range map[string]string{"/status": "api", "/other": "other"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Host = "api.example.com"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Body.String() != body {
+			t.Errorf("%s: got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package owl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// I18n holds the message catalogs of an App by locale and negotiates the
+// locale of requests, see Ctx.T. The default error handler also
+// translates the messages of HTTPErrors, used as keys. It is safe for
+// concurrent use.
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	i18n := owl.NewI18n("en")
+//	if err := i18n.LoadFS(locales, "locales/*.json"); err != nil {
+//		log.Fatal(err)
+//	}
+//	app.Use(i18n.Middleware)
+type I18n struct {
+	fallback string
+
+	mu       sync.RWMutex
+	catalogs map[string]map[string]string // By lower-cased locale
+	locales  []string
+}
+
+// NewI18n returns an empty I18n answering fallback, e.g. "en", when no
+// catalog matches the request.
+func NewI18n(fallback string) *I18n {
+	return &I18n{fallback: fallback, catalogs: map[string]map[string]string{}}
+}
+
+// Add merges messages into the catalog of locale, e.g. "fr" or "pt-BR".
+// Messages are fmt format strings.
+func (b *I18n) Add(locale string, messages map[string]string) *I18n {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := strings.ToLower(locale)
+	catalog := b.catalogs[key]
+	if catalog == nil {
+		catalog = map[string]string{}
+		b.catalogs[key] = catalog
+		b.locales = append(b.locales, locale)
+		sort.Strings(b.locales)
+	}
+	for k, v := range messages {
+		catalog[k] = v
+	}
+	return b
+}
+
+// LoadFS adds the catalogs of the JSON files of fsys matching pattern,
+// such as "locales/*.json", each named after its locale, e.g.
+// locales/fr.json, and holding an object of messages.
+func (b *I18n) LoadFS(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("owl: loading catalog '%s': %w", name, err)
+		}
+		b.Add(strings.TrimSuffix(path.Base(name), path.Ext(name)), messages)
+	}
+	return nil
+}
+
+// Locales returns the locales with a catalog.
+func (b *I18n) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]string(nil), b.locales...)
+}
+
+// Match returns the locale best matching an Accept-Language header value:
+// the preferred locale with a catalog, its base language, e.g. "fr" for
+// "fr-CA", or the fallback.
+func (b *I18n) Match(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		c := candidate{tag: strings.ToLower(strings.TrimSpace(tag)), q: 1}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(v, 64); err == nil {
+				c.q = q
+			}
+		}
+		if c.tag != "" && c.q > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, c := range candidates {
+		for _, tag := range []string{c.tag, strings.SplitN(c.tag, "-", 2)[0]} {
+			if _, ok := b.catalogs[tag]; ok {
+				return b.canonical(tag)
+			}
+		}
+	}
+	return b.fallback
+}
+
+// canonical returns locale with the case it was added with.
+func (b *I18n) canonical(locale string) string {
+	for _, l := range b.locales {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+	}
+	return locale
+}
+
+// T translates key into locale, falling back to the fallback locale and
+// then to key itself, and formats the message with args. A nil I18n
+// formats key itself.
+func (b *I18n) T(locale, key string, args ...interface{}) string {
+	var msg string
+	ok := false
+	if b != nil {
+		b.mu.RLock()
+		msg, ok = b.catalogs[strings.ToLower(locale)][key]
+		if !ok {
+			msg, ok = b.catalogs[strings.ToLower(b.fallback)][key]
+		}
+		b.mu.RUnlock()
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// localeCtx is stored under LocaleCtxKey.
+type localeCtx struct {
+	i18n   *I18n
+	locale string
+}
+
+// Middleware negotiates the locale of each request from its
+// Accept-Language header, for Ctx.Locale and Ctx.T, and sets the
+// Content-Language header of the response.
+func (b *I18n) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := b.Match(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", locale)
+		ctx := context.WithValue(r.Context(), LocaleCtxKey, &localeCtx{i18n: b, locale: locale})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Locale returns the locale negotiated by I18n.Middleware, or "".
+func (c *Ctx) Locale() string {
+	if lc, ok := c.Request.Context().Value(LocaleCtxKey).(*localeCtx); ok {
+		return lc.locale
+	}
+	return ""
+}
+
+// T translates key into the locale of the request, see I18n.T. Without
+// I18n.Middleware, it formats key itself with args.
+func (c *Ctx) T(key string, args ...interface{}) string {
+	if lc, ok := c.Request.Context().Value(LocaleCtxKey).(*localeCtx); ok {
+		return lc.i18n.T(lc.locale, key, args...)
+	}
+	return (*I18n)(nil).T("", key, args...)
+}
+
+// translate returns the translation of msg in the locale of the request,
+// or msg itself, without formatting it.
+func (c *Ctx) translate(msg string) string {
+	if lc, ok := c.Request.Context().Value(LocaleCtxKey).(*localeCtx); ok {
+		return lc.i18n.T(lc.locale, msg)
+	}
+	return msg
+}
@@ -0,0 +1,65 @@
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestI18n(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"hello": "Hello, %s!", "Not Found": "Not Found"}`)},
+		"locales/fr.json":    {Data: []byte(`{"hello": "Bonjour, %s !", "Not Found": "Introuvable"}`)},
+		"locales/pt-BR.json": {Data: []byte(`{"hello": "Olá, %s!"}`)},
+	}
+	i18n := NewI18n("en")
+	if err := i18n.LoadFS(fsys, "locales/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(i18n.Locales(), ","); got != "en,fr,pt-BR" {
+		t.Fatalf("unexpected locales %s", got)
+	}
+
+	tests := []struct{ accept, locale string }{
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr"},
+		{"de, en;q=0.5, fr;q=0.7", "fr"},
+		{"pt-br", "pt-BR"},
+		{"fr;q=0, de", "en"},
+		{"", "en"},
+	}
+	for _, tt := range tests {
+		if got := i18n.Match(tt.accept); got != tt.locale {
+			t.Errorf("Match(%q): got %s, want %s", tt.accept, got, tt.locale)
+		}
+	}
+	if got := i18n.T("pt-BR", "Not Found"); got != "Not Found" {
+		t.Errorf("expected the fallback locale to be used, got %q", got)
+	}
+
+	app := New()
+	app.Use(i18n.Middleware)
+	app.GET("/hello", func(c *Ctx) error {
+		return c.Text(c.Locale() + ": " + c.T("hello", "Ana"))
+	})
+	app.GET("/missing", func(c *Ctx) error {
+		return NewHTTPError(http.StatusNotFound, "Not Found")
+	})
+
+	r := httptest.NewRequest("GET", "/hello", nil)
+	r.Header.Set("Accept-Language", "fr-FR")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Body.String() != "fr: Bonjour, Ana !" || w.Header().Get("Content-Language") != "fr" || w.Header().Get("Vary") != "Accept-Language" {
+		t.Fatalf("got %q, headers %v", w.Body.String(), w.Header())
+	}
+
+	r = httptest.NewRequest("GET", "/missing", nil)
+	r.Header.Set("Accept-Language", "fr")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), `"message":"Introuvable"`) {
+		t.Fatalf("expected a localized error message, got %s", w.Body.String())
+	}
+}
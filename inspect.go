@@ -0,0 +1,166 @@
+package owl
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Named gives mw a name, shown by App.MiddlewareChain and
+// App.DumpMiddlewares instead of the name of its function. It is most
+// useful for middlewares built by closures, which otherwise show up as
+// "pkg.Factory.func1":
+//
+//	admin := app.Group("/admin", owl.Named("auth:admin", RequireRole("admin")))
+//
+//go:noinline
+func Named(name string, mw Middleware) Middleware {
+	return func(next Handler) Handler {
+		if sameFunc(next, nameProbe) {
+			return func(*Ctx) error { return middlewareName(name) }
+		}
+		return mw(next)
+	}
+}
+
+// namedPrototype runs the same code as every middleware returned by Named,
+// which isn't inlined so that its closure isn't duplicated in callers.
+var namedPrototype = Named("", nil)
+
+// middlewareName is how a named middleware given nameProbe as next handler
+// reports its name.
+type middlewareName string
+
+func (n middlewareName) Error() string { return string(n) }
+
+// nameProbe is the next handler given to named middlewares to ask for
+// their name, see middlewareNameOf.
+func nameProbe(*Ctx) error { return nil }
+
+// middlewareNameOf returns the name given to mw by Named, or the name of
+// its function.
+func middlewareNameOf(mw Middleware) string {
+	if sameFunc(mw, namedPrototype) {
+		if name, ok := mw(nameProbe)(nil).(middlewareName); ok && name != "" {
+			return string(name)
+		}
+	}
+	return funcName(mw)
+}
+
+// sameFunc reports whether f and g run the same code, such as two
+// closures of the same function literal.
+func sameFunc(f, g interface{}) bool {
+	return reflect.ValueOf(f).Pointer() == reflect.ValueOf(g).Pointer()
+}
+
+// MiddlewareChain returns the effective middleware chain of the route
+// registered for method and pattern, outermost first: the App's net/http
+// middlewares, then the Owl-style middlewares of the App, its groups and
+// the route itself. It reports false when no such route was registered.
+//
+//	chain, _ := app.MiddlewareChain("GET", "/admin/users")
+//	// [middleware.RequestID middleware.Logger auth:admin]
+//
+// Routes registered with Host are not covered; use DumpMiddlewares.
+func (a *App) MiddlewareChain(method, pattern string) ([]string, bool) {
+	a.compile()
+	pattern, _ = splitWildcard(pattern)
+	set := a.routes[routeKey{mux: a.mux, method: strings.ToUpper(method), path: pattern}]
+	if set == nil {
+		set = a.routes[routeKey{mux: a.mux, path: pattern}]
+	}
+	if set == nil {
+		return nil, false
+	}
+	return a.middlewareChain(a.mux, set), true
+}
+
+// DumpMiddlewares writes the middleware chain of every route registered on
+// the App, one route per line, sorted by host, pattern and method, followed
+// by the summary of routes documented with Doc:
+//
+//	GET /admin/users: middleware.RequestID > middleware.Logger > auth:admin # List users
+//
+// Routes of Apps attached with Mount are not listed.
+func (a *App) DumpMiddlewares(w io.Writer) error {
+	type line struct{ host, pattern, method, chain, summary string }
+
+	a.compile()
+	lines := make([]line, 0, len(a.routes))
+	for key, set := range a.routes {
+		method := key.method
+		if method == "" {
+			method = "*"
+		}
+		lines = append(lines, line{
+			host:    a.hostPattern(key.mux),
+			pattern: key.path,
+			method:  method,
+			chain:   strings.Join(a.middlewareChain(key.mux, set), " > "),
+		})
+		if doc := documentedRoute(set).doc; doc != nil && doc.Summary != "" {
+			lines[len(lines)-1].summary = " # " + doc.Summary
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].host != lines[j].host {
+			return lines[i].host < lines[j].host
+		}
+		if lines[i].pattern != lines[j].pattern {
+			return lines[i].pattern < lines[j].pattern
+		}
+		return lines[i].method < lines[j].method
+	})
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "%s %s%s: %s%s\n", l.method, l.host, l.pattern, l.chain, l.summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// middlewareChain lists the middlewares run for the last route of set,
+// which was registered on mux.
+func (a *App) middlewareChain(mux RouterBackend, set *routeSet) []string {
+	var names []string
+	for _, mw := range a.mux.Middlewares() {
+		names = append(names, funcName(mw))
+	}
+	if mux != a.mux {
+		// Skip the host mux's own param-capturing middleware.
+		for _, mw := range mux.Middlewares()[1:] {
+			names = append(names, funcName(mw))
+		}
+	}
+	return append(names, set.routes[len(set.routes)-1].middlewares...)
+}
+
+// hostPattern returns the host pattern served by mux, or "" for the App's
+// default routes.
+func (a *App) hostPattern(mux RouterBackend) string {
+	for _, hr := range a.hosts {
+		if hr.mux == mux {
+			return strings.Join(hr.labels, ".")
+		}
+	}
+	return ""
+}
+
+// funcName returns the package-qualified name of the function f, without
+// the import path.
+func funcName(f interface{}) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package owl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func requestTag(next http.Handler) http.Handler { return next }
+
+func auditMiddleware(next Handler) Handler { return next }
+
+func TestMiddlewareChain(t *testing.T) {
+	role := func(name string) Middleware {
+		return Named("role:"+name, func(next Handler) Handler { return next })
+	}
+	ok := func(c *Ctx) error { return nil }
+
+	app := New()
+	app.Use(requestTag)
+	app.Use(Middleware(auditMiddleware))
+	admin := app.Group("/admin", role("admin"))
+	admin.GET("/users", ok, Named("cache", func(next Handler) Handler { return next }))
+	admin.POST("/users", ok).Doc(Doc{Summary: "Create a user"})
+	app.Host("api.example.com").GET("/status", ok)
+
+	chain, found := app.MiddlewareChain("get", "/admin/users")
+	want := "owl.requestTag > owl.auditMiddleware > role:admin > cache"
+	if !found || strings.Join(chain, " > ") != want {
+		t.Fatalf("got %q, want %q", chain, want)
+	}
+	if _, found := app.MiddlewareChain("GET", "/missing"); found {
+		t.Fatal("expected no chain for unknown route")
+	}
+
+	var sb strings.Builder
+	if err := app.DumpMiddlewares(&sb); err != nil {
+		t.Fatal(err)
+	}
+	wantDump := "GET /admin/users: " + want + "\n" +
+		"POST /admin/users: owl.requestTag > owl.auditMiddleware > role:admin # Create a user\n" +
+		"GET api.example.com/status: owl.requestTag > owl.auditMiddleware\n"
+	if sb.String() != wantDump {
+		t.Fatalf("unexpected dump:\n%s", sb.String())
+	}
+}
+
+func TestNamedBuildingApp(t *testing.T) {
+	ok := func(c *Ctx) error { return c.Text("ok") }
+	// A middleware factory building another App while the first one builds.
+	nested := Named("nested", func(next Handler) Handler {
+		inner := New()
+		inner.GET("/", ok, Named("inner", func(next Handler) Handler { return next }))
+		if err := inner.Build(); err != nil {
+			t.Error(err)
+		}
+		return next
+	})
+
+	app := New()
+	app.GET("/", ok, nested)
+	done := make(chan error, 1)
+	go func() { done <- app.Build() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Build deadlocked")
+	}
+	if chain, _ := app.MiddlewareChain("GET", "/"); strings.Join(chain, " > ") != "nested" {
+		t.Fatalf("unexpected chain %q", chain)
+	}
+}
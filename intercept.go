@@ -0,0 +1,113 @@
+package owl
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+)
+
+// Interceptor rewrites a response before it is written: it receives the
+// status and body set by the handler, or by the error handler, and returns
+// the ones to send. Headers can be changed through c.Response.Header().
+type Interceptor func(c *Ctx, status int, body []byte) (int, []byte)
+
+// UseAfter adds interceptors running, in order, on the buffered response of
+// every route once its handler returns, for cross-cutting rewrites such as
+// envelopes, PII scrubbing or HTML post-processing:
+//
+//	app.UseAfter(func(c *owl.Ctx, status int, body []byte) (int, []byte) {
+//		return status, ssnPattern.ReplaceAll(body, []byte("***-**-****"))
+//	})
+//
+// Like other Owl-style middlewares, it wraps the middlewares added after
+// it. Responses flushed by the handler, such as streams, are sent as they
+// are written and skip the interceptors.
+func (a *App) UseAfter(interceptors ...Interceptor) *App {
+	return a.Use(Named("owl.UseAfter", Middleware(func(next Handler) Handler {
+		return func(c *Ctx) error {
+			orig := c.Response
+			iw := &interceptWriter{ResponseWriter: orig}
+			c.Response = iw
+			defer func() { c.Response = orig }()
+
+			if err := next(c); err != nil {
+				a.errorHandler(c, err)
+			}
+			if iw.passthrough {
+				return nil
+			}
+
+			status, body := iw.code, iw.buf.Bytes()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			for _, ic := range interceptors {
+				status, body = ic(c, status, body)
+			}
+			if orig.Header().Get("Content-Length") != "" {
+				orig.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			}
+			orig.WriteHeader(status)
+			_, err := orig.Write(body)
+			return err
+		}
+	})))
+}
+
+// interceptWriter buffers a response for UseAfter, until it is flushed.
+// Its ResponseState reports what the handler wrote, before the
+// interceptors run.
+type interceptWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	code        int
+	bytes       int
+	passthrough bool
+}
+
+func (w *interceptWriter) Status() int       { return w.code }
+func (w *interceptWriter) BytesWritten() int { return w.bytes }
+
+func (w *interceptWriter) WriteHeader(code int) {
+	if w.passthrough || code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *interceptWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	var n int
+	var err error
+	if w.passthrough {
+		n, err = w.ResponseWriter.Write(b)
+	} else {
+		n, err = w.buf.Write(b)
+	}
+	w.bytes += n
+	return n, err
+}
+
+func (w *interceptWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+		w.ResponseWriter.WriteHeader(w.code)
+		w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *interceptWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
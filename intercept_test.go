@@ -0,0 +1,94 @@
+package owl
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestUseAfter(t *testing.T) {
+	app := New()
+	app.UseAfter(
+		func(c *Ctx, status int, body []byte) (int, []byte) {
+			return status, bytes.ReplaceAll(body, []byte("123-45-6789"), []byte("***-**-****"))
+		},
+		func(c *Ctx, status int, body []byte) (int, []byte) {
+			c.SetHeader("X-Enveloped", "true")
+			envelope := append([]byte(`{"status":`+strconv.Itoa(status)+`,"data":`), bytes.TrimSpace(body)...)
+			return status, append(envelope, '}')
+		},
+	)
+	app.GET("/user", func(c *Ctx) error {
+		return c.JSON(map[string]string{"ssn": "123-45-6789"})
+	})
+	app.GET("/missing", func(c *Ctx) error {
+		return NewHTTPError(http.StatusNotFound, "Not Found")
+	})
+	app.GET("/teapot", func(c *Ctx) error {
+		c.Response.WriteHeader(http.StatusTeapot)
+		return nil
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := get("/user"); w.Code != 200 || w.Body.String() != `{"status":200,"data":{"ssn":"***-**-****"}}` || w.Header().Get("X-Enveloped") != "true" {
+		t.Fatalf("got %d %s, headers %v", w.Code, w.Body.String(), w.Header())
+	}
+	if w := get("/missing"); w.Code != 404 || w.Body.String() != `{"status":404,"data":{"code":404,"message":"Not Found","success":false}}` {
+		t.Fatalf("expected error responses to be intercepted, got %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/teapot"); w.Code != http.StatusTeapot {
+		t.Fatalf("expected the status to be kept, got %d", w.Code)
+	}
+}
+
+func TestUseAfterFlush(t *testing.T) {
+	app := New()
+	app.UseAfter(func(c *Ctx, status int, body []byte) (int, []byte) {
+		return status, []byte("intercepted")
+	})
+	app.GET("/stream", func(c *Ctx) error {
+		c.Response.Write([]byte("chunk 1 "))
+		http.NewResponseController(c.Response).Flush()
+		c.Response.Write([]byte("chunk 2"))
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if w.Body.String() != "chunk 1 chunk 2" || !w.Flushed {
+		t.Fatalf("expected flushed responses to be streamed, got %q", w.Body.String())
+	}
+}
+
+func TestUseAfterResponseState(t *testing.T) {
+	app := New()
+	app.UseAfter(func(c *Ctx, status int, body []byte) (int, []byte) {
+		return status, append(body, '!')
+	})
+	var written bool
+	var status, size int
+	app.GET("/", func(c *Ctx) error {
+		if c.Written() {
+			t.Error("expected nothing to be written yet")
+		}
+		err := c.Text("hi")
+		written, status, size = c.Written(), c.ResponseStatus(), c.BytesWritten()
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if !written || status != 200 || size != 2 {
+		t.Fatalf("expected the buffered response to be reported, got %v %d %d", written, status, size)
+	}
+	if w.Body.String() != "hi!" {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+}
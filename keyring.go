@@ -0,0 +1,210 @@
+package owl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyringOpts configures a Keyring.
+type KeyringOpts struct {
+	// Grace is how long a replaced key is still accepted when verifying,
+	// so that cookies, CSRF tokens and JWTs issued just before a rotation
+	// stay valid (default: 24h).
+	Grace time.Duration
+
+	// MaxKeys bounds the number of keys kept, the current one included
+	// (default: 3).
+	MaxKeys int
+
+	// RotateEvery replaces the current key with a random one at this
+	// interval (default: 0, only Rotate replaces keys). Random keys are
+	// local to the process; Apps running several instances share keys
+	// through App.Keyring instead.
+	RotateEvery time.Duration
+}
+
+// Keyring holds the current key, used for signing, and the previous keys
+// still accepted for verification, so that rotating a key doesn't log out
+// every user or break in-flight tokens. It is safe for concurrent use.
+//
+//	keys := owl.NewKeyring(owl.KeyringOpts{}, current, previous)
+//	c.SetHeader("X-CSRF-Token", keys.Sign(sessionID))
+//	id, ok := keys.Verify(c.Header("X-CSRF-Token"))
+//
+// The JWT middleware accepts a Keyring for HMAC signed tokens.
+type Keyring struct {
+	opts KeyringOpts
+	now  func() time.Time
+
+	mu      sync.RWMutex
+	keys    []keyringKey // Current key first
+	rotated time.Time
+}
+
+type keyringKey struct {
+	key     []byte
+	retired time.Time // Zero for the current key and explicit previous keys
+}
+
+// NewKeyring returns a Keyring signing with current and also verifying
+// with previous, which stay accepted until they are rotated out. With
+// RotateEvery set, current can be nil.
+func NewKeyring(opts KeyringOpts, current []byte, previous ...[]byte) *Keyring {
+	if opts.Grace <= 0 {
+		opts.Grace = 24 * time.Hour
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = 3
+	}
+	k := &Keyring{opts: opts, now: time.Now}
+	if current == nil {
+		if opts.RotateEvery <= 0 {
+			panic("owl: NewKeyring needs a current key")
+		}
+		current = randomKey()
+	}
+	k.keys = append(k.keys, keyringKey{key: current})
+	for _, p := range previous {
+		k.keys = append(k.keys, keyringKey{key: p})
+	}
+	k.rotated = k.now()
+	k.trim()
+	return k
+}
+
+// Rotate makes key the current key. The former current key stays accepted
+// for KeyringOpts.Grace.
+func (k *Keyring) Rotate(key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.rotate(key)
+}
+
+func (k *Keyring) rotate(key []byte) {
+	now := k.now()
+	k.keys[0].retired = now
+	k.keys = append([]keyringKey{{key: key}}, k.keys...)
+	k.rotated = now
+	k.trim()
+}
+
+// trim drops keys past their grace period and beyond MaxKeys.
+func (k *Keyring) trim() {
+	now := k.now()
+	keys := k.keys[:1]
+	for _, key := range k.keys[1:] {
+		if len(keys) < k.opts.MaxKeys && (key.retired.IsZero() || now.Sub(key.retired) < k.opts.Grace) {
+			keys = append(keys, key)
+		}
+	}
+	k.keys = keys
+}
+
+// Current returns the key to sign with.
+func (k *Keyring) Current() []byte {
+	k.maybeRotate()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[0].key
+}
+
+// Keys returns the keys accepted for verification, current key first.
+func (k *Keyring) Keys() [][]byte {
+	k.maybeRotate()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := k.now()
+	keys := make([][]byte, 0, len(k.keys))
+	for _, key := range k.keys {
+		if key.retired.IsZero() || now.Sub(key.retired) < k.opts.Grace {
+			keys = append(keys, key.key)
+		}
+	}
+	return keys
+}
+
+// maybeRotate applies KeyringOpts.RotateEvery.
+func (k *Keyring) maybeRotate() {
+	if k.opts.RotateEvery <= 0 {
+		return
+	}
+	k.mu.RLock()
+	due := k.now().Sub(k.rotated) >= k.opts.RotateEvery
+	k.mu.RUnlock()
+	if !due {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.now().Sub(k.rotated) >= k.opts.RotateEvery {
+		k.rotate(randomKey())
+	}
+}
+
+// Sign returns value followed by a "." and its HMAC-SHA256 signature with
+// the current key, e.g. for signed cookies or CSRF tokens.
+func (k *Keyring) Sign(value string) string {
+	return value + "." + base64.RawURLEncoding.EncodeToString(keyringMAC(k.Current(), value))
+}
+
+// Verify checks a value returned by Sign against every accepted key and
+// returns the original value.
+func (k *Keyring) Verify(signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+	value := signed[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", false
+	}
+	for _, key := range k.Keys() {
+		if hmac.Equal(sig, keyringMAC(key, value)) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func keyringMAC(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func randomKey() []byte {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("owl: reading random key: " + err.Error())
+	}
+	return b
+}
+
+// Keyring returns a Keyring whose current key is the secret name, read
+// with App.Secret. When RefreshSecrets finds a new value, it becomes the
+// current key and the former one stays accepted for opts.Grace, so every
+// instance of the App picks up a rotation made in the secret manager
+// without restarting:
+//
+//	app := owl.New(owl.AppConfig{Secrets: owl.FileSecrets("/run/secrets"), SecretRefresh: time.Minute})
+//	keys, err := app.Keyring(ctx, "jwt-key", owl.KeyringOpts{Grace: time.Hour})
+//	app.Use(middleware.JWT(middleware.JWTConfig{Keyring: keys}))
+//
+// opts.RotateEvery is ignored; the secret manager sets the schedule.
+func (a *App) Keyring(ctx context.Context, name string, opts KeyringOpts) (*Keyring, error) {
+	key, err := a.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	opts.RotateEvery = 0
+	k := NewKeyring(opts, key)
+	a.OnSecretRotate(name, k.Rotate)
+	return k, nil
+}
@@ -0,0 +1,95 @@
+package owl
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	now := time.Now()
+	k := NewKeyring(KeyringOpts{Grace: time.Hour}, []byte("k1"))
+	k.now = func() time.Time { return now }
+
+	old := k.Sign("session-42")
+	if v, ok := k.Verify(old); !ok || v != "session-42" {
+		t.Fatalf("got %q, %v", v, ok)
+	}
+	if _, ok := k.Verify("session-42.AAAA"); ok {
+		t.Fatal("forged signature accepted")
+	}
+
+	k.Rotate([]byte("k2"))
+	fresh := k.Sign("session-42")
+	if fresh == old {
+		t.Fatal("expected signing with the new key")
+	}
+	if _, ok := k.Verify(old); !ok {
+		t.Fatal("value signed with the previous key should verify during the grace period")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := k.Verify(old); ok {
+		t.Fatal("value signed with the previous key should expire after the grace period")
+	}
+	if _, ok := k.Verify(fresh); !ok {
+		t.Fatal("value signed with the current key should verify")
+	}
+}
+
+func TestKeyringPreviousAndMaxKeys(t *testing.T) {
+	signed := NewKeyring(KeyringOpts{}, []byte("old")).Sign("v")
+
+	k := NewKeyring(KeyringOpts{MaxKeys: 2}, []byte("new"), []byte("old"))
+	if _, ok := k.Verify(signed); !ok {
+		t.Fatal("explicit previous keys should verify")
+	}
+	k.Rotate([]byte("newer"))
+	if len(k.Keys()) != 2 {
+		t.Fatalf("expected MaxKeys to bound the keyring, got %d keys", len(k.Keys()))
+	}
+	if _, ok := k.Verify(signed); ok {
+		t.Fatal("keys beyond MaxKeys should be dropped")
+	}
+}
+
+func TestKeyringRotateEvery(t *testing.T) {
+	k := NewKeyring(KeyringOpts{RotateEvery: time.Hour, Grace: 30 * time.Minute}, nil)
+	now := k.rotated
+	k.now = func() time.Time { return now }
+
+	first := k.Current()
+	signed := k.Sign("v")
+	now = now.Add(time.Hour)
+	if string(k.Current()) == string(first) {
+		t.Fatal("expected a scheduled rotation")
+	}
+	if _, ok := k.Verify(signed); !ok {
+		t.Fatal("value signed before the rotation should verify during the grace period")
+	}
+}
+
+func TestAppKeyring(t *testing.T) {
+	current := "k1"
+	app := New(AppConfig{Secrets: SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		return []byte(current), nil
+	})})
+	ctx := context.Background()
+
+	k, err := app.Keyring(ctx, "cookie-key", KeyringOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := k.Sign("v")
+
+	current = "k2"
+	if err := app.RefreshSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if string(k.Current()) != "k2" {
+		t.Fatalf("expected the refreshed secret to become current, got %q", k.Current())
+	}
+	if _, ok := k.Verify(signed); !ok {
+		t.Fatal("value signed with the previous secret should verify")
+	}
+}
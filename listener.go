@@ -0,0 +1,120 @@
+package owl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Serve builds the App and serves it on ln (blocking), e.g. a unix socket
+// from ListenUnix or a systemd activated socket from SystemdListeners, for
+// setups that can't pass a TCP address. Like http.Server.Serve, it closes
+// ln when it returns. Custom Transports open their own listeners and don't
+// support it. Use Supervisor.AddListener for a graceful shutdown on
+// signals.
+func (a *App) Serve(ln net.Listener) error {
+	srv, err := a.serving(ln, "Serve")
+	if err != nil {
+		return err
+	}
+	return srv.Serve(ln)
+}
+
+// ServeTLS is Serve over HTTPS, see StartTLS.
+func (a *App) ServeTLS(ln net.Listener, certFile, keyFile string) error {
+	srv, err := a.serving(ln, "ServeTLS")
+	if err != nil {
+		return err
+	}
+	return srv.ServeTLS(ln, certFile, keyFile)
+}
+
+// serving builds the App and returns the server for ln, which is closed
+// on failure.
+func (a *App) serving(ln net.Listener, what string) (*http.Server, error) {
+	if a.transport != nil {
+		ln.Close()
+		return nil, errors.New("owl: " + what + " isn't supported by custom Transports")
+	}
+	if err := a.Build(); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	a.refreshSecrets()
+	addr := ln.Addr().String()
+	srv := a.Listen(addr)
+	a.started(addr)
+	return srv, nil
+}
+
+// listenTCP opens the listener of Start, with the default address of
+// net/http.
+func listenTCP(addr string) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
+	}
+	return net.Listen("tcp", addr)
+}
+
+// ListenUnix listens on the unix domain socket path, removing a stale
+// socket file left by a previous run, and sets its permissions to mode,
+// e.g. 0660 so that a proxy of the same group can connect.
+func ListenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("owl: unix socket '%s' is in use", path)
+		}
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// systemdFirstFD is the first file descriptor passed by systemd, see
+// sd_listen_fds(3).
+const systemdFirstFD = 3
+
+// SystemdListeners returns the sockets passed by systemd socket activation,
+// in the order of the ListenStream lines of the socket unit, or nil when
+// the process wasn't socket activated. The LISTEN_* variables are unset so
+// that child processes don't inherit them.
+func SystemdListeners() ([]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := systemdFirstFD; fd < systemdFirstFD+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close() // FileListener holds a duplicate
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("owl: systemd socket %d: %w", fd, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,81 @@
+package owl
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owl.sock")
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("unix") })
+
+	ln, err := ListenUnix(path, 0660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0660 {
+		t.Fatalf("expected the socket mode to be set, got %v %v", fi, err)
+	}
+	if _, err := ListenUnix(path, 0660); err == nil {
+		t.Fatal("expected a socket in use to be refused")
+	}
+
+	sup := NewSupervisor(SupervisorConfig{ShutdownTimeout: time.Second})
+	sup.AddListener(ln, app)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if resp, err = client.Get("http://owl/"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "unix" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("expected a clean stop, got %v", err)
+	}
+
+	// A socket file left by a crashed server is replaced.
+	ln, _ = ListenUnix(path, 0600)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	if ln, err = ListenUnix(path, 0600); err != nil {
+		t.Fatalf("expected a stale socket to be replaced, got %v", err)
+	}
+	ln.Close()
+}
+
+func TestSystemdListeners(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err := SystemdListeners()
+	if err != nil || listeners != nil {
+		t.Fatalf("expected no listeners for another process, got %v %v", listeners, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Fatal("expected the LISTEN_* variables to be unset")
+	}
+}
@@ -0,0 +1,94 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// loggerCtxKey is the context.Context key to store the *slog.Logger of the
+// App serving a request, see LoggerFromContext.
+var loggerCtxKey = &contextKey{"Logger"}
+
+// LoggerFromContext returns the structured logger set with
+// AppConfig.Logger by the App serving the request, or nil. Middlewares
+// such as middleware.Logger use it to log in the format of the App.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	l, _ := ctx.Value(loggerCtxKey).(*slog.Logger)
+	return l
+}
+
+// Logger returns the structured logger of the App: AppConfig.Logger, or
+// slog.Default when unset.
+func (a *App) Logger() *slog.Logger {
+	if a.logger != nil {
+		return a.logger
+	}
+	return slog.Default()
+}
+
+// started announces that the App serves addr and runs the OnListen hooks.
+func (a *App) started(addr string) {
+	switch {
+	case a.noBanner:
+	case a.logger != nil:
+		a.logger.Info("server starting", "name", a.name, "version", a.version, "addr", addr)
+	case a.useColor():
+		log.Printf("\033[92m%s\033[0m v%s server starting on \033[102;30m%s\033[0m", a.name, a.version, addr)
+	default:
+		log.Printf("%s v%s server starting on %s", a.name, a.version, addr)
+	}
+	if a.devMode {
+		a.logRoutes()
+	}
+	a.runListen(addr)
+}
+
+// logRoutes logs the route table with the middleware chains, see
+// AppConfig.DevMode.
+func (a *App) logRoutes() {
+	var buf strings.Builder
+	a.DumpMiddlewares(&buf)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		if a.logger != nil {
+			a.logger.Info("route", "route", line)
+		} else {
+			log.Print("owl: route " + line)
+		}
+	}
+}
+
+// useColor reports whether the startup message of the standard logger is
+// colored, see AppConfig.NoColor.
+func (a *App) useColor() bool {
+	if a.noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := log.Writer().(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// logf logs a message of the framework with the structured logger of the
+// App when set, or the standard logger, with args as key/value pairs.
+func (a *App) logf(level LogLevel, msg string, args ...interface{}) {
+	if a.logger != nil {
+		a.logger.Log(context.Background(), slog.Level(level), msg, args...)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("owl: " + msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	log.Print(b.String())
+}
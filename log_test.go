@@ -0,0 +1,87 @@
+package owl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	app := New(AppConfig{Logger: logger})
+	app.GET("/logf", func(c *Ctx) error {
+		if LoggerFromContext(c.Request.Context()) != logger {
+			t.Error("expected the App logger in the request context")
+		}
+		c.Logf(LevelWarn, "slow %s", "query")
+		return nil
+	})
+	app.GET("/fail", func(c *Ctx) error {
+		return errors.New("db down")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/logf", nil))
+	if out := buf.String(); !strings.Contains(out, `level=WARN msg="slow query" method=GET path=/logf`) {
+		t.Fatalf("unexpected Logf record %q", out)
+	}
+
+	buf.Reset()
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	if out := buf.String(); w.Code != 500 || !strings.Contains(out, `level=ERROR msg="handler error" method=GET path=/fail status=500 error="db down"`) {
+		t.Fatalf("unexpected error record %q", out)
+	}
+
+	if New().Logger() != slog.Default() || app.Logger() != logger {
+		t.Fatal("expected Logger to fall back to slog.Default")
+	}
+}
+
+func TestStartupBanner(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(w io.Writer) { log.SetOutput(w) }(log.Writer())
+	log.SetOutput(&buf)
+
+	New(AppConfig{Name: "api", Version: "1.2.0"}).started(":8080")
+	if out := buf.String(); !strings.HasSuffix(out, "api v1.2.0 server starting on :8080\n") {
+		t.Fatalf("expected a plain message when not writing to a terminal, got %q", out)
+	}
+
+	buf.Reset()
+	var listened bool
+	app := New(AppConfig{DisableBanner: true})
+	app.Hooks().OnListen(func(string) { listened = true })
+	app.started(":8080")
+	if buf.Len() != 0 || !listened {
+		t.Fatalf("expected no banner but the hooks, got %q", buf.String())
+	}
+}
+
+func TestDevMode(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(w io.Writer) { log.SetOutput(w) }(log.Writer())
+	log.SetOutput(&buf)
+
+	app := New(AppConfig{DevMode: true})
+	app.GET("/users", func(c *Ctx) error { return c.JSON(map[string]int{"count": 1}) })
+	app.started(":8080")
+	if !app.DevMode() || !strings.Contains(buf.String(), "owl: route GET /users:") {
+		t.Fatalf("expected the route table to be logged, got %q", buf.String())
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	if w.Body.String() != "{\n  \"count\": 1\n}\n" {
+		t.Fatalf("expected indented JSON, got %q", w.Body.String())
+	}
+
+	t.Setenv("OWL_ENV", "production")
+	if New(AppConfig{DevMode: true}).DevMode() {
+		t.Fatal("expected DevMode to be ignored in production")
+	}
+}
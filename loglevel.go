@@ -0,0 +1,222 @@
+package owl
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LogLevel is the verbosity of logs. Its values match those of log/slog.
+type LogLevel int32
+
+// Log levels.
+const (
+	LevelDebug LogLevel = -4
+	LevelInfo  LogLevel = 0
+	LevelWarn  LogLevel = 4
+	LevelError LogLevel = 8
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+// ParseLogLevel parses "debug", "info", "warn" or "error", ignoring case.
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("owl: unknown log level '%s'", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *LogLevel) UnmarshalText(b []byte) error {
+	level, err := ParseLogLevel(string(b))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// logLevels holds the App-wide log level and the per-route overrides.
+type logLevels struct {
+	level atomic.Int32
+
+	mu     sync.RWMutex
+	routes map[string]LogLevel // By route pattern
+}
+
+// SetLogLevel sets the log level of the App, which applies to Ctx.Logf and
+// to the access logs of the Logger middleware: requests answered with a
+// status below 400 are logged at LevelInfo, client errors at LevelWarn and
+// server errors at LevelError (default: LevelInfo). It is safe to call
+// while serving, see LogLevelHandler.
+func (a *App) SetLogLevel(level LogLevel) {
+	a.logLevels.level.Store(int32(level))
+}
+
+// LogLevel returns the log level of the App.
+func (a *App) LogLevel() LogLevel {
+	return LogLevel(a.logLevels.level.Load())
+}
+
+// SetRouteLogLevel overrides the log level for the route registered with
+// pattern, e.g. "/users/{id}", to debug it without flooding the logs of
+// the whole App. It is safe to call while serving.
+func (a *App) SetRouteLogLevel(pattern string, level LogLevel) {
+	a.logLevels.mu.Lock()
+	defer a.logLevels.mu.Unlock()
+	if a.logLevels.routes == nil {
+		a.logLevels.routes = map[string]LogLevel{}
+	}
+	a.logLevels.routes[pattern] = level
+}
+
+// ResetRouteLogLevel removes the override set with SetRouteLogLevel.
+func (a *App) ResetRouteLogLevel(pattern string) {
+	a.logLevels.mu.Lock()
+	defer a.logLevels.mu.Unlock()
+	delete(a.logLevels.routes, pattern)
+}
+
+// routeLogLevel returns the log level for the route matched by rctx.
+func (a *App) routeLogLevel(rctx *Context) LogLevel {
+	a.logLevels.mu.RLock()
+	defer a.logLevels.mu.RUnlock()
+	if len(a.logLevels.routes) > 0 {
+		if level, ok := a.logLevels.routes[rctx.RoutePattern()]; ok {
+			return level
+		}
+	}
+	return a.LogLevel()
+}
+
+// LogLevelHandler returns an admin endpoint reading and changing log
+// levels at runtime. GET answers the App level and the route overrides;
+// PUT or POST with a JSON body changes them:
+//
+//	{"level": "debug"}                          // App level
+//	{"route": "/users/{id}", "level": "debug"}  // route override
+//	{"route": "/users/{id}", "level": ""}       // remove the override
+//
+// It must be registered behind authentication:
+//
+//	admin := app.Group("/admin", requireAdmin)
+//	admin.Match([]string{"GET", "PUT"}, "/log-level", app.LogLevelHandler())
+func (a *App) LogLevelHandler() Handler {
+	return func(c *Ctx) error {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var req struct {
+				Route string `json:"route"`
+				Level string `json:"level"`
+			}
+			if err := c.Bind().JSON(&req); err != nil {
+				return err
+			}
+			if req.Route != "" && req.Level == "" {
+				a.ResetRouteLogLevel(req.Route)
+				break
+			}
+			level, err := ParseLogLevel(req.Level)
+			if err != nil {
+				return NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			if req.Route != "" {
+				a.SetRouteLogLevel(req.Route, level)
+			} else {
+				a.SetLogLevel(level)
+			}
+			a.logf(LevelInfo, "log level changed", "levels", a.logLevelSummary())
+		default:
+			c.SetHeader("Allow", "GET, PUT, POST")
+			return NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+
+		a.logLevels.mu.RLock()
+		routes := make(map[string]LogLevel, len(a.logLevels.routes))
+		for pattern, level := range a.logLevels.routes {
+			routes[pattern] = level
+		}
+		a.logLevels.mu.RUnlock()
+		return c.JSON(map[string]interface{}{"level": a.LogLevel(), "routes": routes})
+	}
+}
+
+// logLevelSummary describes the log levels for the admin log line.
+func (a *App) logLevelSummary() string {
+	a.logLevels.mu.RLock()
+	defer a.logLevels.mu.RUnlock()
+	parts := []string{"app=" + a.LogLevel().String()}
+	for pattern, level := range a.logLevels.routes {
+		parts = append(parts, pattern+"="+level.String())
+	}
+	sort.Strings(parts[1:])
+	return strings.Join(parts, " ")
+}
+
+// LogEnabled reports whether messages at level are logged for the matched
+// route.
+func (c *Ctx) LogEnabled(level LogLevel) bool {
+	return level >= c.logLevel()
+}
+
+// Logf logs a message at level when it is enabled for the matched route,
+// see App.SetLogLevel and App.SetRouteLogLevel.
+func (c *Ctx) Logf(level LogLevel, format string, args ...interface{}) {
+	if !c.LogEnabled(level) {
+		return
+	}
+	if c.app != nil && c.app.logger != nil {
+		c.app.logger.Log(c.Request.Context(), slog.Level(level), fmt.Sprintf(format, args...),
+			"method", c.Request.Method, "path", c.Request.URL.Path)
+		return
+	}
+	log.Printf("[%s] %s", strings.ToUpper(level.String()), fmt.Sprintf(format, args...))
+}
+
+func (c *Ctx) logLevel() LogLevel {
+	if rctx := c.routeContext(); rctx != nil && rctx.hasLogLevel {
+		return rctx.logLevel
+	}
+	if c.app != nil {
+		return c.app.LogLevel()
+	}
+	return LevelInfo
+}
+
+// LogLevel returns the log level of the matched Owl route, see
+// App.SetLogLevel. Like RouteAttrs, it is only set once routing is done,
+// and ok is false for requests that weren't served by an Owl route.
+func (x *Context) LogLevel() (level LogLevel, ok bool) {
+	return x.logLevel, x.hasLogLevel
+}
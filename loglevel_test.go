@@ -0,0 +1,67 @@
+package owl
+
+import (
+	"bytes"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	log.SetOutput(&bytes.Buffer{})
+	defer log.SetOutput(os.Stderr)
+
+	app := New()
+	app.Match([]string{"GET", "PUT"}, "/admin/log-level", app.LogLevelHandler())
+
+	send := func(method, body string) (int, string) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, "/admin/log-level", strings.NewReader(body)))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	if code, body := send("GET", ""); code != 200 || body != `{"level":"info","routes":{}}` {
+		t.Fatalf("GET: got %d %s", code, body)
+	}
+	if code, _ := send("PUT", `{"level":"debug"}`); code != 200 || app.LogLevel() != LevelDebug {
+		t.Fatalf("PUT level: got %d, level %s", code, app.LogLevel())
+	}
+	if code, body := send("PUT", `{"route":"/users/{id}","level":"error"}`); code != 200 || body != `{"level":"debug","routes":{"/users/{id}":"error"}}` {
+		t.Fatalf("PUT route: got %d %s", code, body)
+	}
+	if code, body := send("PUT", `{"route":"/users/{id}"}`); code != 200 || body != `{"level":"debug","routes":{}}` {
+		t.Fatalf("reset route: got %d %s", code, body)
+	}
+	if code, _ := send("PUT", `{"level":"loud"}`); code != 400 {
+		t.Fatalf("invalid level: got %d", code)
+	}
+}
+
+func TestCtxLogf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	app := New()
+	app.GET("/users/{id}", func(c *Ctx) error {
+		c.Logf(LevelDebug, "loading user %s", c.Param("id"))
+		return c.Text("ok")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("debug message logged at info level: %q", buf.String())
+	}
+
+	app.SetRouteLogLevel("/users/{id}", LevelDebug)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/2", nil))
+	if buf.String() != "[DEBUG] loading user 2\n" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
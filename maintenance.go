@@ -0,0 +1,204 @@
+package owl
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaintenanceConfig configures the responses of maintenance mode.
+type MaintenanceConfig struct {
+	// Message is the message of the JSON body (default: "Service under
+	// maintenance").
+	Message string
+
+	// HTML, when set, is answered to clients preferring text/html, such as
+	// browsers, instead of the JSON body.
+	HTML string
+
+	// RetryAfter is sent as the Retry-After header (default: 5m).
+	RetryAfter time.Duration
+
+	// ExemptPaths are path prefixes still served, e.g. "/health" for load
+	// balancer probes or "/admin" to turn maintenance off.
+	ExemptPaths []string
+
+	// ExemptIPs are client IPs or CIDR ranges still served, e.g. those of
+	// the team checking the deployment.
+	ExemptIPs []string
+
+	// TrustProxy reads the client IP from X-Forwarded-For and X-Real-IP,
+	// see ClientIP. Only set it behind a proxy overwriting them.
+	TrustProxy bool
+}
+
+// Maintenance switches an App in and out of maintenance mode at runtime,
+// without redeploying: while enabled, requests are answered with 503
+// Service Unavailable and a Retry-After header, except for exempt paths and
+// clients. It is safe for concurrent use.
+//
+//	app.Maintenance().Enable(owl.MaintenanceConfig{
+//		RetryAfter:  10 * time.Minute,
+//		ExemptPaths: []string{"/health", "/admin"},
+//	})
+//	defer app.Maintenance().Disable()
+type Maintenance struct {
+	enabled atomic.Bool
+
+	mu     sync.RWMutex
+	config MaintenanceConfig
+	nets   []*net.IPNet
+}
+
+// Maintenance returns the maintenance mode controls of the App. The App
+// applies them to every request, before routing.
+func (a *App) Maintenance() *Maintenance {
+	a.maintenanceOnce.Do(func() {
+		a.maintenance.Store(&Maintenance{})
+	})
+	return a.maintenance.Load()
+}
+
+// Enable turns maintenance mode on with config.
+func (m *Maintenance) Enable(config MaintenanceConfig) {
+	if config.Message == "" {
+		config.Message = "Service under maintenance"
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = 5 * time.Minute
+	}
+	var nets []*net.IPNet
+	for _, s := range config.ExemptIPs {
+		if !strings.Contains(s, "/") {
+			if strings.Contains(s, ":") {
+				s += "/128"
+			} else {
+				s += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			panic("owl: invalid maintenance exempt IP '" + s + "'")
+		}
+		nets = append(nets, n)
+	}
+
+	m.mu.Lock()
+	m.config = config
+	m.nets = nets
+	m.mu.Unlock()
+	m.enabled.Store(true)
+}
+
+// Disable turns maintenance mode off.
+func (m *Maintenance) Disable() {
+	m.enabled.Store(false)
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Maintenance) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Middleware answers requests with 503 while maintenance mode is on. Apps
+// apply it on their own; it is meant for other routers sharing the
+// controls of an App.
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.serve(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serve answers r with 503 when maintenance mode is on and r isn't exempt,
+// and reports whether it did.
+func (m *Maintenance) serve(w http.ResponseWriter, r *http.Request) bool {
+	if !m.enabled.Load() {
+		return false
+	}
+	m.mu.RLock()
+	config, nets := m.config, m.nets
+	m.mu.RUnlock()
+
+	for _, prefix := range config.ExemptPaths {
+		if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, strings.TrimSuffix(prefix, "/")+"/") {
+			return false
+		}
+	}
+	if len(nets) > 0 {
+		if ip := net.ParseIP(ClientIP(r, config.TrustProxy)); ip != nil {
+			for _, n := range nets {
+				if n.Contains(ip) {
+					return false
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(config.RetryAfter.Seconds())))
+	w.Header().Set("Cache-Control", "no-store")
+	if config.HTML != "" && negotiate(r.Header.Get("Accept"), "application/json", "text/html") == "text/html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(config.HTML))
+		return true
+	}
+	_ = JSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"success": false,
+		"code":    http.StatusServiceUnavailable,
+		"message": config.Message,
+	})
+	return true
+}
+
+// MaintenanceHandler returns an admin endpoint reading and toggling
+// maintenance mode at runtime. GET answers whether it is enabled; PUT or
+// POST with a JSON body changes it:
+//
+//	{"enabled": true, "message": "Back at 14:00", "retry_after": 1800}
+//
+// Other settings, such as exemptions, are those of the last Enable. It
+// must be registered behind authentication, and its path must be exempt:
+//
+//	admin := app.Group("/admin", requireAdmin)
+//	admin.Match([]string{"GET", "PUT"}, "/maintenance", app.MaintenanceHandler())
+func (a *App) MaintenanceHandler() Handler {
+	m := a.Maintenance()
+	return func(c *Ctx) error {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var req struct {
+				Enabled    bool   `json:"enabled"`
+				Message    string `json:"message"`
+				RetryAfter int    `json:"retry_after"` // Seconds
+			}
+			if err := c.Bind().JSON(&req); err != nil {
+				return err
+			}
+			if !req.Enabled {
+				m.Disable()
+				break
+			}
+			m.mu.RLock()
+			config := m.config
+			m.mu.RUnlock()
+			if req.Message != "" {
+				config.Message = req.Message
+			}
+			if req.RetryAfter > 0 {
+				config.RetryAfter = time.Duration(req.RetryAfter) * time.Second
+			}
+			m.Enable(config)
+		default:
+			c.SetHeader("Allow", "GET, PUT, POST")
+			return NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+		return c.JSON(map[string]interface{}{"enabled": m.Enabled()})
+	}
+}
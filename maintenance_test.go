@@ -0,0 +1,59 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	app := New()
+	ok := func(c *Ctx) error { return c.Text("ok") }
+	app.GET("/", ok)
+	app.GET("/health", ok)
+	app.Match([]string{"GET", "PUT"}, "/admin/maintenance", app.MaintenanceHandler())
+
+	do := func(method, path, remote, accept, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.RemoteAddr = remote
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do("GET", "/", "192.0.2.1:1234", "", ""); w.Code != 200 {
+		t.Fatalf("expected requests to be served by default, got %d", w.Code)
+	}
+
+	app.Maintenance().Enable(MaintenanceConfig{
+		RetryAfter:  10 * time.Minute,
+		HTML:        "<h1>Back soon</h1>",
+		ExemptPaths: []string{"/health", "/admin"},
+		ExemptIPs:   []string{"10.0.0.0/8", "192.0.2.7"},
+	})
+	w := do("GET", "/", "192.0.2.1:1234", "", "")
+	if w.Code != 503 || w.Header().Get("Retry-After") != "600" || !strings.Contains(w.Body.String(), "Service under maintenance") {
+		t.Fatalf("expected a 503 JSON answer, got %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+	if w := do("GET", "/", "192.0.2.1:1234", "text/html,*/*;q=0.8", ""); w.Body.String() != "<h1>Back soon</h1>" {
+		t.Fatalf("expected the HTML page for browsers, got %s", w.Body.String())
+	}
+	for _, tt := range []struct{ path, remote string }{
+		{"/health", "192.0.2.1:1234"},
+		{"/", "10.1.2.3:1234"},
+		{"/", "192.0.2.7:1234"},
+	} {
+		if w := do("GET", tt.path, tt.remote, "", ""); w.Code != 200 {
+			t.Errorf("expected %s from %s to be exempt, got %d", tt.path, tt.remote, w.Code)
+		}
+	}
+
+	if w := do("PUT", "/admin/maintenance", "192.0.2.1:1234", "", `{"enabled": false}`); w.Code != 200 || app.Maintenance().Enabled() {
+		t.Fatalf("expected the admin endpoint to disable maintenance, got %d %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/", "192.0.2.1:1234", "", ""); w.Code != 200 {
+		t.Fatalf("expected requests to be served again, got %d", w.Code)
+	}
+}
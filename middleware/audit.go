@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// AuditEvent records who did what on which resource, and how it went.
+type AuditEvent struct {
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor,omitempty"`
+	Action    string            `json:"action"` // Method and route, e.g. "DELETE /users/{id}"
+	Method    string            `json:"method"`
+	Route     string            `json:"route"`
+	Path      string            `json:"path"`
+	Resource  map[string]string `json:"resource,omitempty"` // URL params
+	Status    int               `json:"status"`
+	Outcome   string            `json:"outcome"` // "success", "denied" or "failure"
+	IP        string            `json:"ip"`
+	RequestID string            `json:"request_id,omitempty"`
+	Duration  time.Duration     `json:"duration"`
+}
+
+// AuditSink delivers audit events, e.g. to a file, a SIEM or a message
+// broker. Events are delivered on the request goroutine once the response
+// is written, so slow sinks should buffer.
+type AuditSink interface {
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(ctx context.Context, event AuditEvent) error
+
+// Audit implements AuditSink.
+func (f AuditSinkFunc) Audit(ctx context.Context, event AuditEvent) error {
+	return f(ctx, event)
+}
+
+// WriterAuditSink returns an AuditSink writing events as JSON lines to w,
+// such as os.Stdout or an append-only file.
+func WriterAuditSink(w io.Writer) AuditSink {
+	var mu sync.Mutex
+	return AuditSinkFunc(func(ctx context.Context, event AuditEvent) error {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = w.Write(append(b, '\n'))
+		return err
+	})
+}
+
+// AuditPublisher publishes messages to a broker such as Kafka, e.g. a thin
+// wrapper around the producer of a client library.
+type AuditPublisher interface {
+	Publish(ctx context.Context, key, value []byte) error
+}
+
+// PublisherAuditSink returns an AuditSink publishing events as JSON to p,
+// keyed by actor so that the events of an actor stay ordered.
+func PublisherAuditSink(p AuditPublisher) AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, event AuditEvent) error {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return p.Publish(ctx, []byte(event.Actor), b)
+	})
+}
+
+// AuditConfig defines the configuration of the Audit middleware.
+type AuditConfig struct {
+	// Sink receives the events. Required.
+	Sink AuditSink
+
+	// Methods are the audited methods (default: POST, PUT, PATCH and
+	// DELETE).
+	Methods []string
+
+	// ActorFunc identifies who made the request (default: the "sub" claim
+	// of the claims set by JWT, or the "user_id" value of the session).
+	ActorFunc func(r *http.Request) string
+
+	// ErrorHandler is called when the sink fails (default: logs with the
+	// standard logger).
+	ErrorHandler func(r *http.Request, event AuditEvent, err error)
+}
+
+// Audit is a middleware producing an audit event for every request with a
+// mutating method, for compliance trails of who changed what:
+//
+//	f, _ := os.OpenFile("audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+//	r.Use(middleware.Audit(middleware.AuditConfig{Sink: middleware.WriterAuditSink(f)}))
+//
+// It must run after the authentication middlewares setting the actor, and
+// RealIP when behind a trusted proxy.
+func Audit(config AuditConfig) func(http.Handler) http.Handler {
+	if config.Sink == nil {
+		panic("chi/middleware: Audit expects a Sink")
+	}
+	if config.Methods == nil {
+		config.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if config.ActorFunc == nil {
+		config.ActorFunc = defaultAuditActor
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(r *http.Request, event AuditEvent, err error) {
+			log.Printf("chi/middleware: audit %s by %q: %v", event.Action, event.Actor, err)
+		}
+	}
+	methods := make(map[string]bool, len(config.Methods))
+	for _, m := range config.Methods {
+		methods[strings.ToUpper(m)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !methods[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ww, state := trackResponse(w, r)
+			start := time.Now()
+			defer func() {
+				status := state.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				if rec := recover(); rec != nil {
+					status = http.StatusInternalServerError
+					defer panic(rec)
+				}
+				event := newAuditEvent(r, status, start)
+				event.Actor = config.ActorFunc(r)
+				if err := config.Sink.Audit(context.WithoutCancel(r.Context()), event); err != nil {
+					config.ErrorHandler(r, event, err)
+				}
+			}()
+			next.ServeHTTP(ww, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func newAuditEvent(r *http.Request, status int, start time.Time) AuditEvent {
+	event := AuditEvent{
+		Time:      start.UTC(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    status,
+		Outcome:   "success",
+		IP:        remoteIP(r),
+		RequestID: GetReqID(r.Context()),
+		Duration:  time.Since(start),
+	}
+	if rctx := owl.RouteContext(r.Context()); rctx != nil {
+		event.Route = rctx.RoutePattern()
+		for i, key := range rctx.URLParams.Keys {
+			if key == "*" {
+				continue
+			}
+			if event.Resource == nil {
+				event.Resource = map[string]string{}
+			}
+			event.Resource[key] = rctx.URLParams.Values[i]
+		}
+	}
+	if event.Route == "" {
+		event.Route = event.Path
+	}
+	event.Action = event.Method + " " + event.Route
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		event.Outcome = "denied"
+	case status >= 400:
+		event.Outcome = "failure"
+	}
+	return event
+}
+
+// defaultAuditActor returns the "sub" claim of map claims, or the
+// "user_id" value of the session.
+func defaultAuditActor(r *http.Request) string {
+	switch claims := r.Context().Value(owl.ClaimsCtxKey).(type) {
+	case *map[string]interface{}:
+		if sub, ok := (*claims)["sub"].(string); ok {
+			return sub
+		}
+	case map[string]interface{}:
+		if sub, ok := claims["sub"].(string); ok {
+			return sub
+		}
+	}
+	if s, ok := r.Context().Value(owl.SessionCtxKey).(*owl.Session); ok && s != nil {
+		return s.GetString("user_id")
+	}
+	return ""
+}
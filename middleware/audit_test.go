@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func TestAudit(t *testing.T) {
+	var events []AuditEvent
+	sink := AuditSinkFunc(func(ctx context.Context, event AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	withClaims := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := &map[string]interface{}{"sub": "alice"}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), owl.ClaimsCtxKey, claims)))
+		})
+	}
+
+	app := owl.New()
+	app.Use(withClaims)
+	app.Use(Audit(AuditConfig{Sink: sink}))
+	app.GET("/users/{id}", func(c *owl.Ctx) error { return nil })
+	app.DELETE("/users/{id}", func(c *owl.Ctx) error { return nil })
+	app.PUT("/users/{id}", func(c *owl.Ctx) error {
+		return owl.NewHTTPError(http.StatusForbidden, "Forbidden")
+	})
+
+	for _, method := range []string{"GET", "DELETE", "PUT"} {
+		r := httptest.NewRequest(method, "/users/42", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		app.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected mutating requests to be audited, got %d events", len(events))
+	}
+	e := events[0]
+	if e.Actor != "alice" || e.Action != "DELETE /users/{id}" || e.Resource["id"] != "42" ||
+		e.Status != 200 || e.Outcome != "success" || e.IP != "10.0.0.1" || e.Time.IsZero() {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if e := events[1]; e.Status != http.StatusForbidden || e.Outcome != "denied" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestAuditSinks(t *testing.T) {
+	var buf bytes.Buffer
+	var sinkErr error
+	h := Audit(AuditConfig{
+		Sink:         WriterAuditSink(&buf),
+		ErrorHandler: func(r *http.Request, event AuditEvent, err error) { sinkErr = err },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+
+	var event AuditEvent
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil || event.Action != "POST /orders" || event.Status != 201 {
+		t.Fatalf("expected a JSON line, got %q: %v", buf.String(), err)
+	}
+
+	failing := AuditSinkFunc(func(ctx context.Context, event AuditEvent) error { return errors.New("broker down") })
+	h = Audit(AuditConfig{
+		Sink:         failing,
+		ErrorHandler: func(r *http.Request, event AuditEvent, err error) { sinkErr = err },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PATCH", "/orders/1", nil))
+	if sinkErr == nil || sinkErr.Error() != "broker down" {
+		t.Fatalf("expected the sink error to be handled, got %v", sinkErr)
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultBodyDumpRedact are the fields masked by BodyDump.
+var DefaultBodyDumpRedact = []string{"password", "card_number", "cvv", "secret", "token", "api_key"}
+
+// BodyDumpOpts configures the BodyDumpWithOpts middleware.
+type BodyDumpOpts struct {
+	// Handler receives the bodies of each request and response once the
+	// response is complete. Required.
+	Handler func(r *http.Request, reqBody, resBody []byte)
+
+	// MaxSize caps the bytes captured of each body (default: 64KB).
+	// Larger bodies are truncated, and still served in full.
+	MaxSize int
+
+	// Redact are the names of the fields masked in JSON and form bodies,
+	// matched case-insensitively at any depth (default:
+	// DefaultBodyDumpRedact).
+	Redact []string
+}
+
+// BodyDump is a middleware passing copies of the request and response
+// bodies to handler, e.g. to debug a client integration or to feed an
+// audit pipeline. The values of sensitive fields, see
+// DefaultBodyDumpRedact, are replaced with "[REDACTED]":
+//
+//	r.With(middleware.BodyDump(func(r *http.Request, reqBody, resBody []byte) {
+//		log.Printf("%s %s\n> %s\n< %s", r.Method, r.URL.Path, reqBody, resBody)
+//	})).Post("/payments", createPayment)
+//
+// The request body captured is what the handler read of it.
+func BodyDump(handler func(r *http.Request, reqBody, resBody []byte)) func(http.Handler) http.Handler {
+	return BodyDumpWithOpts(BodyDumpOpts{Handler: handler})
+}
+
+// BodyDumpWithOpts is a BodyDump middleware using opts.
+func BodyDumpWithOpts(opts BodyDumpOpts) func(http.Handler) http.Handler {
+	if opts.Handler == nil {
+		panic("chi/middleware: BodyDump expects a handler")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 64 << 10
+	}
+	if opts.Redact == nil {
+		opts.Redact = DefaultBodyDumpRedact
+	}
+	redactor := newBodyRedactor(opts.Redact)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			reqBuf := &cappedBuffer{max: opts.MaxSize}
+			resBuf := &cappedBuffer{max: opts.MaxSize}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = readCloser{io.TeeReader(r.Body, reqBuf), r.Body}
+			}
+			ww := NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(resBuf)
+
+			defer func() {
+				opts.Handler(r,
+					redactor.redact(r.Header.Get("Content-Type"), reqBuf.Bytes()),
+					redactor.redact(ww.Header().Get("Content-Type"), resBuf.Bytes()))
+			}()
+			next.ServeHTTP(ww, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// cappedBuffer keeps the first max bytes written to it.
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyRedactor masks the values of sensitive fields in bodies.
+type bodyRedactor struct {
+	fields map[string]bool
+	json   *regexp.Regexp
+}
+
+func newBodyRedactor(fields []string) *bodyRedactor {
+	br := &bodyRedactor{fields: map[string]bool{}}
+	if len(fields) == 0 {
+		return br
+	}
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		br.fields[strings.ToLower(f)] = true
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	// A field name followed by a string, possibly truncated, or a scalar.
+	br.json = regexp.MustCompile(`("(?i:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*(?:"|\\?$)|[^,}\]\s]+)`)
+	return br
+}
+
+// redact returns body with the values of sensitive fields masked, when
+// contentType is JSON or a form.
+func (br *bodyRedactor) redact(contentType string, body []byte) []byte {
+	if len(body) == 0 || len(br.fields) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []byte("[REDACTED]")
+		}
+		for k := range values {
+			if br.fields[strings.ToLower(k)] {
+				values[k] = []string{"[REDACTED]"}
+			}
+		}
+		return []byte(values.Encode())
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return br.json.ReplaceAll(body, []byte(`$1"[REDACTED]"`))
+	}
+	return body
+}
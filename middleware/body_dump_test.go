@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyDump(t *testing.T) {
+	var gotReq, gotRes string
+	h := BodyDump(func(r *http.Request, reqBody, resBody []byte) {
+		gotReq, gotRes = string(reqBody), string(resBody)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "hunter2") {
+			t.Errorf("expected the handler to read the original body, got %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"user":{"name":"bob","token":"abc123"},"count":2}`))
+	}))
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"bob","Password":"hunter2","card":{"card_number":4111111111111111}}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if want := `{"name":"bob","Password":"[REDACTED]","card":{"card_number":"[REDACTED]"}}`; gotReq != want {
+		t.Fatalf("request body: got %s, want %s", gotReq, want)
+	}
+	if want := `{"user":{"name":"bob","token":"[REDACTED]"},"count":2}`; gotRes != want {
+		t.Fatalf("response body: got %s, want %s", gotRes, want)
+	}
+}
+
+func TestBodyDumpOpts(t *testing.T) {
+	var gotReq, gotRes string
+	h := BodyDumpWithOpts(BodyDumpOpts{
+		MaxSize: 24,
+		Handler: func(r *http.Request, reqBody, resBody []byte) {
+			gotReq, gotRes = string(reqBody), string(resBody)
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"password":"abcdefghijklmnop"}`))
+	}))
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("user=bob&password=hunter2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if gotReq != "password=%5BREDACTED%5D&user=bob" {
+		t.Fatalf("expected form fields to be redacted, got %s", gotReq)
+	}
+	if gotRes != `{"id":1,"password":"[REDACTED]"` {
+		t.Fatalf("expected truncated values to be redacted, got %s", gotRes)
+	}
+	if w.Body.Len() != 38 {
+		t.Fatalf("expected the full response to be served, got %d bytes", w.Body.Len())
+	}
+}
@@ -0,0 +1,392 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// CachedResponse is a response stored by the Cache middleware.
+type CachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	Stored time.Time   `json:"stored"`
+
+	// Vary holds the values of the request headers named by the Vary
+	// header of the response, which must match for it to be reused.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// CacheStore stores the responses of the Cache middleware. Implementations
+// backed by Redis share them across the instances of an app; they
+// typically serialize CachedResponse as JSON and use SCAN for
+// DeletePrefix.
+type CacheStore interface {
+	// Get returns the response stored under key, or nil.
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+	// Set stores res under key for ttl.
+	Set(ctx context.Context, key string, res *CachedResponse, ttl time.Duration) error
+	// Delete removes the response stored under key.
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes the responses stored under keys starting with
+	// prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// CacheConfig defines the configuration of the Cache middleware.
+type CacheConfig struct {
+	// TTL is how long responses are fresh. Required.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after TTL a stale response is still
+	// served, while it is refreshed in the background (default: 0).
+	StaleWhileRevalidate time.Duration
+
+	// KeyFunc returns the key a response is stored under (default: the
+	// path and query, followed by the VaryHeaders, so that the responses
+	// of a path can be invalidated with CacheStore.DeletePrefix). Requests
+	// with an empty key aren't cached.
+	KeyFunc func(r *http.Request) string
+
+	// VaryHeaders are the request headers the default key includes
+	// (default: Accept, Accept-Encoding, Accept-Language, Authorization
+	// and Cookie, so that users never get each other's responses).
+	VaryHeaders []string
+
+	// Store holds the responses (default: a MemoryCacheStore).
+	Store CacheStore
+
+	// MaxSize caps the body of cached responses (default: 1MB).
+	MaxSize int
+}
+
+// Cache is a middleware caching the successful responses of GET requests,
+// for read-heavy endpoints whose data may be slightly out of date:
+//
+//	store := middleware.NewMemoryCacheStore()
+//	r.With(middleware.Cache(middleware.CacheConfig{
+//		TTL:                  time.Minute,
+//		StaleWhileRevalidate: 5 * time.Minute,
+//		Store:                store,
+//	})).Get("/products/{id}", getProduct)
+//
+//	// After an update:
+//	store.DeletePrefix(ctx, "/products/"+id)
+//
+// Responses carry an X-Cache header, HIT, STALE or MISS, and cached ones an
+// Age header. Only the headers set by the handlers after Cache are stored,
+// and they don't replace the ones set by the middlewares before it, such
+// as CORS. Responses are only reused for requests matching the headers
+// named by their Vary header. Responses setting cookies, with
+// Cache-Control private or no-store, or with "Vary: *" aren't cached. The
+// default key includes the credentials of the request, so responses
+// depending on the user are cached per user; a KeyFunc leaving them out
+// must only be used for public responses.
+func Cache(config CacheConfig) func(http.Handler) http.Handler {
+	if config.TTL <= 0 {
+		panic("chi/middleware: Cache expects TTL > 0")
+	}
+	if config.VaryHeaders == nil {
+		config.VaryHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cookie"}
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(r *http.Request) string {
+			var b strings.Builder
+			b.WriteString(r.URL.RequestURI())
+			for _, h := range config.VaryHeaders {
+				b.WriteString("\n" + h + ": " + strings.Join(r.Header.Values(h), ", "))
+			}
+			return b.String()
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore()
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = 1 << 20
+	}
+	var mu sync.Mutex
+	revalidating := map[string]bool{}
+
+	return func(next http.Handler) http.Handler {
+		// record runs next and stores its response when it is cacheable.
+		// vary lists headers the response varies on besides the ones of its
+		// Vary header, for revalidations run without the outer middlewares.
+		record := func(w http.ResponseWriter, r *http.Request, key string, vary []string) {
+			before := w.Header().Clone()
+			cw := &cacheWriter{ResponseWriter: w, max: config.MaxSize}
+			next.ServeHTTP(cw, r)
+			if cw.status == 0 {
+				cw.status = http.StatusOK
+			}
+			if cw.status != http.StatusOK || cw.overflow || !cacheable(w.Header()) {
+				return
+			}
+			// Keep the headers of the handler, not the ones of outer
+			// middlewares, which depend on the request.
+			header := http.Header{}
+			for k, v := range w.Header() {
+				if !equalValues(before[k], v) {
+					header[k] = append([]string(nil), v...)
+				}
+			}
+			header.Del("X-Cache")
+			res := &CachedResponse{Status: cw.status, Header: header, Body: cw.body.Bytes(), Stored: time.Now()}
+			for _, name := range append(varyNames(w.Header()), vary...) {
+				if res.Vary == nil {
+					res.Vary = map[string]string{}
+				}
+				res.Vary[name] = strings.Join(r.Header.Values(name), ", ")
+			}
+			_ = config.Store.Set(r.Context(), key, res, config.TTL+config.StaleWhileRevalidate)
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := config.KeyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := config.Store.Get(r.Context(), key)
+			if err == nil && res != nil && varyMatches(res, r) {
+				age := time.Since(res.Stored)
+				switch {
+				case age < config.TTL:
+					writeCached(w, res, "HIT", age)
+					return
+				case age < config.TTL+config.StaleWhileRevalidate:
+					mu.Lock()
+					start := !revalidating[key]
+					revalidating[key] = true
+					mu.Unlock()
+					if start {
+						rr := r.Clone(detachedContext(r.Context()))
+						go func() {
+							defer func() {
+								mu.Lock()
+								delete(revalidating, key)
+								mu.Unlock()
+							}()
+							vary := make([]string, 0, len(res.Vary))
+							for name := range res.Vary {
+								vary = append(vary, name)
+							}
+							record(&cacheRecorder{header: http.Header{}}, rr, key, vary)
+						}()
+					}
+					writeCached(w, res, "STALE", age)
+					return
+				}
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			record(w, r, key, nil)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// detachedContext returns a context for revalidating the response of a
+// request once it is done: not canceled with ctx, and with a copy of its
+// routing context, which the router reuses for other requests.
+func detachedContext(ctx context.Context) context.Context {
+	ctx = context.WithoutCancel(ctx)
+	rctx := owl.RouteContext(ctx)
+	if rctx == nil {
+		return ctx
+	}
+	cp := owl.NewRouteContext()
+	cp.Routes = rctx.Routes
+	cp.RoutePath = rctx.RoutePath
+	cp.RouteMethod = rctx.RouteMethod
+	cp.URLParams.Keys = append([]string(nil), rctx.URLParams.Keys...)
+	cp.URLParams.Values = append([]string(nil), rctx.URLParams.Values...)
+	cp.RoutePatterns = append([]string(nil), rctx.RoutePatterns...)
+	return context.WithValue(ctx, owl.RouteCtxKey, cp)
+}
+
+// cacheable reports whether a response with header may be shared.
+func cacheable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	for _, name := range varyNames(header) {
+		if name == "*" {
+			return false
+		}
+	}
+	cc := strings.ToLower(header.Get("Cache-Control"))
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
+
+// varyNames returns the header names listed by the Vary header.
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyMatches reports whether r has the values of the headers res varies
+// on.
+func varyMatches(res *CachedResponse, r *http.Request) bool {
+	for name, v := range res.Vary {
+		if strings.Join(r.Header.Values(name), ", ") != v {
+			return false
+		}
+	}
+	return true
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCached writes res, without replacing the headers already set by
+// the middlewares before Cache.
+func writeCached(w http.ResponseWriter, res *CachedResponse, state string, age time.Duration) {
+	for k, v := range res.Header {
+		if _, ok := w.Header()[k]; !ok {
+			w.Header()[k] = append([]string(nil), v...)
+		}
+	}
+	w.Header().Set("X-Cache", state)
+	w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+	w.WriteHeader(res.Status)
+	w.Write(res.Body)
+}
+
+// cacheWriter writes the response through and records its body, up to
+// max bytes.
+type cacheWriter struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	max      int
+	status   int
+	overflow bool
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflow {
+		if w.body.Len()+len(b) > w.max {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// cacheRecorder is the ResponseWriter of background revalidations.
+type cacheRecorder struct {
+	header http.Header
+}
+
+func (w *cacheRecorder) Header() http.Header         { return w.header }
+func (w *cacheRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (w *cacheRecorder) WriteHeader(int)             {}
+
+// MemoryCacheStore is a CacheStore keeping responses in memory, for single
+// instance apps. Expired responses are dropped as new ones are stored.
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	sets    int
+}
+
+type memoryCacheEntry struct {
+	res     *CachedResponse
+	expires time.Time
+}
+
+// NewMemoryCacheStore returns an empty MemoryCacheStore.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: map[string]memoryCacheEntry{}}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, nil
+	}
+	return e.res, nil
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, res *CachedResponse, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sets++; s.sets%1024 == 0 {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	s.entries[key] = memoryCacheEntry{res: res, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements CacheStore.
+func (s *MemoryCacheStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// DeletePrefix implements CacheStore.
+func (s *MemoryCacheStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.entries, k)
+		}
+	}
+	return nil
+}
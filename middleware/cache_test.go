@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int32
+	store := NewMemoryCacheStore()
+	h := Cache(CacheConfig{TTL: time.Minute, StaleWhileRevalidate: time.Minute, Store: store})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("v" + strconv.Itoa(int(n))))
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := get("/products/1"); w.Body.String() != "v1" || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("got %q, X-Cache %q", w.Body.String(), w.Header().Get("X-Cache"))
+	}
+	if w := get("/products/1"); w.Body.String() != "v1" || w.Header().Get("X-Cache") != "HIT" || w.Header().Get("Content-Type") != "text/plain" {
+		t.Fatalf("expected a cached response, got %q, headers %v", w.Body.String(), w.Header())
+	}
+	if w := get("/products/1?page=2"); w.Body.String() != "v2" {
+		t.Fatalf("expected queries to be cached apart, got %q", w.Body.String())
+	}
+
+	store.DeletePrefix(context.Background(), "/products/1")
+	if w := get("/products/1"); w.Body.String() != "v3" || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("expected invalidated responses to be refreshed, got %q", w.Body.String())
+	}
+
+	// Age the response past its TTL.
+	for k, e := range store.entries {
+		e.res.Stored = e.res.Stored.Add(-90 * time.Second)
+		store.entries[k] = e
+	}
+	if w := get("/products/1"); w.Body.String() != "v3" || w.Header().Get("X-Cache") != "STALE" || w.Header().Get("Age") != "90" {
+		t.Fatalf("expected a stale response, got %q, headers %v", w.Body.String(), w.Header())
+	}
+	deadline := time.Now().Add(time.Second)
+	w := get("/products/1")
+	for w.Header().Get("X-Cache") != "HIT" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		w = get("/products/1")
+	}
+	if w.Body.String() != "v4" || w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("expected the response to be revalidated in the background, got %q, headers %v", w.Body.String(), w.Header())
+	}
+
+	get("/private")
+	if w := get("/private"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected private responses not to be cached")
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/products/1", nil))
+	if w.Header().Get("X-Cache") != "" {
+		t.Fatal("expected POST requests not to be cached")
+	}
+}
+
+func TestCacheRevalidateParams(t *testing.T) {
+	store := NewMemoryCacheStore()
+	release := make(chan struct{})
+	r := owl.NewRouter()
+	r.With(Cache(CacheConfig{TTL: time.Minute, StaleWhileRevalidate: time.Minute, Store: store})).Get("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Wait") != "" {
+			<-release
+		}
+		w.Write([]byte("product " + owl.URLParam(r, "id")))
+	})
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	get("/products/1", http.Header{"X-Wait": nil})
+	for k, e := range store.entries {
+		e.res.Stored = e.res.Stored.Add(-90 * time.Second)
+		store.entries[k] = e
+	}
+	// The revalidation waits until other requests reused the routing context.
+	if w := get("/products/1", http.Header{"X-Wait": {"1"}}); w.Header().Get("X-Cache") != "STALE" {
+		t.Fatalf("expected a stale response, got %v", w.Header())
+	}
+	for i := 0; i < 10; i++ {
+		get("/products/999", nil)
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	w := get("/products/1", nil)
+	for w.Header().Get("X-Cache") != "HIT" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		w = get("/products/1", nil)
+	}
+	if w.Body.String() != "product 1" {
+		t.Fatalf("expected the revalidated response of the product, got %q", w.Body.String())
+	}
+
+	if w := get("/products/2", http.Header{"Authorization": {"Bearer a"}}); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected a miss")
+	}
+	if w := get("/products/2", http.Header{"Authorization": {"Bearer b"}}); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected the responses of users to be cached apart")
+	}
+}
+
+func TestCacheBehindCORS(t *testing.T) {
+	var calls atomic.Int32
+	r := owl.NewRouter()
+	r.Use(CORSWithConfig(CORSConfig{AllowOrigins: []string{"https://a.com", "https://b.com"}}))
+	r.With(Cache(CacheConfig{TTL: time.Minute})).Get("/products", func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("v" + strconv.Itoa(int(n))))
+	})
+	get := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/products", nil)
+		req.Header.Set("Origin", origin)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	get("https://a.com")
+	for _, origin := range []string{"https://b.com", "https://b.com"} {
+		w := get(origin)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Fatalf("expected the origin of the request to be allowed, got %q, X-Cache %q", got, w.Header().Get("X-Cache"))
+		}
+		if len(w.Header().Values("Vary")) != 1 || w.Header().Get("Content-Type") != "text/plain" {
+			t.Fatalf("unexpected headers %v", w.Header())
+		}
+	}
+	if w := get("https://b.com"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "v2" {
+		t.Fatalf("expected the response for b.com to be cached, got %q, %v", w.Body.String(), w.Header())
+	}
+
+	star := Cache(CacheConfig{TTL: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "*")
+		w.Write([]byte("ok"))
+	}))
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		star.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Header().Get("X-Cache") != "MISS" {
+			t.Fatal("expected responses with Vary: * not to be cached")
+		}
+	}
+}
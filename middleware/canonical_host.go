@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CanonicalHostOpts represents a set of canonical host options.
+type CanonicalHostOpts struct {
+	// URL is the canonical scheme and host, e.g. "https://www.example.com".
+	// Any path is ignored.
+	URL string
+
+	// Permanent sends 301/308 redirects instead of 302/307.
+	Permanent bool
+
+	// SkipPaths are served as is, whatever the scheme and host, so that
+	// load balancer health checks don't get redirected (default: /health,
+	// /healthz, /livez, /ready, /readyz and /ping).
+	SkipPaths []string
+
+	// TrustForwardedProto takes the request scheme from the
+	// X-Forwarded-Proto header, for TLS terminated by a trusted proxy.
+	TrustForwardedProto bool
+
+	// HSTSMaxAge, when set, adds a Strict-Transport-Security header to
+	// responses served over https on the canonical host.
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains and HSTSPreload add the includeSubDomains and
+	// preload directives to the Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+}
+
+// CanonicalHost is a middleware that redirects requests to the scheme and
+// host of canonical, keeping path and query, e.g. http to https and apex
+// to www:
+//
+//	r.Use(middleware.CanonicalHost("https://www.example.com", true))
+//
+// Health endpoints are not redirected, see CanonicalHostOpts.SkipPaths.
+func CanonicalHost(canonical string, permanent bool) func(http.Handler) http.Handler {
+	return CanonicalHostWithOpts(CanonicalHostOpts{URL: canonical, Permanent: permanent})
+}
+
+// CanonicalHostWithOpts is a middleware that redirects requests to a
+// canonical scheme and host using passed CanonicalHostOpts.
+func CanonicalHostWithOpts(opts CanonicalHostOpts) func(http.Handler) http.Handler {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		panic("chi/middleware: CanonicalHost expects an absolute URL such as https://www.example.com")
+	}
+	scheme := strings.ToLower(u.Scheme)
+	host := stripDefaultPort(strings.ToLower(u.Host), scheme)
+
+	if opts.SkipPaths == nil {
+		opts.SkipPaths = []string{"/health", "/healthz", "/livez", "/ready", "/readyz", "/ping"}
+	}
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skip[p] = true
+	}
+
+	hsts := ""
+	if opts.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(opts.HSTSMaxAge.Seconds()))
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if opts.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqScheme := "http"
+			if r.TLS != nil {
+				reqScheme = "https"
+			}
+			if opts.TrustForwardedProto {
+				if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+					reqScheme = strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+				}
+			}
+
+			if reqScheme != scheme || stripDefaultPort(strings.ToLower(r.Host), reqScheme) != host {
+				target := url.URL{Scheme: scheme, Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+				http.Redirect(w, r, target.String(), redirectCode(r.Method, opts.Permanent))
+				return
+			}
+
+			if hsts != "" && scheme == "https" {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// stripDefaultPort removes the port of host when it is the default one of
+// scheme, which clients may or may not send.
+func stripDefaultPort(host, scheme string) string {
+	if (scheme == "https" && strings.HasSuffix(host, ":443")) || (scheme == "http" && strings.HasSuffix(host, ":80")) {
+		return host[:strings.LastIndexByte(host, ':')]
+	}
+	return host
+}
+
+// redirectCode picks a redirect status that keeps the method and body of
+// non-GET requests.
+func redirectCode(method string, permanent bool) int {
+	safe := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case permanent && safe:
+		return http.StatusMovedPermanently
+	case permanent:
+		return http.StatusPermanentRedirect
+	case safe:
+		return http.StatusFound
+	default:
+		return http.StatusTemporaryRedirect
+	}
+}
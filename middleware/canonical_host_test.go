@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	r := owl.NewRouter()
+	r.Use(CanonicalHostWithOpts(CanonicalHostOpts{
+		URL:                 "https://www.example.com",
+		Permanent:           true,
+		TrustForwardedProto: true,
+		HSTSMaxAge:          365 * 24 * time.Hour,
+	}))
+	r.Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	tests := []struct {
+		name, method, url, proto string
+		tls                      bool
+		code                     int
+		location, hsts           string
+	}{
+		{"http to https", "GET", "http://www.example.com/a?b=1", "", false, 301, "https://www.example.com/a?b=1", ""},
+		{"apex to www", "GET", "https://example.com/a", "", true, 301, "https://www.example.com/a", ""},
+		{"post keeps method", "POST", "http://example.com/form", "", false, 308, "https://www.example.com/form", ""},
+		{"canonical", "GET", "https://www.example.com/a", "", true, 200, "", "max-age=31536000"},
+		{"behind proxy", "GET", "http://www.example.com/a", "https", false, 200, "", "max-age=31536000"},
+		{"default port", "GET", "https://www.example.com:443/a", "", true, 200, "", "max-age=31536000"},
+		{"other port", "GET", "https://www.example.com:8443/a", "", true, 301, "https://www.example.com/a", ""},
+		{"health check", "GET", "http://10.0.0.1/healthz", "", false, 200, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.code || w.Header().Get("Location") != tt.location || w.Header().Get("Strict-Transport-Security") != tt.hsts {
+				t.Errorf("got %d location=%q hsts=%q", w.Code, w.Header().Get("Location"), w.Header().Get("Strict-Transport-Security"))
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	h := CanonicalHost("https://www.example.com", false)(http.NotFoundHandler())
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected temporary redirect, got %d", w.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// ChaosOpts configures the Chaos middleware. Rates are fractions between 0
+// and 1 of the requests that get the fault; each fault is drawn
+// independently.
+type ChaosOpts struct {
+	// LatencyRate is the fraction of requests delayed by up to Latency.
+	LatencyRate float64
+	Latency     time.Duration
+
+	// ErrorRate is the fraction of requests answered with ErrorStatus
+	// (default: 503) instead of reaching the handler.
+	ErrorRate   float64
+	ErrorStatus int
+
+	// ResetRate is the fraction of requests whose connection is reset
+	// without a response.
+	ResetRate float64
+
+	// Enabled decides per request whether faults may be injected, e.g. to
+	// only target requests with a test header. Default: all requests.
+	Enabled func(r *http.Request) bool
+
+	// AllowProduction must be set to inject faults when the OWL_ENV or
+	// APP_ENV environment variable is "production" or "prod".
+	AllowProduction bool
+
+	// Rand returns a pseudo-random number in [0, 1). Default: math/rand.
+	Rand func() float64
+}
+
+// Chaos is a fault-injection middleware for resilience testing of clients
+// and retry logic. It injects latency, error responses and connection
+// resets into a configurable share of requests.
+//
+// As a guard, Chaos does nothing when the process runs in production (see
+// ChaosOpts.AllowProduction):
+//
+//	r.Use(middleware.Chaos(middleware.ChaosOpts{
+//		LatencyRate: 0.2, Latency: 2 * time.Second,
+//		ErrorRate:   0.05,
+//		ResetRate:   0.01,
+//	}))
+func Chaos(opts ChaosOpts) func(http.Handler) http.Handler {
+	if !opts.AllowProduction && owl.IsProduction() {
+		log.Printf("chi/middleware: Chaos disabled in production")
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusServiceUnavailable
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.Float64
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if opts.Enabled != nil && !opts.Enabled(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if opts.LatencyRate > 0 && opts.Rand() < opts.LatencyRate {
+				d := time.Duration(opts.Rand() * float64(opts.Latency))
+				select {
+				case <-time.After(d):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			if opts.ResetRate > 0 && opts.Rand() < opts.ResetRate {
+				resetConn(w)
+				return
+			}
+
+			if opts.ErrorRate > 0 && opts.Rand() < opts.ErrorRate {
+				w.Header().Set("X-Chaos", "error")
+				http.Error(w, http.StatusText(opts.ErrorStatus), opts.ErrorStatus)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// resetConn drops the connection without a response. On HTTP/1 TCP
+// connections the socket is closed with SO_LINGER 0 so the client sees a
+// reset; otherwise the handler is aborted, which closes the stream.
+func resetConn(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			if tc, ok := conn.(*net.TCPConn); ok {
+				tc.SetLinger(0)
+			}
+			conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
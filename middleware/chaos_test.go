@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestChaos(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+
+	t.Run("error", func(t *testing.T) {
+		r := owl.NewRouter()
+		r.Use(Chaos(ChaosOpts{ErrorRate: 0.5, Rand: func() float64 { return 0.1 }}))
+		r.Get("/", ok)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("X-Chaos") != "error" {
+			t.Fatalf("expected injected 503, got %d", w.Code)
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		r := owl.NewRouter()
+		r.Use(Chaos(ChaosOpts{LatencyRate: 1, Latency: 100 * time.Millisecond, Rand: func() float64 { return 0.5 }}))
+		r.Get("/", ok)
+
+		start := time.Now()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if d := time.Since(start); d < 50*time.Millisecond || w.Body.String() != "ok" {
+			t.Fatalf("expected ~50ms delay and ok body, got %v %q", d, w.Body.String())
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		r := owl.NewRouter()
+		r.Use(Chaos(ChaosOpts{ResetRate: 1}))
+		r.Get("/", ok)
+
+		ts := httptest.NewServer(r)
+		defer ts.Close()
+		res, err := http.Get(ts.URL)
+		if err == nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			t.Fatal("expected connection error")
+		}
+	})
+
+	t.Run("production guard", func(t *testing.T) {
+		t.Setenv("OWL_ENV", "production")
+		r := owl.NewRouter()
+		r.Use(Chaos(ChaosOpts{ErrorRate: 1}))
+		r.Get("/", ok)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("chaos should be disabled in production, got %d", w.Code)
+		}
+	})
+}
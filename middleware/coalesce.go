@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CoalesceOpts configures the Coalesce middleware.
+type CoalesceOpts struct {
+	// VaryHeaders are the request headers that, with the method, path and
+	// query, tell identical requests apart (default: Accept,
+	// Accept-Encoding, Accept-Language, Authorization and Cookie, so that
+	// users never get each other's responses).
+	VaryHeaders []string
+
+	// KeyFunc replaces the key computed from VaryHeaders, e.g. to coalesce
+	// requests of the same tenant. Requests with an empty key are served
+	// on their own.
+	KeyFunc func(r *http.Request) string
+}
+
+// Coalesce is a middleware that runs the handler once for concurrent
+// identical GET requests and fans its response out to all of them. It
+// protects expensive endpoints, such as reports, from thundering herds
+// when a cache expires or many clients poll at once:
+//
+//	r.With(middleware.Coalesce(middleware.CoalesceOpts{})).Get("/reports/daily", dailyReport)
+//
+// Requests arriving after the response is complete run the handler again;
+// combine Coalesce with a cache to reuse responses over time. Responses
+// setting cookies are never shared: the waiting requests then run the
+// handler themselves. Responses are buffered in memory, and coalesced
+// responses carry an "X-Coalesced: true" header.
+func Coalesce(opts CoalesceOpts) func(http.Handler) http.Handler {
+	if opts.VaryHeaders == nil {
+		opts.VaryHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cookie"}
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = func(r *http.Request) string {
+			var b strings.Builder
+			b.WriteString(r.Method + " " + r.URL.RequestURI())
+			for _, h := range opts.VaryHeaders {
+				b.WriteString("\n" + h + ": " + strings.Join(r.Header.Values(h), ", "))
+			}
+			return b.String()
+		}
+	}
+	var mu sync.Mutex
+	calls := map[string]*coalescedCall{}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := opts.KeyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mu.Lock()
+			if call, ok := calls[key]; ok {
+				mu.Unlock()
+				select {
+				case <-call.done:
+				case <-r.Context().Done():
+					return
+				}
+				if !call.ok || call.private {
+					// The leader panicked, was aborted or got a response
+					// of its own, such as a session cookie.
+					next.ServeHTTP(w, r)
+					return
+				}
+				call.writeTo(w)
+				return
+			}
+			call := &coalescedCall{done: make(chan struct{}), header: http.Header{}}
+			calls[key] = call
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				delete(calls, key)
+				mu.Unlock()
+				close(call.done)
+			}()
+			next.ServeHTTP(&coalesceWriter{ResponseWriter: w, call: call}, r)
+			if call.status == 0 {
+				call.status = http.StatusOK
+			}
+			call.ok = true
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// coalescedCall is the response of the leading request, shared with the
+// concurrent identical ones once done is closed.
+type coalescedCall struct {
+	done    chan struct{}
+	ok      bool
+	private bool // The response sets cookies
+	status  int
+	header  http.Header
+	body    bytes.Buffer
+}
+
+func (c *coalescedCall) writeTo(w http.ResponseWriter) {
+	for k, v := range c.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+	w.Header().Set("X-Coalesced", "true")
+	w.WriteHeader(c.status)
+	w.Write(c.body.Bytes())
+}
+
+// coalesceWriter writes the leader's response through and records it.
+type coalesceWriter struct {
+	http.ResponseWriter
+	call *coalescedCall
+}
+
+func (w *coalesceWriter) WriteHeader(code int) {
+	if w.call.status == 0 && code >= 200 {
+		w.call.status = code
+		w.call.private = len(w.Header().Values("Set-Cookie")) > 0
+		for k, v := range w.Header() {
+			w.call.header[k] = append([]string(nil), v...)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *coalesceWriter) Write(b []byte) (int, error) {
+	if w.call.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.call.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *coalesceWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	h := Coalesce(CoalesceOpts{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("report for " + r.Header.Get("Authorization")))
+	}))
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+		auth := "alice"
+		if i == 4 {
+			auth = "bob"
+		}
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			r := httptest.NewRequest("GET", "/reports?day=1", nil)
+			r.Header.Set("Authorization", auth)
+			h.ServeHTTP(w, r)
+		}(responses[i])
+	}
+	for deadline := time.Now().Add(time.Second); runs.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // Let the followers join
+	close(release)
+	wg.Wait()
+
+	if n := runs.Load(); n != 2 {
+		t.Fatalf("expected one run per user, got %d", n)
+	}
+	coalesced := 0
+	for i, w := range responses {
+		want := "report for alice"
+		if i == 4 {
+			want = "report for bob"
+		}
+		if w.Code != 200 || w.Body.String() != want || w.Header().Get("Content-Type") != "text/csv" {
+			t.Errorf("response %d: got %d %q %v", i, w.Code, w.Body.String(), w.Header())
+		}
+		if w.Header().Get("X-Coalesced") == "true" {
+			coalesced++
+		}
+	}
+	if coalesced != 3 {
+		t.Errorf("expected 3 coalesced responses, got %d", coalesced)
+	}
+}
+
+func TestCoalesceCookies(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	h := Coalesce(CoalesceOpts{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := runs.Add(1)
+		if n == 1 {
+			<-release
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: strconv.Itoa(int(n))})
+		w.Write([]byte("hello"))
+	}))
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 3)
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		}(responses[i])
+	}
+	time.Sleep(20 * time.Millisecond) // Let the followers join
+	close(release)
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i, w := range responses {
+		if w.Header().Get("X-Coalesced") != "" {
+			t.Errorf("response %d: expected responses setting cookies not to be shared", i)
+		}
+		seen[w.Header().Get("Set-Cookie")] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected a cookie per request, got %v", seen)
+	}
+}
@@ -1,22 +1,71 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // ContentCharset generates a handler that writes a 415 Unsupported Media Type response if none of the charsets match.
 // An empty charset will allow requests with no Content-Type header or no specified charset.
 func ContentCharset(charsets ...string) func(next http.Handler) http.Handler {
-	for i, c := range charsets {
+	return ContentCharsetWithOpts(ContentCharsetOpts{Charsets: charsets})
+}
+
+// ContentCharsetOpts configures the ContentCharsetWithOpts middleware.
+type ContentCharsetOpts struct {
+	// Charsets are the accepted charsets, matched case-insensitively. An
+	// empty charset accepts requests without one.
+	Charsets []string
+
+	// TranscodeLatin1 also accepts ISO-8859-1 bodies, and converts them to
+	// UTF-8 before the handler reads them, for legacy clients posting
+	// forms in Latin-1. The Content-Type charset is rewritten to utf-8.
+	TranscodeLatin1 bool
+
+	// MaxBody caps the bodies read for transcoding (default: 10MB).
+	MaxBody int64
+}
+
+// ContentCharsetWithOpts is a ContentCharset middleware using opts:
+//
+//	r.Use(middleware.ContentCharsetWithOpts(middleware.ContentCharsetOpts{
+//		Charsets:        []string{"utf-8", ""},
+//		TranscodeLatin1: true,
+//	}))
+func ContentCharsetWithOpts(opts ContentCharsetOpts) func(next http.Handler) http.Handler {
+	charsets := make([]string, len(opts.Charsets))
+	for i, c := range opts.Charsets {
 		charsets[i] = strings.ToLower(c)
 	}
+	if opts.MaxBody <= 0 {
+		opts.MaxBody = 10 << 20
+	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !contentEncoding(r.Header.Get("Content-Type"), charsets...) {
-				w.WriteHeader(http.StatusUnsupportedMediaType)
+			ctype := r.Header.Get("Content-Type")
+			if opts.TranscodeLatin1 && isLatin1(contentCharset(ctype)) {
+				if err := transcodeLatin1(r, opts.MaxBody); err != nil {
+					status := http.StatusBadRequest
+					if errors.Is(err, errBodyTooLarge) {
+						status = http.StatusRequestEntityTooLarge
+					}
+					http.Error(w, http.StatusText(status), status)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !contentEncoding(ctype, charsets...) {
+				unsupportedMediaType(w, "Unsupported charset, expected "+strings.Join(opts.Charsets, " or "))
 				return
 			}
 
@@ -27,10 +76,15 @@ func ContentCharset(charsets ...string) func(next http.Handler) http.Handler {
 
 // Check the content encoding against a list of acceptable values.
 func contentEncoding(ce string, charsets ...string) bool {
+	return slices.Contains(charsets, contentCharset(ce))
+}
+
+// contentCharset returns the lower-cased charset of a Content-Type value.
+func contentCharset(ce string) string {
 	_, ce = split(strings.ToLower(ce), ";")
 	_, ce = split(ce, "charset=")
 	ce, _ = split(ce, ";")
-	return slices.Contains(charsets, ce)
+	return strings.Trim(ce, `"`)
 }
 
 // Split a string in two parts, cleaning any whitespace.
@@ -43,3 +97,62 @@ func split(str, sep string) (string, string) {
 
 	return a, b
 }
+
+var errBodyTooLarge = errors.New("body too large")
+
+// isLatin1 reports whether charset names ISO-8859-1.
+func isLatin1(charset string) bool {
+	switch charset {
+	case "iso-8859-1", "iso_8859-1", "latin1", "latin-1", "l1":
+		return true
+	}
+	return false
+}
+
+// transcodeLatin1 replaces the ISO-8859-1 body of r with its UTF-8
+// conversion. The escapes of urlencoded forms are converted too.
+func transcodeLatin1(r *http.Request, max int64) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > max {
+		return errBodyTooLarge
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			converted := make(url.Values, len(values))
+			for k, vs := range values {
+				for _, v := range vs {
+					converted.Add(latin1ToUTF8(k), latin1ToUTF8(v))
+				}
+			}
+			body = []byte(converted.Encode())
+		}
+	} else {
+		body = []byte(latin1ToUTF8(string(body)))
+	}
+
+	if params == nil {
+		params = map[string]string{}
+	}
+	params["charset"] = "utf-8"
+	r.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// latin1ToUTF8 converts s, whose bytes are ISO-8859-1 code points.
+func latin1ToUTF8(s string) string {
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		buf = utf8.AppendRune(buf, rune(s[i]))
+	}
+	return string(buf)
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-owl/owl"
@@ -125,3 +127,34 @@ func TestContentEncoding(t *testing.T) {
 		t.Error("Want true, got false")
 	}
 }
+
+func TestContentCharsetTranscodeLatin1(t *testing.T) {
+	var gotType, gotName, gotBody string
+	h := ContentCharsetWithOpts(ContentCharsetOpts{Charsets: []string{"utf-8", ""}, TranscodeLatin1: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("Content-Type")
+		if strings.HasPrefix(gotType, "application/x-www-form-urlencoded") {
+			gotName = r.FormValue("name")
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	send := func(ctype, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", ctype)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := send("application/x-www-form-urlencoded; charset=ISO-8859-1", "name=Jos%E9+M%FCller"); w.Code != 200 || gotName != "José Müller" || gotType != "application/x-www-form-urlencoded; charset=utf-8" {
+		t.Fatalf("got %d, name %q, Content-Type %q", w.Code, gotName, gotType)
+	}
+	if w := send("text/plain; charset=latin1", "caf\xe9"); w.Code != 200 || gotBody != "café" {
+		t.Fatalf("got %d, body %q", w.Code, gotBody)
+	}
+	w := send("text/plain; charset=windows-1251", "...")
+	if w.Code != http.StatusUnsupportedMediaType || !strings.Contains(w.Body.String(), `"code":415`) {
+		t.Fatalf("expected other charsets to be rejected with a JSON 415, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 	"strings"
+
+	"github.com/go-owl/owl"
 )
 
 // SetHeader is a convenience handler to set a response header key/value
@@ -16,7 +18,11 @@ func SetHeader(key, value string) func(http.Handler) http.Handler {
 }
 
 // AllowContentType enforces a whitelist of request Content-Types otherwise responds
-// with a 415 Unsupported Media Type status.
+// with a 415 Unsupported Media Type status and a JSON error body, before
+// handlers try to bind the body. Parameters such as charset are ignored and
+// media types are matched case-insensitively.
+//
+//	r.Use(middleware.AllowContentType("application/json"))
 func AllowContentType(contentTypes ...string) func(http.Handler) http.Handler {
 	allowedContentTypes := make(map[string]struct{}, len(contentTypes))
 	for _, ctype := range contentTypes {
@@ -39,7 +45,17 @@ func AllowContentType(contentTypes ...string) func(http.Handler) http.Handler {
 				return
 			}
 
-			w.WriteHeader(http.StatusUnsupportedMediaType)
+			unsupportedMediaType(w, "Unsupported Media Type, expected "+strings.Join(contentTypes, " or "))
 		})
 	}
 }
+
+// unsupportedMediaType answers 415 Unsupported Media Type with a JSON error
+// body like the one of owl's default error handler.
+func unsupportedMediaType(w http.ResponseWriter, msg string) {
+	_ = owl.JSON(w, http.StatusUnsupportedMediaType, map[string]interface{}{
+		"success": false,
+		"code":    http.StatusUnsupportedMediaType,
+		"message": msg,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-owl/owl"
@@ -48,6 +49,12 @@ func TestContentType(t *testing.T) {
 			[]string{"application/json"},
 			http.StatusUnsupportedMediaType,
 		},
+		{
+			"should accept requests with a matching content type in another case",
+			"Application/JSON; Charset=utf-8",
+			[]string{"application/json"},
+			http.StatusOK,
+		},
 		{
 			"should not accept requests with a mismatching content type even if multiple content types are allowed",
 			"text/plain; charset=Latin-1",
@@ -77,6 +84,14 @@ func TestContentType(t *testing.T) {
 			if res.StatusCode != tt.want {
 				t.Errorf("response is incorrect, got %d, want %d", recorder.Code, tt.want)
 			}
+			if tt.want == http.StatusUnsupportedMediaType {
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+					t.Errorf("expected a JSON error body, got Content-Type %q", ct)
+				}
+				if !strings.Contains(recorder.Body.String(), `"code":415`) {
+					t.Errorf("expected a 415 error body, got %q", recorder.Body.String())
+				}
+			}
 		})
 	}
 }
@@ -2,8 +2,11 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+
+	"github.com/go-owl/owl"
 )
 
 // CORSConfig defines CORS configuration.
@@ -11,6 +14,10 @@ type CORSConfig struct {
 	// AllowOrigins defines allowed origins. Use ["*"] to allow all.
 	AllowOrigins []string
 
+	// AllowOriginFunc allows origins missing from AllowOrigins, e.g. by
+	// pattern. The origin is echoed back.
+	AllowOriginFunc func(origin string) bool
+
 	// AllowMethods defines allowed HTTP methods.
 	AllowMethods []string
 
@@ -58,9 +65,12 @@ func CORSWithConfig(config CORSConfig) func(http.Handler) http.Handler {
 					break
 				}
 			}
+			if allowOrigin == "" && origin != "" && config.AllowOriginFunc != nil && config.AllowOriginFunc(origin) {
+				allowOrigin = origin
+			}
 
 			// If origin not allowed and not wildcard, skip CORS headers
-			if allowOrigin == "" && len(config.AllowOrigins) > 0 && config.AllowOrigins[0] != "*" {
+			if allowOrigin == "" && (config.AllowOriginFunc != nil || len(config.AllowOrigins) > 0 && config.AllowOrigins[0] != "*") {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -114,3 +124,52 @@ func CORSWithConfig(config CORSConfig) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// CORSForEnv returns a CORS middleware adapted to the environment named by
+// the OWL_ENV or APP_ENV environment variable, so that a wildcard meant
+// for local development can't ship to production by accident:
+//
+//   - In development ("development", "dev" or "local"), origins on
+//     localhost, 127.0.0.1 or [::1], on any port, are allowed on top of
+//     config.AllowOrigins, e.g. for a frontend dev server.
+//   - In every other environment, including when neither variable is
+//     set, config.AllowOrigins must be an explicit allowlist: CORSForEnv
+//     panics when it is empty or contains "*".
+//
+// Example:
+//
+//	config := middleware.DefaultCORSConfig()
+//	config.AllowOrigins = []string{"https://app.example.com"}
+//	r.Use(middleware.CORSForEnv(config))
+func CORSForEnv(config CORSConfig) func(http.Handler) http.Handler {
+	if owl.IsDevelopment() {
+		allow := config.AllowOriginFunc
+		config.AllowOriginFunc = func(origin string) bool {
+			return isLocalOrigin(origin) || (allow != nil && allow(origin))
+		}
+		return CORSWithConfig(config)
+	}
+
+	if len(config.AllowOrigins) == 0 && config.AllowOriginFunc == nil {
+		panic("chi/middleware: CORSForEnv needs AllowOrigins outside of development")
+	}
+	for _, o := range config.AllowOrigins {
+		if o == "*" {
+			panic("chi/middleware: CORSForEnv doesn't allow the \"*\" origin outside of development")
+		}
+	}
+	return CORSWithConfig(config)
+}
+
+// isLocalOrigin reports whether origin is served from the local machine.
+func isLocalOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
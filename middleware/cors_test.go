@@ -64,3 +64,50 @@ func TestCORSWithConfig(t *testing.T) {
 		t.Errorf("Expected no Access-Control-Allow-Origin header, got %s", origin)
 	}
 }
+
+func TestCORSForEnv(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowOrigins = []string{"https://app.example.com"}
+	allowed := func(h http.Handler, origin string) bool {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Origin") == origin
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Setenv("OWL_ENV", "")
+	t.Setenv("APP_ENV", "")
+	if allowed(CORSForEnv(config)(ok), "http://localhost:5173") {
+		t.Error("unset environment: expected local origins to be rejected")
+	}
+
+	t.Setenv("APP_ENV", "development")
+	dev := CORSForEnv(config)(ok)
+	for _, origin := range []string{"http://localhost:5173", "http://127.0.0.1:3000", "http://[::1]:8080", "https://app.example.com"} {
+		if !allowed(dev, origin) {
+			t.Errorf("development: expected %s to be allowed", origin)
+		}
+	}
+	if allowed(dev, "http://localhost.evil.com") {
+		t.Error("development: expected other hosts to be rejected")
+	}
+
+	t.Setenv("OWL_ENV", "production")
+	prod := CORSForEnv(config)(ok)
+	if allowed(prod, "http://localhost:5173") || !allowed(prod, "https://app.example.com") {
+		t.Error("production: expected only the allowlist to be allowed")
+	}
+
+	for name, origins := range map[string][]string{"wildcard": {"*"}, "empty": nil} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("production: expected a panic for the %s allowlist", name)
+				}
+			}()
+			CORSForEnv(CORSConfig{AllowOrigins: origins})
+		}()
+	}
+}
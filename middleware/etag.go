@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// ETagOpts configures the ETagWithOpts middleware.
+type ETagOpts struct {
+	// Weak makes the ETags weak, e.g. W/"...", for responses that are
+	// equivalent without being byte-identical, such as compressed ones.
+	Weak bool
+
+	// MaxSize caps the buffered response; larger responses are streamed
+	// without ETag (default: 1MB).
+	MaxSize int
+}
+
+// ETag is a middleware setting a strong ETag on successful GET responses,
+// hashed from their body, and answering 304 Not Modified when the
+// request's If-None-Match matches it, so that clients revalidating a
+// cached response don't download it again. ETags set by the handler are
+// kept and compared instead.
+//
+// Responses are buffered up to 1MB, and streamed responses are left alone.
+// Place ETag after Compress, so that the ETag matches the bytes sent.
+func ETag(next http.Handler) http.Handler {
+	return ETagWithOpts(ETagOpts{})(next)
+}
+
+// ETagWithOpts is an ETag middleware using opts.
+func ETagWithOpts(opts ETagOpts) func(http.Handler) http.Handler {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 1 << 20
+	}
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &etagWriter{ResponseWriter: w, max: opts.MaxSize}
+			next.ServeHTTP(ew, r)
+			if ew.passthrough {
+				return
+			}
+
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				sum := sha256.Sum256(ew.buf.Bytes())
+				etag = `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+				if opts.Weak {
+					etag = "W/" + etag
+				}
+				w.Header().Set("ETag", etag)
+			}
+			if etagMatch(r.Header.Get("If-None-Match"), etag) {
+				h := w.Header()
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(ew.buf.Bytes())
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// etagMatch reports whether the If-None-Match header value matches etag,
+// using the weak comparison of RFC 9110.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers a successful response, or passes it through when it
+// isn't one, is too large or is flushed.
+type etagWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	max         int
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passthrough || code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	if code != http.StatusOK {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.max {
+		w.flushBuffer()
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// flushBuffer gives up on the ETag and sends what was buffered.
+func (w *etagWriter) flushBuffer() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
+
+func (w *etagWriter) Flush() {
+	w.flushBuffer()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	h := ETag(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, "not found", http.StatusNotFound)
+		case "/own":
+			w.Header().Set("ETag", `"v42"`)
+			w.Write([]byte("versioned"))
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello "))
+			w.Write([]byte("world"))
+		}
+	}))
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "hello world" || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("got %d %q, ETag %q", w.Code, w.Body.String(), etag)
+	}
+	if w := get("/", `"other", W/`+etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("expected 304 for a matching If-None-Match, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("/", `"other"`); w.Code != 200 || w.Body.String() != "hello world" {
+		t.Fatalf("expected 200 for another ETag, got %d", w.Code)
+	}
+	if w := get("/own", `"v42"`); w.Code != http.StatusNotModified || w.Header().Get("ETag") != `"v42"` {
+		t.Fatalf("expected the handler's ETag to be compared, got %d, headers %v", w.Code, w.Header())
+	}
+	if w := get("/missing", ""); w.Code != 404 || w.Header().Get("ETag") != "" {
+		t.Fatalf("expected errors to be passed through, got %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestETagMaxSize(t *testing.T) {
+	h := ETagWithOpts(ETagOpts{MaxSize: 4, Weak: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?body=abc", nil))
+	if !strings.HasPrefix(w.Header().Get("ETag"), `W/"`) {
+		t.Fatalf("expected a weak ETag, got %q", w.Header().Get("ETag"))
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?body=abcdef", nil))
+	if w.Code != 200 || w.Body.String() != "abcdef" || w.Header().Get("ETag") != "" {
+		t.Fatalf("expected large responses to be streamed without ETag, got %d %q, headers %v", w.Code, w.Body.String(), w.Header())
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// HeaderPolicy configures which response headers HeaderPolicyCheck expects.
+type HeaderPolicy struct {
+	// CacheControlOnGET requires a Cache-Control header on successful GET
+	// responses.
+	CacheControlOnGET bool
+
+	// ContentType requires a Content-Type header on responses with a body,
+	// and flags declared types that don't match the body, such as HTML
+	// served as application/json.
+	ContentType bool
+
+	// Required lists headers every response must carry, e.g. security
+	// headers like X-Content-Type-Options.
+	Required []string
+
+	// OnViolation is called once per response with all violations found.
+	// The default logs them with the standard logger.
+	OnViolation func(r *http.Request, violations []string)
+}
+
+// DefaultHeaderPolicy checks Cache-Control on GET, Content-Type consistency
+// and the common security headers.
+var DefaultHeaderPolicy = HeaderPolicy{
+	CacheControlOnGET: true,
+	ContentType:       true,
+	Required: []string{
+		"X-Content-Type-Options",
+		"X-Frame-Options",
+		"Referrer-Policy",
+	},
+}
+
+// sniffLen is the number of body bytes inspected, as http.DetectContentType.
+const sniffLen = 512
+
+// HeaderPolicyCheck is a development middleware that inspects every
+// response and reports headers that are missing or inconsistent according
+// to policy. It never alters the response; it only reports, so header
+// inconsistencies are caught before production. It buffers the first bytes
+// of each response body, so don't enable it in production.
+func HeaderPolicyCheck(policy HeaderPolicy) func(http.Handler) http.Handler {
+	report := policy.OnViolation
+	if report == nil {
+		report = func(r *http.Request, violations []string) {
+			log.Printf("header policy: %s %s: %s", r.Method, r.URL.Path, strings.Join(violations, "; "))
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := NewWrapResponseWriter(w, r.ProtoMajor)
+			sniff := &prefixBuffer{max: sniffLen}
+			ww.Tee(sniff)
+
+			next.ServeHTTP(ww, r)
+
+			if violations := policy.check(r, ww, sniff.Bytes()); len(violations) > 0 {
+				report(r, violations)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (p HeaderPolicy) check(r *http.Request, ww WrapResponseWriter, body []byte) []string {
+	var violations []string
+	h := ww.Header()
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if p.CacheControlOnGET && r.Method == http.MethodGet && status < 300 && h.Get("Cache-Control") == "" {
+		violations = append(violations, "missing Cache-Control on GET")
+	}
+
+	if p.ContentType && ww.BytesWritten() > 0 {
+		if ct := h.Get("Content-Type"); ct == "" {
+			violations = append(violations, "missing Content-Type")
+		} else if msg := contentTypeMismatch(ct, body); msg != "" {
+			violations = append(violations, msg)
+		}
+	}
+
+	for _, k := range p.Required {
+		if h.Get(k) == "" {
+			violations = append(violations, "missing "+http.CanonicalHeaderKey(k))
+		}
+	}
+	return violations
+}
+
+// contentTypeMismatch compares the declared content type with the body.
+func contentTypeMismatch(declared string, body []byte) string {
+	mt, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return "invalid Content-Type " + declared
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+
+	switch {
+	case strings.HasSuffix(mt, "json"):
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) > 0 && !strings.ContainsRune(`{["-0123456789tfn`, rune(trimmed[0])) {
+			return "Content-Type " + mt + " but body is not JSON"
+		}
+	case detected == "text/html" && mt != "text/html":
+		return "Content-Type " + mt + " but body looks like text/html"
+	case !strings.HasPrefix(detected, "text/") && detected != "application/octet-stream" && detected != mt:
+		return "Content-Type " + mt + " but body looks like " + detected
+	}
+	return ""
+}
+
+// prefixBuffer keeps the first max bytes written to it.
+type prefixBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *prefixBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
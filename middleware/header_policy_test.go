@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func TestHeaderPolicyCheck(t *testing.T) {
+	var got []string
+	policy := DefaultHeaderPolicy
+	policy.OnViolation = func(r *http.Request, violations []string) { got = violations }
+
+	r := owl.NewRouter()
+	r.Use(HeaderPolicyCheck(policy))
+	r.Get("/bad", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("<html><body>oops</body></html>"))
+	})
+	r.Get("/good", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/bad", nil))
+	want := []string{
+		"missing Cache-Control on GET",
+		"Content-Type application/json but body is not JSON",
+		"missing X-Content-Type-Options",
+		"missing X-Frame-Options",
+		"missing Referrer-Policy",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected violations:\n got: %q\nwant: %q", got, want)
+	}
+
+	got = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/good", nil))
+	if got != nil {
+		t.Fatalf("expected no violations, got %q", got)
+	}
+}
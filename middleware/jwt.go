@@ -0,0 +1,606 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// JWTConfig defines JWT configuration. One of Secret, Keyring, KeyFunc or
+// JWKSEndpoint must be set.
+type JWTConfig struct {
+	// Secret is the key of HMAC signed tokens (HS256, HS384, HS512).
+	Secret []byte
+
+	// Keyring holds the keys of HMAC signed tokens when they are rotated:
+	// tokens signed with the current key or a previous one still in its
+	// grace period are accepted. It takes precedence over Secret.
+	Keyring *owl.Keyring
+
+	// KeyFunc returns the key verifying a token, given its header: a
+	// []byte for HMAC, an *rsa.PublicKey, an *ecdsa.PublicKey or an
+	// ed25519.PublicKey. It takes precedence over Secret and JWKSEndpoint.
+	KeyFunc func(header JWTHeader) (interface{}, error)
+
+	// JWKSEndpoint is the URL of a JSON Web Key Set, such as
+	// "https://issuer.example.com/.well-known/jwks.json". Keys are picked
+	// by the "kid" of the token, cached, and fetched again every
+	// JWKSRefresh or when a token names an unknown key, at most once a
+	// minute.
+	JWKSEndpoint string
+
+	// JWKSRefresh is how long fetched keys are used (default: 1h).
+	JWKSRefresh time.Duration
+
+	// HTTPClient fetches the JWKS (default: a client with a 10s timeout).
+	HTTPClient *http.Client
+
+	// Claims returns a pointer to decode the token payload into, e.g.
+	//
+	//	Claims: func() interface{} { return &MyClaims{} },
+	//
+	// (default: a *map[string]interface{}). The result is stored on the
+	// request and returned by owl.Ctx.Claims and JWTClaims.
+	Claims func() interface{}
+
+	// TokenLookup lists where the token is read from, tried in order:
+	// "header:<name>" (with a "Bearer " prefix for Authorization),
+	// "query:<name>" or "cookie:<name>", separated by commas (default:
+	// "header:Authorization").
+	TokenLookup string
+
+	// Algorithms restricts the accepted "alg" values (default: all
+	// algorithms matching the key type; "none" is never accepted).
+	Algorithms []string
+
+	// Issuer and Audience, when set, must match the "iss" and "aud" claims.
+	Issuer   string
+	Audience string
+
+	// Leeway tolerates clock skew when checking "exp", "nbf" and "iat".
+	Leeway time.Duration
+
+	// ErrorHandler answers requests without a valid token (default: 401
+	// with a WWW-Authenticate header).
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// JWTHeader is the decoded header of a token.
+type JWTHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Errors reported to JWTConfig.ErrorHandler.
+var (
+	ErrJWTMissing = errors.New("jwt: missing token")
+	ErrJWTInvalid = errors.New("jwt: invalid token")
+	ErrJWTExpired = errors.New("jwt: token is expired")
+)
+
+// JWT is a middleware that authenticates requests with a JSON Web Token,
+// verifying its signature and registered claims, and stores its claims on
+// the request:
+//
+//	r.Use(middleware.JWT(middleware.JWTConfig{
+//		JWKSEndpoint: "https://auth.example.com/.well-known/jwks.json",
+//		Issuer:       "https://auth.example.com/",
+//		Audience:     "api",
+//		Claims:       func() interface{} { return &MyClaims{} },
+//	}))
+//
+//	claims := c.Claims().(*MyClaims)
+func JWT(config JWTConfig) func(http.Handler) http.Handler {
+	if config.Secret == nil && config.Keyring == nil && config.KeyFunc == nil && config.JWKSEndpoint == "" {
+		panic("chi/middleware: JWT needs one of Secret, Keyring, KeyFunc or JWKSEndpoint")
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = "header:Authorization"
+	}
+	if config.Claims == nil {
+		config.Claims = func() interface{} { return &map[string]interface{}{} }
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = jwtFailed
+	}
+	lookups := parseTokenLookup(config.TokenLookup)
+
+	var keys *jwks
+	if config.JWKSEndpoint != "" {
+		keys = &jwks{url: config.JWKSEndpoint, client: config.HTTPClient, refresh: config.JWKSRefresh}
+		if keys.client == nil {
+			keys.client = &http.Client{Timeout: 10 * time.Second}
+		}
+		if keys.refresh <= 0 {
+			keys.refresh = time.Hour
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			token := ""
+			for _, lookup := range lookups {
+				if token = lookup(r); token != "" {
+					break
+				}
+			}
+			if token == "" {
+				config.ErrorHandler(w, r, ErrJWTMissing)
+				return
+			}
+
+			claims, err := parseJWT(r.Context(), token, &config, keys)
+			if err != nil {
+				config.ErrorHandler(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), owl.ClaimsCtxKey, claims)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// JWTClaims returns the claims stored by JWT, or nil.
+func JWTClaims(ctx context.Context) interface{} {
+	return ctx.Value(owl.ClaimsCtxKey)
+}
+
+func jwtFailed(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrJWTMissing) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+func parseTokenLookup(spec string) []func(r *http.Request) string {
+	var lookups []func(r *http.Request) string
+	for _, part := range strings.Split(spec, ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || name == "" {
+			panic(fmt.Sprintf("chi/middleware: invalid JWT TokenLookup '%s'", part))
+		}
+		switch source {
+		case "header":
+			lookups = append(lookups, func(r *http.Request) string {
+				v := r.Header.Get(name)
+				if strings.EqualFold(name, "Authorization") {
+					if len(v) > 7 && strings.EqualFold(v[:7], "Bearer ") {
+						return strings.TrimSpace(v[7:])
+					}
+					return ""
+				}
+				return v
+			})
+		case "query":
+			lookups = append(lookups, func(r *http.Request) string {
+				return r.URL.Query().Get(name)
+			})
+		case "cookie":
+			lookups = append(lookups, func(r *http.Request) string {
+				if c, err := r.Cookie(name); err == nil {
+					return c.Value
+				}
+				return ""
+			})
+		default:
+			panic(fmt.Sprintf("chi/middleware: invalid JWT TokenLookup source '%s'", source))
+		}
+	}
+	return lookups
+}
+
+// parseJWT verifies token and returns its claims.
+func parseJWT(ctx context.Context, token string, config *JWTConfig, keys *jwks) (interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTInvalid
+	}
+	var header JWTHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrJWTInvalid
+	}
+	if header.Alg == "" || strings.EqualFold(header.Alg, "none") {
+		return nil, ErrJWTInvalid
+	}
+	if len(config.Algorithms) > 0 && !containsString(config.Algorithms, header.Alg) {
+		return nil, fmt.Errorf("%w: algorithm %s is not allowed", ErrJWTInvalid, header.Alg)
+	}
+
+	var candidates []interface{}
+	switch {
+	case config.KeyFunc != nil:
+		key, err := config.KeyFunc(header)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+		}
+		candidates = append(candidates, key)
+	case keys != nil:
+		key, err := keys.key(ctx, header)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+		}
+		candidates = append(candidates, key)
+	case config.Keyring != nil:
+		for _, key := range config.Keyring.Keys() {
+			candidates = append(candidates, key)
+		}
+	default:
+		candidates = append(candidates, config.Secret)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTInvalid
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	for _, key := range candidates {
+		if err = verifyJWT(header.Alg, key, signed, signature); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTInvalid
+	}
+	var registered struct {
+		Exp *float64        `json:"exp"`
+		Nbf *float64        `json:"nbf"`
+		Iat *float64        `json:"iat"`
+		Iss string          `json:"iss"`
+		Aud json.RawMessage `json:"aud"`
+	}
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, ErrJWTInvalid
+	}
+	now := float64(time.Now().Unix())
+	leeway := config.Leeway.Seconds()
+	if registered.Exp != nil && now > *registered.Exp+leeway {
+		return nil, ErrJWTExpired
+	}
+	if registered.Nbf != nil && now < *registered.Nbf-leeway {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrJWTInvalid)
+	}
+	if registered.Iat != nil && now < *registered.Iat-leeway {
+		return nil, fmt.Errorf("%w: token is issued in the future", ErrJWTInvalid)
+	}
+	if config.Issuer != "" && registered.Iss != config.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrJWTInvalid)
+	}
+	if config.Audience != "" && !audienceContains(registered.Aud, config.Audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrJWTInvalid)
+	}
+
+	claims := config.Claims()
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+	}
+	return claims, nil
+}
+
+// verifyJWT checks signature against signed, making sure alg matches the
+// type of key so that, for instance, a public RSA key can't be used as an
+// HMAC secret.
+func verifyJWT(alg string, key interface{}, signed, signature []byte) error {
+	var h crypto.Hash
+	switch {
+	case strings.HasSuffix(alg, "256"):
+		h = crypto.SHA256
+	case strings.HasSuffix(alg, "384"):
+		h = crypto.SHA384
+	case strings.HasSuffix(alg, "512"):
+		h = crypto.SHA512
+	}
+
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") || h == 0 {
+			break
+		}
+		if len(k) == 0 {
+			return errors.New("empty HMAC secret")
+		}
+		mac := hmac.New(hashFunc(h), k)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if h == 0 {
+			break
+		}
+		digest := digest(h, signed)
+		switch {
+		case strings.HasPrefix(alg, "RS"):
+			return rsa.VerifyPKCS1v15(k, h, digest, signature)
+		case strings.HasPrefix(alg, "PS"):
+			return rsa.VerifyPSS(k, h, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		// ES512 uses P-521.
+		bits := k.Curve.Params().BitSize
+		if !strings.HasPrefix(alg, "ES") || h == 0 || bits != map[crypto.Hash]int{crypto.SHA256: 256, crypto.SHA384: 384, crypto.SHA512: 521}[h] {
+			break
+		}
+		size := (bits + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("signature mismatch")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest(h, signed), r, s) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg != "EdDSA" {
+			break
+		}
+		if !ed25519.Verify(k, signed, signature) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %s does not match the key", alg)
+}
+
+func hashFunc(h crypto.Hash) func() hash.Hash {
+	switch h {
+	case crypto.SHA384:
+		return sha512.New384
+	case crypto.SHA512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+func digest(h crypto.Hash, b []byte) []byte {
+	d := hashFunc(h)()
+	d.Write(b)
+	return d.Sum(nil)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return one == audience
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		return containsString(many, audience)
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// jwks caches the keys of a JSON Web Key Set.
+type jwks struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]jwk
+	fetched time.Time
+	forced  time.Time
+	loading *jwksLoad // The fetch in progress, see load
+}
+
+// jwksLoad is a fetch of the key set shared by concurrent requests.
+type jwksLoad struct {
+	done chan struct{}
+	err  error
+}
+
+// jwksTimeout bounds a fetch of the key set, which outlives the request
+// that started it.
+const jwksTimeout = 10 * time.Second
+
+type jwk struct {
+	alg string
+	key interface{}
+}
+
+// key returns the key named by header.Kid, fetching the set again when it
+// is stale or, at most once a minute, when the key is unknown, as after a
+// key rotation by the issuer.
+func (s *jwks) key(ctx context.Context, header JWTHeader) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetched) > s.refresh {
+		if err := s.load(ctx); err != nil {
+			if s.keys == nil {
+				return nil, err
+			}
+			// Keep the stale keys and retry in a minute.
+			s.fetched = time.Now().Add(time.Minute - s.refresh)
+		}
+	}
+	k, ok := s.find(header.Kid)
+	if !ok && time.Since(s.forced) > time.Minute {
+		s.forced = time.Now()
+		if err := s.load(ctx); err != nil {
+			return nil, err
+		}
+		k, ok = s.find(header.Kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", header.Kid)
+	}
+	if k.alg != "" && k.alg != header.Alg {
+		return nil, fmt.Errorf("key %q is for %s", header.Kid, k.alg)
+	}
+	return k.key, nil
+}
+
+func (s *jwks) find(kid string) (jwk, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// load fetches the key set again, with s.mu held by the caller. The lock
+// is released during the fetch, which concurrent requests share and which
+// isn't canceled with the request that started it; ctx only bounds how
+// long the caller waits.
+func (s *jwks) load(ctx context.Context) error {
+	l := s.loading
+	if l == nil {
+		l = &jwksLoad{done: make(chan struct{})}
+		s.loading = l
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+			defer cancel()
+			keys, err := s.fetch(ctx)
+
+			s.mu.Lock()
+			if err == nil {
+				s.keys, s.fetched = keys, time.Now()
+			}
+			l.err = err
+			s.loading = nil
+			s.mu.Unlock()
+			close(l.done)
+		}()
+	}
+
+	s.mu.Unlock()
+	defer s.mu.Lock()
+	select {
+	case <-l.done:
+		return l.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch downloads the key set. Keys of unsupported types are skipped.
+func (s *jwks) fetch(ctx context.Context) (map[string]jwk, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: %s", res.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+
+	keys := make(map[string]jwk, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key interface{}
+		switch k.Kty {
+		case "RSA":
+			n, e := decodeBig(k.N), decodeBig(k.E)
+			if n != nil && e != nil && e.IsInt64() {
+				key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			}
+			x, y := decodeBig(k.X), decodeBig(k.Y)
+			if curve != nil && x != nil && y != nil {
+				key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+			}
+		case "OKP":
+			if x, err := base64.RawURLEncoding.DecodeString(k.X); err == nil && k.Crv == "Ed25519" && len(x) == ed25519.PublicKeySize {
+				key = ed25519.PublicKey(x)
+			}
+		case "oct":
+			if b, err := base64.RawURLEncoding.DecodeString(k.K); err == nil && len(b) > 0 {
+				key = b
+			}
+		}
+		if key != nil {
+			keys[k.Kid] = jwk{alg: k.Alg, key: key}
+		}
+	}
+	return keys, nil
+}
+
+func decodeBig(s string) *big.Int {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(bytes.TrimLeft(b, "\x00"))
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func signJWT(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func jwtRequest(h http.Handler, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestJWTSecret(t *testing.T) {
+	secret := []byte("s3cr3t")
+	type claims struct {
+		Sub  string `json:"sub"`
+		Role string `json:"role"`
+	}
+
+	app := owl.New()
+	app.Use(JWT(JWTConfig{
+		Secret:   secret,
+		Audience: "api",
+		Claims:   func() interface{} { return &claims{} },
+	}))
+	app.GET("/", func(c *owl.Ctx) error {
+		return c.Text(c.Claims().(*claims).Role)
+	})
+
+	exp := time.Now().Add(time.Hour).Unix()
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	valid := signJWT(t, hs256, map[string]interface{}{"sub": "1", "role": "admin", "aud": []string{"api"}, "exp": exp}, secret)
+
+	if w := jwtRequest(app, valid); w.Code != 200 || w.Body.String() != "admin" {
+		t.Fatalf("valid token: got %d %q", w.Code, w.Body.String())
+	}
+
+	tests := map[string]string{
+		"missing":      "",
+		"garbage":      "not.a.jwt",
+		"wrong secret": signJWT(t, hs256, map[string]interface{}{"aud": "api", "exp": exp}, []byte("other")),
+		"expired":      signJWT(t, hs256, map[string]interface{}{"aud": "api", "exp": time.Now().Add(-time.Minute).Unix()}, secret),
+		"audience":     signJWT(t, hs256, map[string]interface{}{"aud": "web", "exp": exp}, secret),
+		"none":         signJWT(t, map[string]interface{}{"alg": "none"}, map[string]interface{}{"aud": "api"}, []byte{}),
+	}
+	for name, token := range tests {
+		w := jwtRequest(app, token)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: got %d, WWW-Authenticate %q", name, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestJWTKeyFuncAndLookup(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	h := JWT(JWTConfig{
+		KeyFunc:     func(JWTHeader) (interface{}, error) { return &key.PublicKey, nil },
+		TokenLookup: "header:Authorization,cookie:jwt",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := *JWTClaims(r.Context()).(*map[string]interface{})
+		w.Write([]byte(claims["sub"].(string)))
+	}))
+
+	token := signJWT(t, map[string]interface{}{"alg": "ES256"}, map[string]interface{}{"sub": "42"}, key)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "42" {
+		t.Fatalf("cookie token: got %d %q", w.Code, w.Body.String())
+	}
+
+	// A token claiming HMAC must not be verified with the public key.
+	confused := signJWT(t, map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"sub": "42"}, []byte("x"))
+	if w := jwtRequest(h, confused); w.Code != http.StatusUnauthorized {
+		t.Fatalf("algorithm confusion: got %d", w.Code)
+	}
+}
+
+func TestJWTJWKS(t *testing.T) {
+	key1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	published := map[string]*rsa.PrivateKey{"k1": key1}
+	fetches := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		var keys []map[string]string
+		for kid, k := range published {
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "alg": "RS256", "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer srv.Close()
+
+	h := JWT(JWTConfig{JWKSEndpoint: srv.URL})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	token1 := signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "k1"}, map[string]interface{}{"sub": "1"}, key1)
+	for i := 0; i < 3; i++ {
+		if w := jwtRequest(h, token1); w.Code != 200 {
+			t.Fatalf("k1: got %d", w.Code)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("expected the JWKS to be cached, got %d fetches", fetches)
+	}
+
+	// The issuer rotates to k2: the unknown kid triggers a new fetch.
+	published["k2"] = key2
+	token2 := signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "k2"}, map[string]interface{}{"sub": "1"}, key2)
+	if w := jwtRequest(h, token2); w.Code != 200 {
+		t.Fatalf("k2: got %d", w.Code)
+	}
+	if fetches != 2 {
+		t.Fatalf("expected a refetch for the new key, got %d fetches", fetches)
+	}
+
+	// Unknown keys don't refetch more than once a minute.
+	forged := signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "k3"}, map[string]interface{}{"sub": "1"}, key2)
+	for i := 0; i < 3; i++ {
+		if w := jwtRequest(h, forged); w.Code != http.StatusUnauthorized {
+			t.Fatalf("k3: got %d", w.Code)
+		}
+	}
+	if fetches != 2 {
+		t.Fatalf("expected unknown keys to be rate limited, got %d fetches", fetches)
+	}
+}
+
+func TestJWTJWKSSharedFetch(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	h := JWT(JWTConfig{JWKSEndpoint: srv.URL})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	token := signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "k1"}, map[string]interface{}{"sub": "1"}, key)
+
+	// The request starting the fetch gives up, the others keep waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("canceled request: got %d", w.Code)
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, 4)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = jwtRequest(h, token).Code
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond) // Let the requests wait for the fetch
+	release <- struct{}{}
+	wg.Wait()
+	for i, code := range codes {
+		if code != 200 {
+			t.Fatalf("request %d: got %d", i, code)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected a single shared fetch, got %d", n)
+	}
+}
+
+func TestJWTKeyring(t *testing.T) {
+	keys := owl.NewKeyring(owl.KeyringOpts{}, []byte("k1"))
+	h := JWT(JWTConfig{Keyring: keys})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	hs256 := map[string]interface{}{"alg": "HS256"}
+	before := signJWT(t, hs256, map[string]interface{}{"sub": "1"}, []byte("k1"))
+	keys.Rotate([]byte("k2"))
+	after := signJWT(t, hs256, map[string]interface{}{"sub": "1"}, []byte("k2"))
+
+	for name, token := range map[string]string{"previous key": before, "current key": after} {
+		if w := jwtRequest(h, token); w.Code != 200 {
+			t.Errorf("%s: got %d", name, w.Code)
+		}
+	}
+	if w := jwtRequest(h, signJWT(t, hs256, map[string]interface{}{"sub": "1"}, []byte("k0"))); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key: got %d", w.Code)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"sync"
 	"time"
+
+	"github.com/go-owl/owl"
 )
 
 var (
@@ -26,8 +29,8 @@ var (
 // print in color, otherwise it will print in black and white. Logger prints a
 // request ID if one is provided.
 //
-// Alternatively, look at https://github.com/goware/httplog for a more in-depth
-// http logger with structured logging support.
+// Requests served by an owl.App configured with AppConfig.Logger are logged
+// as structured records to that logger instead, see SlogLogFormatter.
 //
 // IMPORTANT NOTE: Logger should go before any other middleware that may change
 // the response, such as middleware.Recoverer. Example:
@@ -45,11 +48,11 @@ func RequestLogger(f LogFormatter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			entry := f.NewLogEntry(r)
-			ww := NewWrapResponseWriter(w, r.ProtoMajor)
+			ww, state := trackResponse(w, r)
 
 			t1 := time.Now()
 			defer func() {
-				entry.Write(ww.Status(), ww.BytesWritten(), ww.Header(), time.Since(t1), nil)
+				entry.Write(state.Status(), state.BytesWritten(), ww.Header(), time.Since(t1), nil)
 			}()
 
 			next.ServeHTTP(ww, WithLogEntry(r, entry))
@@ -83,6 +86,16 @@ func WithLogEntry(r *http.Request, entry LogEntry) *http.Request {
 	return r
 }
 
+// AddLogField adds key=value to the access log line of the request, when
+// its LogEntry supports extra fields, like the one of DefaultLogFormatter.
+// Middlewares use it to link data to a request, e.g. the ID of a profile
+// captured by ProfileSampler.
+func AddLogField(r *http.Request, key, value string) {
+	if entry, ok := GetLogEntry(r).(interface{ AddField(key, value string) }); ok {
+		entry.AddField(key, value)
+	}
+}
+
 // LoggerInterface accepts printing to stdlib logger or compatible logger.
 type LoggerInterface interface {
 	Print(v ...interface{})
@@ -96,6 +109,9 @@ type DefaultLogFormatter struct {
 
 // NewLogEntry creates a new LogEntry for the request.
 func (l *DefaultLogFormatter) NewLogEntry(r *http.Request) LogEntry {
+	if logger := owl.LoggerFromContext(r.Context()); logger != nil {
+		return (&SlogLogFormatter{Logger: logger}).NewLogEntry(r)
+	}
 	useColor := !l.NoColor
 	entry := &defaultLogEntry{
 		DefaultLogFormatter: l,
@@ -129,9 +145,25 @@ type defaultLogEntry struct {
 	request  *http.Request
 	buf      *bytes.Buffer
 	useColor bool
+
+	mu     sync.Mutex
+	fields [][2]string // Added by AddLogField
+}
+
+// AddField adds key=value to the log line, see AddLogField.
+func (l *defaultLogEntry) AddField(key, value string) {
+	l.mu.Lock()
+	l.fields = append(l.fields, [2]string{key, value})
+	l.mu.Unlock()
 }
 
 func (l *defaultLogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	if rctx := owl.RouteContext(l.request.Context()); rctx != nil {
+		if level, ok := rctx.LogLevel(); ok && accessLogLevel(status) < level {
+			return
+		}
+	}
+
 	switch {
 	case status < 200:
 		cW(l.buf, l.useColor, bBlue, "%03d", status)
@@ -147,6 +179,17 @@ func (l *defaultLogEntry) Write(status, bytes int, header http.Header, elapsed t
 
 	cW(l.buf, l.useColor, bBlue, " %dB", bytes)
 
+	if rctx := owl.RouteContext(l.request.Context()); rctx != nil {
+		for _, attr := range rctx.RouteAttrs() {
+			cW(l.buf, l.useColor, nCyan, " %s=%s", attr.Key, attr.Value)
+		}
+	}
+	l.mu.Lock()
+	for _, field := range l.fields {
+		cW(l.buf, l.useColor, nCyan, " %s=%s", field[0], field[1])
+	}
+	l.mu.Unlock()
+
 	l.buf.WriteString(" in ")
 	if elapsed < 500*time.Millisecond {
 		cW(l.buf, l.useColor, nGreen, "%s", elapsed)
@@ -159,6 +202,19 @@ func (l *defaultLogEntry) Write(status, bytes int, header http.Header, elapsed t
 	l.Logger.Print(l.buf.String())
 }
 
+// accessLogLevel is the level of the access log line of a response with
+// status, compared with the level set by owl.App.SetLogLevel.
+func accessLogLevel(status int) owl.LogLevel {
+	switch {
+	case status >= 500:
+		return owl.LevelError
+	case status >= 400:
+		return owl.LevelWarn
+	default:
+		return owl.LevelInfo
+	}
+}
+
 func (l *defaultLogEntry) Panic(v interface{}, stack []byte) {
 	PrintPrettyStack(v)
 }
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// SlogLogFormatter is a LogFormatter writing access logs as structured
+// records to a *slog.Logger, with the method, path, status, bytes,
+// duration and request_id fields, plus route attributes and fields added
+// with AddLogField. DefaultLogFormatter switches to it for requests served
+// by an App configured with AppConfig.Logger.
+//
+//	r.Use(middleware.RequestLogger(&middleware.SlogLogFormatter{
+//		Logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+//	}))
+type SlogLogFormatter struct {
+	Logger *slog.Logger
+}
+
+// NewLogEntry creates a new LogEntry for the request.
+func (l *SlogLogFormatter) NewLogEntry(r *http.Request) LogEntry {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogEntry{logger: logger, request: r}
+}
+
+type slogLogEntry struct {
+	logger  *slog.Logger
+	request *http.Request
+
+	mu     sync.Mutex
+	fields []slog.Attr // Added by AddLogField
+}
+
+// AddField adds key=value to the log record, see AddLogField.
+func (l *slogLogEntry) AddField(key, value string) {
+	l.mu.Lock()
+	l.fields = append(l.fields, slog.String(key, value))
+	l.mu.Unlock()
+}
+
+func (l *slogLogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	level := accessLogLevel(status)
+	rctx := owl.RouteContext(l.request.Context())
+	if rctx != nil {
+		if min, ok := rctx.LogLevel(); ok && level < min {
+			return
+		}
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", l.request.Method),
+		slog.String("path", l.request.URL.Path),
+		slog.Int("status", status),
+		slog.Int("bytes", bytes),
+		slog.Duration("duration", elapsed),
+	}
+	if reqID := GetReqID(l.request.Context()); reqID != "" {
+		attrs = append(attrs, slog.String("request_id", reqID))
+	}
+	if rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			attrs = append(attrs, slog.String("route", pattern))
+		}
+		for _, attr := range rctx.RouteAttrs() {
+			attrs = append(attrs, slog.String(attr.Key, attr.Value))
+		}
+	}
+	l.mu.Lock()
+	attrs = append(attrs, l.fields...)
+	l.mu.Unlock()
+
+	l.logger.LogAttrs(l.request.Context(), slog.Level(level), "request", attrs...)
+}
+
+func (l *slogLogEntry) Panic(v interface{}, stack []byte) {
+	l.logger.LogAttrs(l.request.Context(), slog.LevelError, "panic",
+		slog.String("method", l.request.Method),
+		slog.String("path", l.request.URL.Path),
+		slog.Any("panic", v),
+		slog.String("stack", string(stack)),
+	)
+}
@@ -3,11 +3,17 @@ package middleware
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-owl/owl"
 )
 
 type testLoggerWriter struct {
@@ -49,3 +55,66 @@ func TestRequestLoggerReadFrom(t *testing.T) {
 
 	assertEqual(t, data, w.Body.Bytes())
 }
+
+func TestRequestLoggerRouteAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	app := owl.New()
+	app.Use(RequestLogger(&DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true}))
+	app.Group("/billing").Attr("team", "payments").GET("/invoices", func(c *owl.Ctx) error {
+		return c.Text("ok")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/billing/invoices", nil))
+	if !strings.Contains(buf.String(), " team=payments") {
+		t.Fatalf("expected route attributes in log line, got %q", buf.String())
+	}
+}
+
+func TestRequestLoggerLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	app := owl.New()
+	app.Use(RequestLogger(&DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true}))
+	app.GET("/ok", func(c *owl.Ctx) error { return c.Text("ok") })
+	app.GET("/users/{id}", func(c *owl.Ctx) error { return owl.ErrNotFound })
+
+	app.SetLogLevel(owl.LevelWarn)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	if strings.Contains(buf.String(), "/ok") || !strings.Contains(buf.String(), "/users/1") {
+		t.Fatalf("expected only the 404 to be logged at warn level, got %q", buf.String())
+	}
+
+	buf.Reset()
+	app.SetLogLevel(owl.LevelError)
+	app.SetRouteLogLevel("/ok", owl.LevelDebug)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	if !strings.Contains(buf.String(), "/ok") || strings.Contains(buf.String(), "/users/1") {
+		t.Fatalf("expected the route override to apply, got %q", buf.String())
+	}
+}
+
+func TestLoggerSlog(t *testing.T) {
+	var buf bytes.Buffer
+	app := owl.New(owl.AppConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+	app.Use(RequestID)
+	app.Use(Logger)
+	app.GET("/users/{id}", func(c *owl.Ctx) error {
+		return owl.NewHTTPError(http.StatusNotFound, "not found")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/42", nil))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON log record, got %q: %v", buf.String(), err)
+	}
+	if record["level"] != "WARN" || record["method"] != "GET" || record["path"] != "/users/42" ||
+		record["status"] != float64(404) || record["route"] != "/users/{id}" || record["request_id"] == nil {
+		t.Fatalf("unexpected log record %v", record)
+	}
+	if _, ok := record["duration"]; !ok {
+		t.Fatalf("expected a duration field, got %v", record)
+	}
+}
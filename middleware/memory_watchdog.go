@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryWatchdogOpts represents a set of memory watchdog options.
+type MemoryWatchdogOpts struct {
+	// Threshold is the heap size in bytes above which new requests are
+	// shed. Required.
+	Threshold uint64
+
+	// Interval is how often the heap size is sampled (default: 1s).
+	// Sampling happens lazily on incoming requests, no goroutine is
+	// started.
+	Interval time.Duration
+
+	// StatusCode is sent to shed requests (default: 503).
+	StatusCode int
+
+	// RetryAfter, when set, is sent as the Retry-After header to shed
+	// requests.
+	RetryAfter time.Duration
+
+	// TopN is the number of in-flight routes logged when the threshold is
+	// crossed (default: 5).
+	TopN int
+
+	// Logger receives the watchdog reports (default: the standard logger).
+	Logger LoggerInterface
+
+	// HeapFn reports the current heap size in bytes (default: live and
+	// unswept heap objects, as reported by runtime/metrics).
+	HeapFn func() uint64
+}
+
+// MemoryWatchdog is a middleware that sheds load once the heap grows past
+// opts.Threshold, giving the process a chance to recover before it gets
+// OOM-killed. Requests already in flight are left alone; new ones are
+// rejected until the heap shrinks below the threshold again.
+//
+// When shedding starts, the requests in flight are logged grouped by method
+// and path, ordered by count and declared body size, which usually points
+// straight at the runaway endpoint:
+//
+//	r.Use(middleware.MemoryWatchdog(middleware.MemoryWatchdogOpts{
+//		Threshold: 1 << 30, // 1GB
+//	}))
+func MemoryWatchdog(opts MemoryWatchdogOpts) func(http.Handler) http.Handler {
+	if opts.Threshold == 0 {
+		panic("chi/middleware: MemoryWatchdog expects a threshold > 0")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.StatusCode == 0 {
+		opts.StatusCode = http.StatusServiceUnavailable
+	}
+	if opts.TopN <= 0 {
+		opts.TopN = 5
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	if opts.HeapFn == nil {
+		opts.HeapFn = heapObjectBytes
+	}
+
+	wd := &memoryWatchdog{opts: opts, inflight: map[*http.Request]inflightRequest{}}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if wd.overloaded() {
+				if opts.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(opts.RetryAfter.Seconds())))
+				}
+				http.Error(w, http.StatusText(opts.StatusCode), opts.StatusCode)
+				return
+			}
+
+			wd.track(r)
+			defer wd.untrack(r)
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+type inflightRequest struct {
+	route string
+	size  int64
+	start time.Time
+}
+
+type memoryWatchdog struct {
+	opts       MemoryWatchdogOpts
+	lastSample atomic.Int64 // unix nanoseconds
+	shedding   atomic.Bool
+
+	mu       sync.Mutex
+	inflight map[*http.Request]inflightRequest
+}
+
+// overloaded samples the heap at most once per interval and reports whether
+// requests should be shed.
+func (wd *memoryWatchdog) overloaded() bool {
+	now := time.Now().UnixNano()
+	last := wd.lastSample.Load()
+	if now-last < int64(wd.opts.Interval) || !wd.lastSample.CompareAndSwap(last, now) {
+		return wd.shedding.Load()
+	}
+
+	heap := wd.opts.HeapFn()
+	over := heap > wd.opts.Threshold
+	if wd.shedding.Swap(over) != over {
+		if over {
+			wd.opts.Logger.Print(fmt.Sprintf("memory watchdog: heap %d bytes over threshold %d, shedding load; in flight: %s",
+				heap, wd.opts.Threshold, wd.report()))
+		} else {
+			wd.opts.Logger.Print(fmt.Sprintf("memory watchdog: heap %d bytes back under threshold %d, resuming", heap, wd.opts.Threshold))
+		}
+	}
+	return over
+}
+
+func (wd *memoryWatchdog) track(r *http.Request) {
+	wd.mu.Lock()
+	// Group by route pattern, since paths with params would each be
+	// reported apart and bloat the report.
+	route := routePatternOf(r)
+	if route == "" {
+		route = "unmatched"
+	}
+	wd.inflight[r] = inflightRequest{
+		route: r.Method + " " + route,
+		size:  max(r.ContentLength, 0),
+		start: time.Now(),
+	}
+	wd.mu.Unlock()
+}
+
+func (wd *memoryWatchdog) untrack(r *http.Request) {
+	wd.mu.Lock()
+	delete(wd.inflight, r)
+	wd.mu.Unlock()
+}
+
+// report summarizes the top in-flight routes by count, then body size.
+func (wd *memoryWatchdog) report() string {
+	type routeStats struct {
+		route  string
+		count  int
+		size   int64
+		oldest time.Time
+	}
+
+	wd.mu.Lock()
+	byRoute := map[string]*routeStats{}
+	for _, req := range wd.inflight {
+		s := byRoute[req.route]
+		if s == nil {
+			s = &routeStats{route: req.route, oldest: req.start}
+			byRoute[req.route] = s
+		}
+		s.count++
+		s.size += req.size
+		if req.start.Before(s.oldest) {
+			s.oldest = req.start
+		}
+	}
+	wd.mu.Unlock()
+
+	if len(byRoute) == 0 {
+		return "none"
+	}
+	stats := make([]*routeStats, 0, len(byRoute))
+	for _, s := range byRoute {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].count != stats[j].count {
+			return stats[i].count > stats[j].count
+		}
+		if stats[i].size != stats[j].size {
+			return stats[i].size > stats[j].size
+		}
+		return stats[i].route < stats[j].route
+	})
+	if len(stats) > wd.opts.TopN {
+		stats = stats[:wd.opts.TopN]
+	}
+
+	parts := make([]string, len(stats))
+	for i, s := range stats {
+		parts[i] = fmt.Sprintf("%s (count=%d bytes=%d oldest=%s)", s.route, s.count, s.size, time.Since(s.oldest).Round(time.Millisecond))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// heapObjectBytes reads the heap size without stopping the world, unlike
+// runtime.ReadMemStats.
+func heapObjectBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
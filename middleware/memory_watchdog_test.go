@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Print(v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(v...))
+}
+
+func TestMemoryWatchdog(t *testing.T) {
+	var heap atomic.Uint64
+	logger := &testLogger{}
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+
+	r := owl.NewRouter()
+	r.Use(MemoryWatchdog(MemoryWatchdogOpts{
+		Threshold:  100,
+		Interval:   time.Nanosecond,
+		RetryAfter: 5 * time.Second,
+		Logger:     logger,
+		HeapFn:     heap.Load,
+	}))
+	r.Post("/upload/{id}", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", fmt.Sprintf("/upload/%d", i), strings.NewReader("payload")))
+		}(i)
+		<-started
+	}
+
+	heap.Store(200)
+	time.Sleep(time.Millisecond)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" {
+		t.Fatalf("expected request to be shed, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	heap.Store(50)
+	time.Sleep(time.Millisecond)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected recovery after heap shrank, got %d", w.Code)
+	}
+
+	if len(logger.lines) != 2 {
+		t.Fatalf("expected shedding and recovery logs, got %q", logger.lines)
+	}
+	if !strings.Contains(logger.lines[0], "POST /upload/{id} (count=3 bytes=21") {
+		t.Errorf("expected in-flight report, got %q", logger.lines[0])
+	}
+}
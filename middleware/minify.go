@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Minifier minifies content of a media type. Its signature matches the
+// Minify method of github.com/tdewolff/minify, which can be plugged in as
+// is for HTML, CSS and JS.
+type Minifier interface {
+	Minify(mediaType string, w io.Writer, r io.Reader) error
+}
+
+// MinifierFunc adapts a function to a Minifier.
+type MinifierFunc func(mediaType string, w io.Writer, r io.Reader) error
+
+// Minify implements Minifier.
+func (f MinifierFunc) Minify(mediaType string, w io.Writer, r io.Reader) error {
+	return f(mediaType, w, r)
+}
+
+// MinifyOpts configures the MinifyWithOpts middleware.
+type MinifyOpts struct {
+	// Minifier minifies the Types (default: the built-in one, which
+	// compacts JSON and collapses the whitespace of HTML outside pre,
+	// textarea, script and style elements, and leaves other types as is).
+	Minifier Minifier
+
+	// Types are the media types minified (default: text/html, text/css,
+	// text/javascript, application/javascript and application/json).
+	Types []string
+
+	// MaxSize caps the buffered response; larger responses are sent as is
+	// (default: 1MB).
+	MaxSize int
+}
+
+// Minify is a middleware minifying HTML and compacting JSON responses with
+// the built-in minifier, for server-rendered pages without a frontend
+// build step. Use MinifyWithOpts to plug in a full minifier for CSS and JS.
+// Encoded responses are sent as is, so place Minify after Compress.
+func Minify(next http.Handler) http.Handler {
+	return MinifyWithOpts(MinifyOpts{})(next)
+}
+
+// MinifyWithOpts is a Minify middleware using opts:
+//
+//	m := minify.New()
+//	m.AddFunc("text/css", css.Minify)
+//	m.AddFunc("text/html", html.Minify)
+//	r.Use(middleware.MinifyWithOpts(middleware.MinifyOpts{
+//		Minifier: m,
+//		Types:    []string{"text/html", "text/css"},
+//	}))
+func MinifyWithOpts(opts MinifyOpts) func(http.Handler) http.Handler {
+	if opts.Minifier == nil {
+		opts.Minifier = MinifierFunc(builtinMinify)
+	}
+	if opts.Types == nil {
+		opts.Types = []string{"text/html", "text/css", "text/javascript", "application/javascript", "application/json"}
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 1 << 20
+	}
+	types := make(map[string]bool, len(opts.Types))
+	for _, t := range opts.Types {
+		types[strings.ToLower(t)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			mw := &minifyWriter{ResponseWriter: w, types: types, max: opts.MaxSize}
+			next.ServeHTTP(mw, r)
+			if mw.passthrough {
+				return
+			}
+			if mw.status == 0 {
+				// Nothing was written.
+				return
+			}
+
+			var out bytes.Buffer
+			if err := opts.Minifier.Minify(mw.mediaType, &out, bytes.NewReader(mw.buf.Bytes())); err != nil {
+				out.Reset()
+				out.Write(mw.buf.Bytes())
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(out.Len()))
+			w.WriteHeader(mw.status)
+			if r.Method != http.MethodHead {
+				w.Write(out.Bytes())
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// minifyWriter buffers responses of the minified types, or passes others
+// through.
+type minifyWriter struct {
+	http.ResponseWriter
+	types       map[string]bool
+	buf         bytes.Buffer
+	max         int
+	status      int
+	mediaType   string
+	passthrough bool
+}
+
+func (w *minifyWriter) WriteHeader(code int) {
+	if w.passthrough || code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	h := w.Header()
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if !w.types[mediaType] || h.Get("Content-Encoding") != "" || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.mediaType = mediaType
+	h.Del("Content-Length")
+}
+
+func (w *minifyWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.max {
+		w.flushBuffer()
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// flushBuffer gives up on minifying and sends what was buffered.
+func (w *minifyWriter) flushBuffer() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
+
+func (w *minifyWriter) Flush() {
+	w.flushBuffer()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *minifyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var (
+	// htmlRaw matches the elements whose whitespace is kept.
+	htmlRaw = regexp.MustCompile(`(?is)<(pre|textarea|script|style)\b.*?</(pre|textarea|script|style)\s*>`)
+	// htmlSpace matches whitespace runs.
+	htmlSpace = regexp.MustCompile(`\s+`)
+	// htmlBetweenTags matches whitespace between tags, which is collapsed
+	// to nothing when it contains a line break.
+	htmlBetweenTags = regexp.MustCompile(`>\s*\n\s*<`)
+)
+
+// builtinMinify is the default Minifier.
+func builtinMinify(mediaType string, w io.Writer, r io.Reader) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var out bytes.Buffer
+		if err := json.Compact(&out, src); err != nil {
+			return err
+		}
+		_, err = w.Write(out.Bytes())
+		return err
+	case mediaType == "text/html":
+		_, err = w.Write(minifyHTML(src))
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// minifyHTML drops the line breaks between tags and collapses whitespace
+// runs to one space, except in pre, textarea, script and style elements.
+func minifyHTML(src []byte) []byte {
+	// Raw elements are set aside behind placeholder tags.
+	var raw [][]byte
+	src = htmlRaw.ReplaceAllFunc(src, func(m []byte) []byte {
+		raw = append(raw, m)
+		return []byte("<\x00" + strconv.Itoa(len(raw)-1) + ">")
+	})
+	src = htmlBetweenTags.ReplaceAll(src, []byte("><"))
+	src = bytes.TrimSpace(htmlSpace.ReplaceAll(src, []byte(" ")))
+	for i, m := range raw {
+		src = bytes.Replace(src, []byte("<\x00"+strconv.Itoa(i)+">"), m, 1)
+	}
+	return src
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	h := Minify(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{\n  \"name\": \"owl\",\n  \"tags\": [1, 2]\n}\n"))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("keep   this\n\n"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>\n  <body>\n    <p>Hello,   <b>world</b>\n    </p>\n"))
+			w.Write([]byte("    <pre>  keep\n  this</pre>\n  </body>\n</html>\n"))
+		}
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := get("/"); w.Body.String() != "<html><body><p>Hello, <b>world</b></p><pre>  keep\n  this</pre></body></html>" {
+		t.Fatalf("unexpected HTML %q", w.Body.String())
+	}
+	if w := get("/json"); w.Body.String() != `{"name":"owl","tags":[1,2]}` || w.Header().Get("Content-Length") != "27" {
+		t.Fatalf("unexpected JSON %q, headers %v", w.Body.String(), w.Header())
+	}
+	if w := get("/text"); w.Body.String() != "keep   this\n\n" {
+		t.Fatalf("expected other types to be left alone, got %q", w.Body.String())
+	}
+}
+
+func TestMinifyWithOpts(t *testing.T) {
+	upper := MinifierFunc(func(mediaType string, w io.Writer, r io.Reader) error {
+		b, _ := io.ReadAll(r)
+		_, err := w.Write([]byte(mediaType + ":" + strings.ReplaceAll(string(b), " ", "")))
+		return err
+	})
+	h := MinifyWithOpts(MinifyOpts{Minifier: upper, Types: []string{"text/css"}, MaxSize: 16})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		w.Write([]byte(r.URL.Query().Get("css")))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?css=a+%7B+b+%7D", nil))
+	if w.Body.String() != "text/css:a{b}" {
+		t.Fatalf("expected the pluggable minifier to be used, got %q", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?css=a+%7B+color%3A+red+%7D+b+%7B%7D", nil))
+	if w.Body.String() != "a { color: red } b {}" {
+		t.Fatalf("expected large responses to be sent as is, got %q", w.Body.String())
+	}
+}
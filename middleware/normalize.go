@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DuplicateQueryPolicy tells Normalize what to do with query keys sent more
+// than once.
+type DuplicateQueryPolicy int
+
+const (
+	// DuplicateQueryKeep leaves repeated query keys as they are.
+	DuplicateQueryKeep DuplicateQueryPolicy = iota
+
+	// DuplicateQueryFirst keeps the first value of repeated query keys.
+	DuplicateQueryFirst
+
+	// DuplicateQueryLast keeps the last value of repeated query keys.
+	DuplicateQueryLast
+
+	// DuplicateQueryReject answers requests with repeated query keys with
+	// 400 Bad Request.
+	DuplicateQueryReject
+)
+
+// NormalizeOpts represents a set of request normalization options.
+type NormalizeOpts struct {
+	// MaxHeaders is the max number of header values a request may carry
+	// (default: 0, no limit).
+	MaxHeaders int
+
+	// MaxHeaderBytes is the max total size of header names and values
+	// (default: 0, no limit).
+	MaxHeaderBytes int
+
+	// DuplicateQuery sets how repeated query keys are handled (default:
+	// DuplicateQueryKeep).
+	DuplicateQuery DuplicateQueryPolicy
+
+	// MultiValueKeys lists query keys allowed to repeat regardless of
+	// DuplicateQuery, e.g. "tags" for ?tags=a&tags=b.
+	MultiValueKeys []string
+}
+
+// Normalize is a middleware that cleans up request headers and query
+// parameters at the edge, before routing and binding run. It:
+//
+//   - rejects header and query names or values with control characters,
+//   - trims surrounding whitespace from header and query values,
+//   - applies opts.DuplicateQuery to repeated query keys,
+//   - enforces opts.MaxHeaders and opts.MaxHeaderBytes.
+//
+// Rejected requests get a 400 Bad Request naming the offending field.
+func Normalize(opts NormalizeOpts) func(http.Handler) http.Handler {
+	multi := make(map[string]bool, len(opts.MultiValueKeys))
+	for _, k := range opts.MultiValueKeys {
+		multi[k] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if msg := normalizeHeaders(r.Header, opts); msg != "" {
+				http.Error(w, msg, http.StatusBadRequest)
+				return
+			}
+			if r.URL.RawQuery != "" {
+				query, msg := normalizeQuery(r.URL.RawQuery, opts.DuplicateQuery, multi)
+				if msg != "" {
+					http.Error(w, msg, http.StatusBadRequest)
+					return
+				}
+				r.URL.RawQuery = query
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// normalizeHeaders trims header values in place and returns a message
+// describing the first violation, if any.
+func normalizeHeaders(h http.Header, opts NormalizeOpts) string {
+	count, size := 0, 0
+	for name, values := range h {
+		if hasControlChar(name) {
+			return "invalid header name " + strconv.Quote(name)
+		}
+		for i, v := range values {
+			if hasControlChar(v) {
+				return "invalid header " + name + ": control character in value"
+			}
+			values[i] = strings.TrimSpace(v)
+			count++
+			size += len(name) + len(v)
+		}
+	}
+	if opts.MaxHeaders > 0 && count > opts.MaxHeaders {
+		return "too many headers: " + strconv.Itoa(count) + " > " + strconv.Itoa(opts.MaxHeaders)
+	}
+	if opts.MaxHeaderBytes > 0 && size > opts.MaxHeaderBytes {
+		return "headers too large: " + strconv.Itoa(size) + " > " + strconv.Itoa(opts.MaxHeaderBytes) + " bytes"
+	}
+	return ""
+}
+
+// normalizeQuery returns the cleaned raw query, or a message describing the
+// first violation. The query is only re-encoded when something changed.
+func normalizeQuery(raw string, policy DuplicateQueryPolicy, multi map[string]bool) (string, string) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "", "invalid query: " + err.Error()
+	}
+
+	changed := false
+	for key, vals := range values {
+		if hasControlChar(key) {
+			return "", "invalid query key " + strconv.Quote(key)
+		}
+		for i, v := range vals {
+			if hasControlChar(v) {
+				return "", "invalid query " + key + ": control character in value"
+			}
+			if t := strings.TrimSpace(v); t != v {
+				vals[i], changed = t, true
+			}
+		}
+		if len(vals) < 2 || multi[key] {
+			continue
+		}
+		switch policy {
+		case DuplicateQueryFirst:
+			values[key], changed = vals[:1], true
+		case DuplicateQueryLast:
+			values[key], changed = vals[len(vals)-1:], true
+		case DuplicateQueryReject:
+			return "", "duplicate query key " + strconv.Quote(key)
+		}
+	}
+
+	if !changed {
+		return raw, ""
+	}
+	return values.Encode(), ""
+}
+
+// hasControlChar reports whether s contains an ASCII control character
+// other than horizontal tab.
+func hasControlChar(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < 0x20 && c != '\t') || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
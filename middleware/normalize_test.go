@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func TestNormalize(t *testing.T) {
+	r := owl.NewRouter()
+	r.Use(Normalize(NormalizeOpts{
+		MaxHeaders:     4,
+		MaxHeaderBytes: 64,
+		DuplicateQuery: DuplicateQueryLast,
+		MultiValueKeys: []string{"tag"},
+	}))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RawQuery + "|" + r.Header.Get("X-Name")))
+	})
+
+	tests := []struct {
+		name    string
+		query   string
+		headers map[string]string
+		code    int
+		body    string
+	}{
+		{"clean", "a=1", nil, 200, "a=1|"},
+		{"trim", "a=+1+&b=2", map[string]string{"X-Name": " owl\t"}, 200, "a=1&b=2|owl"},
+		{"duplicates", "page=1&page=2&tag=x&tag=y", nil, 200, "page=2&tag=x&tag=y|"},
+		{"control in query", "a=%01", nil, 400, "invalid query a: control character in value\n"},
+		{"control in header", "", map[string]string{"X-Name": "o\x00wl"}, 400, "invalid header X-Name: control character in value\n"},
+		{"too many headers", "", map[string]string{"A": "1", "B": "2", "C": "3", "D": "4", "E": "5"}, 400, "too many headers: 5 > 4\n"},
+		{"headers too large", "", map[string]string{"X-Name": strings.Repeat("x", 80)}, 400, "headers too large: 86 > 64 bytes\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			req.Header = http.Header{}
+			for k, v := range tt.headers {
+				req.Header[k] = []string{v}
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.code || w.Body.String() != tt.body {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.code, tt.body)
+			}
+		})
+	}
+
+	reject := owl.NewRouter()
+	reject.Use(Normalize(NormalizeOpts{DuplicateQuery: DuplicateQueryReject}))
+	reject.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	w := httptest.NewRecorder()
+	reject.ServeHTTP(w, httptest.NewRequest("GET", "/?id=1&id=2", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected duplicate key rejection, got %d", w.Code)
+	}
+}
@@ -0,0 +1,210 @@
+//go:build !tinygo
+// +build !tinygo
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	mrand "math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// ProfileKind is the kind of profile captured by ProfileSampler.
+type ProfileKind int
+
+// Profile kinds.
+const (
+	// ProfileCPU captures a CPU profile while the request is served.
+	ProfileCPU ProfileKind = iota
+	// ProfileHeap captures a heap profile when the request completes.
+	ProfileHeap
+	// ProfileTrace captures an execution trace while the request is
+	// served.
+	ProfileTrace
+)
+
+func (k ProfileKind) String() string {
+	switch k {
+	case ProfileHeap:
+		return "heap"
+	case ProfileTrace:
+		return "trace"
+	default:
+		return "cpu"
+	}
+}
+
+// Profile is a profile of a slow request, handed to a ProfileSink.
+type Profile struct {
+	ID       string
+	Kind     ProfileKind
+	Method   string
+	Path     string
+	Route    string // Route pattern, when known
+	Start    time.Time
+	Duration time.Duration
+	Data     []byte // pprof or execution trace data
+}
+
+// ProfileSink stores captured profiles.
+type ProfileSink interface {
+	Store(ctx context.Context, p Profile) error
+}
+
+// ProfileSinkFunc adapts a function to the ProfileSink interface.
+type ProfileSinkFunc func(ctx context.Context, p Profile) error
+
+// Store implements ProfileSink.
+func (f ProfileSinkFunc) Store(ctx context.Context, p Profile) error {
+	return f(ctx, p)
+}
+
+// DirProfileSink writes profiles to dir, named after their ID, e.g.
+// "9f86d081884c7d65.cpu.pprof", for use with go tool pprof or go tool
+// trace.
+func DirProfileSink(dir string) ProfileSink {
+	return ProfileSinkFunc(func(ctx context.Context, p Profile) error {
+		ext := ".pprof"
+		if p.Kind == ProfileTrace {
+			ext = ".trace"
+		}
+		return os.WriteFile(filepath.Join(dir, p.ID+"."+p.Kind.String()+ext), p.Data, 0o644)
+	})
+}
+
+// ProfileSamplerOpts configures the ProfileSampler middleware.
+type ProfileSamplerOpts struct {
+	// Threshold is the latency above which a request's profile is kept.
+	Threshold time.Duration
+
+	// Rate is the fraction of requests, between 0 and 1, that are
+	// profiled (default: 0.01).
+	Rate float64
+
+	// Kind is the kind of profile captured (default: ProfileCPU).
+	Kind ProfileKind
+
+	// Sink stores the profiles of slow requests. It is called in its own
+	// goroutine, after the response was sent.
+	Sink ProfileSink
+
+	// Logger reports sink errors (default: the standard logger).
+	Logger LoggerInterface
+
+	// Rand returns a pseudo-random number in [0, 1). Default: math/rand.
+	Rand func() float64
+}
+
+// ProfileSampler is a middleware capturing profiles of a sample of the
+// requests and keeping those of requests slower than opts.Threshold, to
+// find out where slow requests spend their time in production:
+//
+//	r.Use(middleware.Logger)
+//	r.Use(middleware.ProfileSampler(middleware.ProfileSamplerOpts{
+//		Threshold: 500 * time.Millisecond,
+//		Rate:      0.01,
+//		Sink:      middleware.DirProfileSink("/var/lib/app/profiles"),
+//	}))
+//
+// The ID of a kept profile is added to the access log line of the request
+// as "profile=<id>".
+//
+// CPU profiles and execution traces cover the whole process while the
+// request is served, so they also show concurrent requests. The Go runtime
+// only supports one of them at a time: requests are not profiled while
+// another capture, including one started through net/http/pprof, runs.
+func ProfileSampler(opts ProfileSamplerOpts) func(http.Handler) http.Handler {
+	if opts.Sink == nil {
+		panic("chi/middleware: ProfileSampler needs a Sink")
+	}
+	if opts.Rate <= 0 {
+		opts.Rate = 0.01
+	}
+	if opts.Rand == nil {
+		opts.Rand = mrand.Float64
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	var busy atomic.Bool
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if opts.Rand() >= opts.Rate || !busy.CompareAndSwap(false, true) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			defer busy.Store(false)
+
+			var buf bytes.Buffer
+			stop := func() {}
+			switch opts.Kind {
+			case ProfileCPU:
+				if err := pprof.StartCPUProfile(&buf); err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				stop = pprof.StopCPUProfile
+			case ProfileTrace:
+				if err := trace.Start(&buf); err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				stop = trace.Stop
+			}
+
+			start := time.Now()
+			func() {
+				defer stop()
+				next.ServeHTTP(w, r)
+			}()
+			elapsed := time.Since(start)
+			if elapsed < opts.Threshold {
+				return
+			}
+			if opts.Kind == ProfileHeap {
+				if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+					return
+				}
+			}
+
+			p := Profile{
+				ID:       profileID(),
+				Kind:     opts.Kind,
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				Start:    start,
+				Duration: elapsed,
+				Data:     buf.Bytes(),
+			}
+			if rctx := owl.RouteContext(r.Context()); rctx != nil {
+				p.Route = rctx.RoutePattern()
+			}
+			AddLogField(r, "profile", p.ID)
+			go func() {
+				if err := opts.Sink.Store(context.Background(), p); err != nil {
+					opts.Logger.Print("chi/middleware: storing profile " + p.ID + ": " + err.Error())
+				}
+			}()
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func profileID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,78 @@
+//go:build !tinygo
+// +build !tinygo
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestProfileSampler(t *testing.T) {
+	stored := make(chan Profile, 1)
+	var buf bytes.Buffer
+
+	app := owl.New()
+	app.Use(RequestLogger(&DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true}))
+	app.Use(ProfileSampler(ProfileSamplerOpts{
+		Threshold: 20 * time.Millisecond,
+		Rate:      1,
+		Kind:      ProfileHeap,
+		Sink: ProfileSinkFunc(func(ctx context.Context, p Profile) error {
+			stored <- p
+			return nil
+		}),
+	}))
+	app.GET("/fast", func(c *owl.Ctx) error { return c.Text("ok") })
+	app.GET("/slow/{id}", func(c *owl.Ctx) error {
+		time.Sleep(30 * time.Millisecond)
+		return c.Text("ok")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fast", nil))
+	select {
+	case p := <-stored:
+		t.Fatalf("expected fast requests not to be stored, got %s", p.Path)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow/1", nil))
+	var p Profile
+	select {
+	case p = <-stored:
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow request to be stored")
+	}
+	if p.Route != "/slow/{id}" || p.Kind != ProfileHeap || len(p.Data) == 0 || p.Duration < 20*time.Millisecond {
+		t.Fatalf("unexpected profile: route %q, kind %s, %d bytes, %s", p.Route, p.Kind, len(p.Data), p.Duration)
+	}
+	if !strings.Contains(buf.String(), " profile="+p.ID) {
+		t.Fatalf("expected the profile ID in the access log, got %q", buf.String())
+	}
+}
+
+func TestProfileSamplerRate(t *testing.T) {
+	sampled := 0
+	h := ProfileSampler(ProfileSamplerOpts{
+		Rate: 0.5,
+		Kind: ProfileHeap,
+		Rand: func() float64 { return 0.7 },
+		Sink: ProfileSinkFunc(func(ctx context.Context, p Profile) error {
+			sampled++
+			return nil
+		}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	time.Sleep(20 * time.Millisecond)
+	if sampled != 0 {
+		t.Fatal("expected requests outside of the rate not to be profiled")
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// QueueOpts configures the Queue middleware.
+type QueueOpts struct {
+	// Concurrency is the number of requests served at the same time.
+	// Required.
+	Concurrency int
+
+	// MaxQueue caps the number of requests waiting for a slot; requests
+	// beyond it are rejected at once (default: 0, unbounded).
+	MaxQueue int
+
+	// MaxWait is how long a request waits for a slot before being rejected
+	// (default: 10s).
+	MaxWait time.Duration
+
+	// RetryAfter is sent as the Retry-After header of rejected requests
+	// (default: MaxWait).
+	RetryAfter time.Duration
+
+	// Rejected, when set, is incremented for every rejected request, e.g.
+	// expvar.NewInt("queue_rejected") to expose it with the Profiler.
+	Rejected *expvar.Int
+
+	// OnReject is called for every rejected request, e.g. to increment a
+	// metric of another system.
+	OnReject func(r *http.Request)
+}
+
+// Queue is a middleware serving up to Concurrency requests at a time and
+// queueing the rest until a slot frees up. Requests waiting longer than
+// MaxWait, or arriving when MaxQueue are already waiting, get a 503 with a
+// Retry-After header. Sized to what a backend sustains, it absorbs bursts
+// with some latency instead of rejecting them outright like Throttle:
+//
+//	r.Use(middleware.Queue(middleware.QueueOpts{
+//		Concurrency: 50,
+//		MaxWait:     2 * time.Second,
+//		Rejected:    expvar.NewInt("queue_rejected"),
+//	}))
+//
+// Requests are admitted in the order they arrive, within the fairness of
+// the Go scheduler.
+func Queue(opts QueueOpts) func(http.Handler) http.Handler {
+	if opts.Concurrency < 1 {
+		panic("chi/middleware: Queue expects Concurrency > 0")
+	}
+	if opts.MaxQueue < 0 {
+		panic("chi/middleware: Queue expects MaxQueue to be positive")
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = 10 * time.Second
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = opts.MaxWait
+	}
+	retryAfter := strconv.Itoa(ceilSeconds(opts.RetryAfter))
+	slots := make(chan struct{}, opts.Concurrency)
+	var waiting atomic.Int64
+
+	reject := func(w http.ResponseWriter, r *http.Request, msg string) {
+		if opts.Rejected != nil {
+			opts.Rejected.Add(1)
+		}
+		if opts.OnReject != nil {
+			opts.OnReject(r)
+		}
+		w.Header().Set("Retry-After", retryAfter)
+		http.Error(w, msg, http.StatusServiceUnavailable)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				if n := waiting.Add(1); opts.MaxQueue > 0 && n > int64(opts.MaxQueue) {
+					waiting.Add(-1)
+					reject(w, r, errCapacityExceeded)
+					return
+				}
+				timer := time.NewTimer(opts.MaxWait)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+					waiting.Add(-1)
+				case <-timer.C:
+					waiting.Add(-1)
+					reject(w, r, errTimedOut)
+					return
+				case <-r.Context().Done():
+					timer.Stop()
+					waiting.Add(-1)
+					return
+				}
+			}
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	rejected := new(expvar.Int)
+	h := Queue(QueueOpts{Concurrency: 1, MaxQueue: 1, MaxWait: 100 * time.Millisecond, Rejected: rejected})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	do := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = do().Code
+		}(i)
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// One request served, one queued: the next is over MaxQueue.
+	if w := do(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 503 with Retry-After over MaxQueue, got %d, headers %v", w.Code, w.Header())
+	}
+	close(release)
+	wg.Wait()
+	if codes[0] != 200 || codes[1] != 200 {
+		t.Fatalf("expected the queued request to be served, got %v", codes)
+	}
+	if rejected.Value() != 1 {
+		t.Fatalf("expected 1 rejected request, got %d", rejected.Value())
+	}
+}
+
+func TestQueueMaxWait(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var onReject int
+	h := Queue(QueueOpts{Concurrency: 1, MaxWait: 20 * time.Millisecond, RetryAfter: 5 * time.Second, OnReject: func(r *http.Request) { onReject++ }})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-started
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	close(release)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" || onReject != 1 {
+		t.Fatalf("expected 503 with Retry-After 5 after MaxWait, got %d, headers %v, %d rejections", w.Code, w.Header(), onReject)
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaPeriod is the calendar period over which a quota is counted, in
+// UTC.
+type QuotaPeriod int
+
+const (
+	// QuotaDaily resets quotas at midnight UTC.
+	QuotaDaily QuotaPeriod = iota
+	// QuotaMonthly resets quotas on the first day of the month, UTC.
+	QuotaMonthly
+)
+
+// bounds returns the start and end of the period containing t.
+func (p QuotaPeriod) bounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if p == QuotaMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// QuotaStore counts the requests of each client per period.
+// Implementations backed by Redis or SQL share quotas across the instances
+// of an app and survive restarts.
+type QuotaStore interface {
+	// Increment adds one to the counter of key for the period starting at
+	// start, which may be dropped after end, and returns the new count.
+	Increment(ctx context.Context, key string, start, end time.Time) (int64, error)
+}
+
+// QuotaConfig defines the configuration of the Quota middleware.
+type QuotaConfig struct {
+	// Limit is the number of requests allowed per Period. Required.
+	Limit int64
+
+	// Period is QuotaDaily (default) or QuotaMonthly.
+	Period QuotaPeriod
+
+	// KeyFunc identifies the client of a request, typically by API key
+	// (default: the remote IP). Requests with an empty key aren't counted.
+	KeyFunc func(r *http.Request) string
+
+	// Store counts requests (default: a MemoryQuotaStore). When it fails,
+	// requests are let through.
+	Store QuotaStore
+
+	// Thresholds are the fractions of Limit, such as 0.8 and 1, at which
+	// OnThreshold is called.
+	Thresholds []float64
+
+	// OnThreshold is called once per period when the client key crosses
+	// each of the Thresholds, e.g. to send a usage alert or bill an
+	// overage. It runs on the request goroutine and should be fast.
+	OnThreshold func(r *http.Request, key string, used, limit int64, threshold float64)
+
+	// StatusCode is sent once the quota is exhausted (default: 429).
+	StatusCode int
+}
+
+// Quota is a middleware enforcing a quota of requests per client and per
+// day or month, such as the allowance of an API plan. Unlike RateLimit,
+// which smooths bursts, it caps the volume over a long period. Responses
+// carry X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset (in seconds)
+// headers, and requests over quota get a 429 with a Retry-After header:
+//
+//	r.Use(middleware.Quota(middleware.QuotaConfig{
+//		Limit:      10000,
+//		Period:     middleware.QuotaMonthly,
+//		KeyFunc:    func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+//		Thresholds: []float64{0.8, 1},
+//		OnThreshold: func(r *http.Request, key string, used, limit int64, threshold float64) {
+//			alerts.QuotaUsage(key, threshold)
+//		},
+//	}))
+func Quota(config QuotaConfig) func(http.Handler) http.Handler {
+	if config.Limit < 1 {
+		panic("chi/middleware: Quota expects Limit > 0")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = remoteIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryQuotaStore()
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusTooManyRequests
+	}
+	// Counts at which each threshold is crossed.
+	marks := make([]int64, len(config.Thresholds))
+	for i, t := range config.Thresholds {
+		marks[i] = int64(math.Max(1, math.Ceil(t*float64(config.Limit))))
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := config.KeyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			now := time.Now()
+			start, end := config.Period.bounds(now)
+			used, err := config.Store.Increment(r.Context(), key, start, end)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if config.OnThreshold != nil {
+				for i, mark := range marks {
+					if used == mark {
+						config.OnThreshold(r, key, used, config.Limit, config.Thresholds[i])
+					}
+				}
+			}
+
+			reset := strconv.Itoa(ceilSeconds(end.Sub(now)))
+			h := w.Header()
+			h.Set("X-Quota-Limit", strconv.FormatInt(config.Limit, 10))
+			h.Set("X-Quota-Remaining", strconv.FormatInt(max(0, config.Limit-used), 10))
+			h.Set("X-Quota-Reset", reset)
+			if used > config.Limit {
+				h.Set("Retry-After", reset)
+				http.Error(w, "Quota exceeded", config.StatusCode)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// MemoryQuotaStore is a QuotaStore keeping counters in memory, for single
+// instance apps and tests. Counters are lost on restart.
+type MemoryQuotaStore struct {
+	mu       sync.Mutex
+	counters map[string]*quotaCounter
+}
+
+type quotaCounter struct {
+	start time.Time
+	end   time.Time
+	count int64
+}
+
+// NewMemoryQuotaStore returns an empty MemoryQuotaStore.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: map[string]*quotaCounter{}}
+}
+
+// Increment implements QuotaStore.
+func (s *MemoryQuotaStore) Increment(ctx context.Context, key string, start, end time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.counters[key]
+	if c == nil || !c.start.Equal(start) {
+		if c == nil || start.After(c.start) {
+			// New period: drop the counters of past ones.
+			for k, old := range s.counters {
+				if !old.end.After(start) {
+					delete(s.counters, k)
+				}
+			}
+		}
+		c = &quotaCounter{start: start, end: end}
+		s.counters[key] = c
+	}
+	c.count++
+	return c.count, nil
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	var crossed []float64
+	h := Quota(QuotaConfig{
+		Limit:      4,
+		KeyFunc:    func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+		Thresholds: []float64{0.5, 1},
+		OnThreshold: func(r *http.Request, key string, used, limit int64, threshold float64) {
+			if key != "alice" || limit != 4 {
+				t.Errorf("unexpected threshold callback for %q, limit %d", key, limit)
+			}
+			crossed = append(crossed, threshold)
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, remaining := range []string{"3", "2", "1", "0"} {
+		w := do("alice")
+		if w.Code != 200 || w.Header().Get("X-Quota-Limit") != "4" || w.Header().Get("X-Quota-Remaining") != remaining {
+			t.Fatalf("request %d: got %d, headers %v", i, w.Code, w.Header())
+		}
+	}
+	w := do("alice")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" || w.Header().Get("X-Quota-Remaining") != "0" {
+		t.Fatalf("expected 429 with Retry-After, got %d, headers %v", w.Code, w.Header())
+	}
+	if len(crossed) != 2 || crossed[0] != 0.5 || crossed[1] != 1 {
+		t.Fatalf("expected thresholds 0.5 and 1 crossed once, got %v", crossed)
+	}
+	if w := do("bob"); w.Code != 200 {
+		t.Fatalf("expected other clients not to be limited, got %d", w.Code)
+	}
+	if w := do(""); w.Code != 200 || w.Header().Get("X-Quota-Limit") != "" {
+		t.Fatalf("expected requests without a key not to be counted, got %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestQuotaPeriod(t *testing.T) {
+	now := time.Date(2024, 2, 15, 13, 30, 0, 0, time.UTC)
+	start, end := QuotaDaily.bounds(now)
+	if !start.Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected daily period %v - %v", start, end)
+	}
+	start, end = QuotaMonthly.bounds(now)
+	if !start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected monthly period %v - %v", start, end)
+	}
+}
+
+func TestMemoryQuotaStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryQuotaStore()
+	day1, day2 := QuotaDaily.bounds(time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC))
+	_, day3 := QuotaDaily.bounds(day2)
+
+	store.Increment(ctx, "a", day1, day2)
+	store.Increment(ctx, "b", day1, day2)
+	if n, _ := store.Increment(ctx, "a", day1, day2); n != 2 {
+		t.Fatalf("expected a count of 2, got %d", n)
+	}
+	if n, _ := store.Increment(ctx, "a", day2, day3); n != 1 {
+		t.Fatalf("expected the count to reset with the period, got %d", n)
+	}
+	if _, ok := store.counters["b"]; ok {
+		t.Fatal("expected counters of past periods to be dropped")
+	}
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how RateLimit counts requests.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Max requests, then one request
+	// every Window/Max as tokens refill.
+	TokenBucket RateLimitAlgorithm = iota
+
+	// SlidingWindow allows Max requests in any Window, approximated by
+	// weighting the count of the previous fixed window.
+	SlidingWindow
+)
+
+// RateLimitSpec is the limit applied to a key.
+type RateLimitSpec struct {
+	Max       int
+	Window    time.Duration
+	Algorithm RateLimitAlgorithm
+}
+
+// RateLimitResult is the outcome of a RateLimitStore.Take.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // Requests left before being limited
+	Reset      time.Duration // Until the quota is fully available again
+	RetryAfter time.Duration // Until the next request is allowed, when not Allowed
+}
+
+// RateLimitStore counts requests per key. Implementations backed by Redis
+// share limits across the instances of an app; they usually run the
+// algorithm in a script for atomicity.
+type RateLimitStore interface {
+	// Take records a request for key under spec and reports whether it is
+	// allowed.
+	Take(ctx context.Context, key string, spec RateLimitSpec) (RateLimitResult, error)
+}
+
+// RateLimitConfig defines the configuration of the RateLimit middleware.
+type RateLimitConfig struct {
+	// Max is the number of requests allowed per Window. Required.
+	Max int
+
+	// Window is the period of Max (default: 1m).
+	Window time.Duration
+
+	// Algorithm is TokenBucket (default) or SlidingWindow.
+	Algorithm RateLimitAlgorithm
+
+	// KeyFunc identifies the client of a request, e.g. by API key. The
+	// default uses the remote IP, so install RealIP first when behind a
+	// trusted proxy.
+	KeyFunc func(r *http.Request) string
+
+	// Store counts requests (default: a MemoryRateLimitStore). When it
+	// fails, requests are let through.
+	Store RateLimitStore
+
+	// StatusCode is sent when a request is limited (default: 429).
+	StatusCode int
+}
+
+// RateLimit is a middleware limiting how many requests each client makes
+// per time window. Responses carry the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, and limited requests
+// get a 429 with a Retry-After header:
+//
+//	r.Use(middleware.RateLimit(middleware.RateLimitConfig{
+//		Max:     100,
+//		Window:  time.Minute,
+//		KeyFunc: func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+//	}))
+//
+// Unlike ThrottleClient, which caps the requests of a client in flight at
+// the same time, RateLimit caps the rate at which they arrive.
+func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
+	if config.Max < 1 {
+		panic("chi/middleware: RateLimit expects Max > 0")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = remoteIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusTooManyRequests
+	}
+	spec := RateLimitSpec{Max: config.Max, Window: config.Window, Algorithm: config.Algorithm}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			res, err := config.Store.Take(r.Context(), config.KeyFunc(r), spec)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(config.Max))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				http.Error(w, http.StatusText(config.StatusCode), config.StatusCode)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, at least 1 for positive d.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore is a RateLimitStore keeping counters in memory,
+// for single instance apps. Idle keys are dropped as new ones are seen.
+type MemoryRateLimitStore struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	keys  map[string]*rateLimitState
+	takes int
+}
+
+type rateLimitState struct {
+	// TokenBucket
+	tokens float64
+	last   time.Time
+
+	// SlidingWindow
+	start      time.Time // Start of the current fixed window
+	curr, prev int
+
+	expires time.Time
+}
+
+// NewMemoryRateLimitStore returns an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{now: time.Now, keys: map[string]*rateLimitState{}}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, spec RateLimitSpec) (RateLimitResult, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.takes++; s.takes%1024 == 0 {
+		for k, st := range s.keys {
+			if now.After(st.expires) {
+				delete(s.keys, k)
+			}
+		}
+	}
+	st := s.keys[key]
+	if st == nil || now.After(st.expires) {
+		st = &rateLimitState{tokens: float64(spec.Max), last: now, start: now}
+		s.keys[key] = st
+	}
+	st.expires = now.Add(2 * spec.Window)
+
+	if spec.Algorithm == SlidingWindow {
+		return st.slidingWindow(now, spec), nil
+	}
+	return st.tokenBucket(now, spec), nil
+}
+
+func (st *rateLimitState) tokenBucket(now time.Time, spec RateLimitSpec) RateLimitResult {
+	rate := float64(spec.Max) / float64(spec.Window) // Tokens per nanosecond
+	st.tokens = math.Min(float64(spec.Max), st.tokens+float64(now.Sub(st.last))*rate)
+	st.last = now
+
+	res := RateLimitResult{Allowed: st.tokens >= 1}
+	if res.Allowed {
+		st.tokens--
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - st.tokens) / rate))
+	}
+	res.Remaining = int(st.tokens)
+	res.Reset = time.Duration(math.Ceil((float64(spec.Max) - st.tokens) / rate))
+	return res
+}
+
+func (st *rateLimitState) slidingWindow(now time.Time, spec RateLimitSpec) RateLimitResult {
+	if elapsed := now.Sub(st.start); elapsed >= spec.Window {
+		windows := elapsed / spec.Window
+		st.prev = st.curr
+		if windows > 1 {
+			st.prev = 0
+		}
+		st.curr = 0
+		st.start = st.start.Add(windows * spec.Window)
+	}
+	elapsed := now.Sub(st.start)
+	weight := 1 - float64(elapsed)/float64(spec.Window)
+	count := func() float64 { return float64(st.prev)*weight + float64(st.curr) }
+
+	res := RateLimitResult{Allowed: count() < float64(spec.Max)}
+	if res.Allowed {
+		st.curr++
+	} else if st.curr < spec.Max {
+		// Wait for the previous window to weigh less.
+		f := 1 - float64(spec.Max-st.curr)/float64(st.prev)
+		res.RetryAfter = time.Duration(f*float64(spec.Window)) - elapsed
+	} else {
+		// Wait for the next window, once the current one weighs less.
+		f := 1 - float64(spec.Max)/float64(st.curr)
+		res.RetryAfter = spec.Window - elapsed + time.Duration(f*float64(spec.Window))
+	}
+	res.Remaining = int(math.Max(0, float64(spec.Max)-math.Ceil(count())))
+	res.Reset = spec.Window - elapsed
+	return res
+}
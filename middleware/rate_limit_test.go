@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	h := RateLimit(RateLimitConfig{Max: 2, Window: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(addr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := do("10.0.0.1:1234")
+		if w.Code != 200 || w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != remaining {
+			t.Fatalf("request %d: got %d, headers %v", i, w.Code, w.Header())
+		}
+	}
+	w := do("10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected 429 with Retry-After 30, got %d, headers %v", w.Code, w.Header())
+	}
+	if w := do("10.0.0.2:1234"); w.Code != 200 {
+		t.Fatalf("expected other clients not to be limited, got %d", w.Code)
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	take := func(alg RateLimitAlgorithm) RateLimitResult {
+		res, _ := store.Take(context.Background(), alg.name(), RateLimitSpec{Max: 10, Window: 10 * time.Second, Algorithm: alg})
+		return res
+	}
+
+	t.Run("token bucket", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			if !take(TokenBucket).Allowed {
+				t.Fatalf("expected a burst of 10, limited at %d", i)
+			}
+		}
+		res := take(TokenBucket)
+		if res.Allowed || res.RetryAfter != time.Second || res.Reset != 10*time.Second {
+			t.Fatalf("expected to wait 1s for a token, got %+v", res)
+		}
+		now = now.Add(time.Second)
+		if !take(TokenBucket).Allowed || take(TokenBucket).Allowed {
+			t.Fatal("expected one token to refill per second")
+		}
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		start := now
+		for i := 0; i < 10; i++ {
+			if !take(SlidingWindow).Allowed {
+				t.Fatalf("expected 10 requests per window, limited at %d", i)
+			}
+		}
+		res := take(SlidingWindow)
+		if res.Allowed || res.RetryAfter != 10*time.Second {
+			t.Fatalf("expected to wait for the next window, got %+v", res)
+		}
+
+		// Halfway through the next window, the previous one still counts
+		// for half of its 10 requests.
+		now = start.Add(15 * time.Second)
+		for i := 0; i < 5; i++ {
+			if !take(SlidingWindow).Allowed {
+				t.Fatalf("expected 5 more requests, limited at %d", i)
+			}
+		}
+		if take(SlidingWindow).Allowed {
+			t.Fatal("expected the weighted previous window to limit requests")
+		}
+	})
+}
+
+func (a RateLimitAlgorithm) name() string {
+	if a == SlidingWindow {
+		return "sliding"
+	}
+	return "bucket"
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// RewriteRules declares how RewriteRequest transforms an incoming request.
+// Rules are applied in the order of the fields below.
+type RewriteRules struct {
+	// Paths rewrites the URL path with the first matching rule.
+	Paths []PathRule
+
+	// SetHeaders sets request headers, replacing existing values.
+	SetHeaders map[string]string
+
+	// RemoveHeaders deletes request headers.
+	RemoveHeaders []string
+
+	// RenameHeaders moves header values from the key to the value name.
+	RenameHeaders map[string]string
+
+	// RenameQuery moves query parameters from the key to the value name.
+	RenameQuery map[string]string
+
+	// SetQuery sets query parameters, replacing existing values.
+	SetQuery map[string]string
+
+	// RemoveQuery deletes query parameters.
+	RemoveQuery []string
+}
+
+// PathRule rewrites request paths matching Pattern to Replacement, which may
+// reference capture groups as in regexp.ReplaceAllString, e.g.
+// PathRule{Pattern: `^/v1/users/(\d+)$`, Replacement: "/users/$1"}.
+type PathRule struct {
+	Pattern     string
+	Replacement string
+}
+
+// RewriteRequest is a middleware that rewrites the request path, headers
+// and query parameters according to declarative rules before the request is
+// routed. It is useful when Owl fronts legacy backends or clients that use
+// different conventions. Mount it with Use so it runs before routing.
+func RewriteRequest(rules RewriteRules) func(http.Handler) http.Handler {
+	type compiledRule struct {
+		rex         *regexp.Regexp
+		replacement string
+	}
+	paths := make([]compiledRule, len(rules.Paths))
+	for i, pr := range rules.Paths {
+		paths[i] = compiledRule{regexp.MustCompile(pr.Pattern), pr.Replacement}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			for _, pr := range paths {
+				if pr.rex.MatchString(r.URL.Path) {
+					r.URL.Path = pr.rex.ReplaceAllString(r.URL.Path, pr.replacement)
+					r.URL.RawPath = ""
+					break
+				}
+			}
+
+			for k, v := range rules.SetHeaders {
+				r.Header.Set(k, v)
+			}
+			for _, k := range rules.RemoveHeaders {
+				r.Header.Del(k)
+			}
+			for from, to := range rules.RenameHeaders {
+				if vs := r.Header.Values(from); len(vs) > 0 {
+					r.Header.Del(from)
+					for _, v := range vs {
+						r.Header.Add(to, v)
+					}
+				}
+			}
+
+			if len(rules.RenameQuery)+len(rules.SetQuery)+len(rules.RemoveQuery) > 0 {
+				q := r.URL.Query()
+				for from, to := range rules.RenameQuery {
+					if vs, ok := q[from]; ok {
+						delete(q, from)
+						q[to] = append(q[to], vs...)
+					}
+				}
+				for k, v := range rules.SetQuery {
+					q.Set(k, v)
+				}
+				for _, k := range rules.RemoveQuery {
+					q.Del(k)
+				}
+				r.URL.RawQuery = q.Encode()
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func TestRewriteRequest(t *testing.T) {
+	r := owl.NewRouter()
+	r.Use(RewriteRequest(RewriteRules{
+		Paths: []PathRule{
+			{Pattern: `^/v1/users/(\d+)$`, Replacement: "/users/$1"},
+			{Pattern: `^/v1/`, Replacement: "/ignored/"},
+		},
+		SetHeaders:    map[string]string{"X-Source": "legacy"},
+		RemoveHeaders: []string{"X-Debug"},
+		RenameHeaders: map[string]string{"X-Auth-Token": "Authorization"},
+		RenameQuery:   map[string]string{"pg": "page"},
+		SetQuery:      map[string]string{"format": "json"},
+		RemoveQuery:   []string{"cb"},
+	}))
+
+	var got *http.Request
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		got = req
+		w.Write([]byte(owl.URLParam(req, "id")))
+	})
+
+	req := httptest.NewRequest("GET", "/v1/users/42?pg=3&cb=123", nil)
+	req.Header.Set("X-Debug", "1")
+	req.Header.Set("X-Auth-Token", "Bearer abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "42" {
+		t.Fatalf("path not rewritten, got %d %q", w.Code, w.Body.String())
+	}
+	if got.Header.Get("X-Source") != "legacy" || got.Header.Get("X-Debug") != "" {
+		t.Errorf("headers not set/removed: %v", got.Header)
+	}
+	if got.Header.Get("Authorization") != "Bearer abc" || got.Header.Get("X-Auth-Token") != "" {
+		t.Errorf("header not renamed: %v", got.Header)
+	}
+	if got.URL.RawQuery != "format=json&page=3" {
+		t.Errorf("unexpected query %q", got.URL.RawQuery)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// ServerTiming is a middleware sending the metrics recorded with
+// owl.Ctx.Timing, or owl.ServerTimingsFromContext for net/http handlers, in
+// a Server-Timing header, followed by a "total" metric measuring the
+// request until its headers are sent:
+//
+//	Server-Timing: db;dur=12.5;desc="List users", total;dur=14.1
+//
+// Browser devtools and APMs display the breakdown without a tracing stack.
+// The header reveals timings of the backend, so it may be restricted to
+// internal clients in production.
+func ServerTiming(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		timings := &owl.ServerTimings{}
+		r = r.WithContext(context.WithValue(r.Context(), owl.ServerTimingCtxKey, timings))
+		sw := &serverTimingWriter{ResponseWriter: w, timings: timings, start: time.Now()}
+		next.ServeHTTP(sw, r)
+		if !sw.wroteHeader {
+			sw.writeTiming()
+		}
+	}
+	return http.HandlerFunc(fn)
+}
+
+// serverTimingWriter adds the Server-Timing header before the response
+// headers are sent.
+type serverTimingWriter struct {
+	http.ResponseWriter
+	timings     *owl.ServerTimings
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *serverTimingWriter) writeTiming() {
+	w.wroteHeader = true
+	w.timings.Add("total", time.Since(w.start))
+	w.Header().Set("Server-Timing", w.timings.String())
+}
+
+func (w *serverTimingWriter) WriteHeader(code int) {
+	if !w.wroteHeader && code >= 200 {
+		w.writeTiming()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.writeTiming()
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverTimingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
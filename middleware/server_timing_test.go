@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestServerTiming(t *testing.T) {
+	app := owl.New()
+	app.Use(ServerTiming)
+	app.GET("/", func(c *owl.Ctx) error {
+		c.Timing("db", 12500*time.Microsecond, "List users")
+		c.Timing("cache", 2*time.Millisecond)
+		return c.JSON(map[string]string{"ok": "true"})
+	})
+	app.GET("/empty", func(c *owl.Ctx) error { return nil })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	want := regexp.MustCompile(`^db;dur=12\.5;desc="List users", cache;dur=2, total;dur=[0-9.]+$`)
+	if got := w.Header().Get("Server-Timing"); !want.MatchString(got) {
+		t.Fatalf("unexpected Server-Timing %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/empty", nil))
+	if got := w.Header().Get("Server-Timing"); !regexp.MustCompile(`^total;dur=[0-9.]+$`).MatchString(got) {
+		t.Fatalf("expected a total for responses without a body, got %q", got)
+	}
+
+	// Without the middleware, Timing does nothing.
+	r := owl.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		if owl.ServerTimingsFromContext(r.Context()) != nil {
+			t.Error("expected no ServerTimings")
+		}
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
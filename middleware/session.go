@@ -0,0 +1,354 @@
+package middleware
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+// ErrSessionNotFound is returned by a SessionStore for unknown or expired
+// sessions.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore loads and saves session values. The token identifies a
+// session in the store and is sent to the client in the session cookie.
+// Implementations backed by Redis or SQL let several instances of an app
+// share sessions.
+type SessionStore interface {
+	// Load returns the values saved under token, or ErrSessionNotFound.
+	Load(ctx context.Context, token string) (map[string]interface{}, error)
+
+	// Save stores values for ttl under token, or under a new token when
+	// token is empty, and returns the token to send to the client.
+	Save(ctx context.Context, token string, values map[string]interface{}, ttl time.Duration) (string, error)
+
+	// Delete removes token. Deleting an unknown token is not an error.
+	Delete(ctx context.Context, token string) error
+}
+
+// SessionConfig configures the Session middleware.
+type SessionConfig struct {
+	// CookieName is the name of the session cookie (default: "owl_session").
+	CookieName string
+
+	// TTL is how long a session lives after its last change (default: 24h).
+	TTL time.Duration
+
+	// Path and Domain scope the session cookie (default Path: "/").
+	Path   string
+	Domain string
+
+	// Secure restricts the session cookie to HTTPS.
+	Secure bool
+
+	// SameSite is the SameSite attribute of the session cookie (default:
+	// http.SameSiteLaxMode).
+	SameSite http.SameSite
+}
+
+// Session is a middleware loading the session of the request from store
+// and making it available with owl.Ctx.Session:
+//
+//	app.Use(middleware.Session(middleware.NewMemorySessionStore(), middleware.SessionConfig{
+//		Secure: true,
+//	}))
+//
+//	app.POST("/cart", func(c *owl.Ctx) error {
+//		c.Session().Set("cart", c.Request.FormValue("id"))
+//		http.Redirect(c.Response, c.Request, "/cart", http.StatusSeeOther)
+//		return nil
+//	})
+//
+// Sessions are only saved, and the cookie only sent, when they were
+// modified, so requests of anonymous visitors don't create sessions. Values
+// are saved when the response headers are written; save failures are
+// logged with the logger of owl.LoggerFromContext, or slog.Default.
+func Session(store SessionStore, config SessionConfig) func(http.Handler) http.Handler {
+	if store == nil {
+		panic("chi/middleware: Session needs a SessionStore")
+	}
+	if config.CookieName == "" {
+		config.CookieName = "owl_session"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var values map[string]interface{}
+			var token string
+			if cookie, err := r.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+				values, err = store.Load(r.Context(), cookie.Value)
+				switch {
+				case err == nil:
+					token = cookie.Value
+				case !errors.Is(err, ErrSessionNotFound):
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+			}
+
+			sess := owl.NewSession(token, values)
+			sw := &sessionWriter{ResponseWriter: w, commit: func() {
+				if err := saveSession(r.Context(), w, store, config, sess); err != nil {
+					logger := owl.LoggerFromContext(r.Context())
+					if logger == nil {
+						logger = slog.Default()
+					}
+					logger.ErrorContext(r.Context(), "saving session", "error", err)
+				}
+			}}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), owl.SessionCtxKey, sess)))
+			sw.once.Do(sw.commit)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// saveSession saves sess when it was modified and sets the session cookie.
+func saveSession(ctx context.Context, w http.ResponseWriter, store SessionStore, config SessionConfig, sess *owl.Session) error {
+	if !sess.Modified() {
+		return nil
+	}
+	token := sess.ID()
+	if token != "" && (sess.Destroyed() || sess.Renewed()) {
+		if err := store.Delete(ctx, token); err != nil {
+			return err
+		}
+		token = ""
+	}
+
+	cookie := &http.Cookie{
+		Name:     config.CookieName,
+		Path:     config.Path,
+		Domain:   config.Domain,
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: config.SameSite,
+	}
+	if sess.Destroyed() {
+		cookie.MaxAge = -1
+	} else {
+		var err error
+		if token, err = store.Save(ctx, token, sess.Values(), config.TTL); err != nil {
+			return err
+		}
+		cookie.Value = token
+		cookie.MaxAge = int(config.TTL / time.Second)
+	}
+	http.SetCookie(w, cookie)
+	sess.Saved(token)
+	return nil
+}
+
+// sessionWriter saves the session right before the response headers are
+// written.
+type sessionWriter struct {
+	http.ResponseWriter
+	once   sync.Once
+	commit func()
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	w.once.Do(w.commit)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.once.Do(w.commit)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Flush() {
+	w.once.Do(w.commit)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MemorySessionStore is a SessionStore keeping sessions in memory. It
+// suits development and single instance apps. Expired sessions are dropped
+// as new ones are saved.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	saves    int
+}
+
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+// NewMemorySessionStore returns an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[string]memorySession{}}
+}
+
+// Load implements SessionStore.
+func (s *MemorySessionStore) Load(ctx context.Context, token string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[token]
+	if !ok || !time.Now().Before(sess.expires) {
+		return nil, ErrSessionNotFound
+	}
+	return copySessionValues(sess.values), nil
+}
+
+// Save implements SessionStore.
+func (s *MemorySessionStore) Save(ctx context.Context, token string, values map[string]interface{}, ttl time.Duration) (string, error) {
+	if token == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		token = base64.RawURLEncoding.EncodeToString(b)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[token] = memorySession{values: copySessionValues(values), expires: time.Now().Add(ttl)}
+	if s.saves++; s.saves%1024 == 0 {
+		now := time.Now()
+		for t, sess := range s.sessions {
+			if !now.Before(sess.expires) {
+				delete(s.sessions, t)
+			}
+		}
+	}
+	return token, nil
+}
+
+// Delete implements SessionStore.
+func (s *MemorySessionStore) Delete(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, token)
+	return nil
+}
+
+// copySessionValues copies values, so that the sessions of a
+// MemorySessionStore don't share their map with requests.
+func copySessionValues(values map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+	return c
+}
+
+// CookieSessionStore is a SessionStore keeping the values in the session
+// cookie itself, encrypted and authenticated with AES-GCM, so that no
+// server-side storage is needed. Values are encoded as JSON: numbers are
+// loaded as float64 and structs as maps. Cookies are limited to about 4KB
+// and sessions can't be revoked before they expire.
+type CookieSessionStore struct {
+	keys *owl.Keyring
+}
+
+// NewCookieSessionStore returns a CookieSessionStore encrypting with the
+// current key of keys and decrypting with any of its keys, so that keys can
+// be rotated without losing sessions.
+func NewCookieSessionStore(keys *owl.Keyring) *CookieSessionStore {
+	if keys == nil {
+		panic("chi/middleware: NewCookieSessionStore needs a Keyring")
+	}
+	return &CookieSessionStore{keys: keys}
+}
+
+// cookieSession is the plaintext of a CookieSessionStore token.
+type cookieSession struct {
+	Values  map[string]interface{} `json:"v"`
+	Expires int64                  `json:"e"`
+}
+
+// maxSessionCookie bounds tokens so that the cookie fits browser limits.
+const maxSessionCookie = 4000
+
+// Load implements SessionStore.
+func (s *CookieSessionStore) Load(ctx context.Context, token string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	for _, key := range s.keys.Keys() {
+		gcm, err := sessionCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < gcm.NonceSize() {
+			return nil, ErrSessionNotFound
+		}
+		plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+		if err != nil {
+			continue
+		}
+		var sess cookieSession
+		if err := json.Unmarshal(plain, &sess); err != nil || time.Now().Unix() >= sess.Expires {
+			return nil, ErrSessionNotFound
+		}
+		return sess.Values, nil
+	}
+	return nil, ErrSessionNotFound
+}
+
+// Save implements SessionStore. The token is ignored: every save issues a
+// new one.
+func (s *CookieSessionStore) Save(ctx context.Context, token string, values map[string]interface{}, ttl time.Duration) (string, error) {
+	plain, err := json.Marshal(cookieSession{Values: values, Expires: time.Now().Add(ttl).Unix()})
+	if err != nil {
+		return "", err
+	}
+	gcm, err := sessionCipher(s.keys.Current())
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil))
+	if len(token) > maxSessionCookie {
+		return "", errors.New("session too large for a cookie")
+	}
+	return token, nil
+}
+
+// Delete implements SessionStore. Tokens can't be revoked; the middleware
+// clears the cookie.
+func (s *CookieSessionStore) Delete(ctx context.Context, token string) error {
+	return nil
+}
+
+// sessionCipher returns an AES-256-GCM cipher keyed with the hash of key,
+// so that Keyring keys of any length can be used.
+func sessionCipher(key []byte) (cipher.AEAD, error) {
+	k := sha256.Sum256(key)
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func sessionApp(store SessionStore) *owl.App {
+	app := owl.New()
+	app.Use(Session(store, SessionConfig{}))
+	app.GET("/get", func(c *owl.Ctx) error {
+		return c.Text(c.Session().GetString("user"))
+	})
+	app.GET("/login", func(c *owl.Ctx) error {
+		c.Session().RenewID()
+		c.Session().Set("user", c.Query("user"))
+		return c.Text("ok")
+	})
+	app.GET("/logout", func(c *owl.Ctx) error {
+		c.Session().Destroy()
+		return c.Text("ok")
+	})
+	return app
+}
+
+func sessionRequest(h http.Handler, path string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	r := httptest.NewRequest("GET", path, nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "owl_session" {
+			return w, c
+		}
+	}
+	return w, nil
+}
+
+func TestSession(t *testing.T) {
+	stores := map[string]SessionStore{
+		"memory": NewMemorySessionStore(),
+		"cookie": NewCookieSessionStore(owl.NewKeyring(owl.KeyringOpts{}, []byte("k1"))),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			app := sessionApp(store)
+
+			if _, cookie := sessionRequest(app, "/get", nil); cookie != nil {
+				t.Fatal("expected unmodified sessions not to be saved")
+			}
+
+			_, login := sessionRequest(app, "/login?user=ada", nil)
+			if login == nil || !login.HttpOnly || login.SameSite != http.SameSiteLaxMode {
+				t.Fatalf("expected an HttpOnly Lax session cookie, got %+v", login)
+			}
+			if w, _ := sessionRequest(app, "/get", login); w.Body.String() != "ada" {
+				t.Fatalf("expected the session to be loaded, got %q", w.Body.String())
+			}
+
+			_, relogin := sessionRequest(app, "/login?user=bob", login)
+			if relogin == nil || relogin.Value == login.Value {
+				t.Fatal("expected RenewID to issue a new token")
+			}
+
+			_, logout := sessionRequest(app, "/logout", relogin)
+			if logout == nil || logout.MaxAge >= 0 {
+				t.Fatalf("expected Destroy to clear the cookie, got %+v", logout)
+			}
+			if name == "memory" {
+				if w, _ := sessionRequest(app, "/get", relogin); w.Body.String() != "" {
+					t.Fatalf("expected the destroyed session to be deleted, got %q", w.Body.String())
+				}
+			}
+
+			tampered := &http.Cookie{Name: "owl_session", Value: login.Value[:len(login.Value)-2] + "xx"}
+			if w, _ := sessionRequest(app, "/get", tampered); w.Code != 200 || w.Body.String() != "" {
+				t.Fatalf("expected unknown tokens to start a new session, got %d %q", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCookieSessionStoreRotation(t *testing.T) {
+	keys := owl.NewKeyring(owl.KeyringOpts{}, []byte("k1"))
+	app := sessionApp(NewCookieSessionStore(keys))
+
+	_, login := sessionRequest(app, "/login?user=ada", nil)
+	keys.Rotate([]byte("k2"))
+	if w, _ := sessionRequest(app, "/get", login); w.Body.String() != "ada" {
+		t.Fatalf("expected sessions to survive a key rotation, got %q", w.Body.String())
+	}
+}
+
+func TestMemorySessionStoreCopies(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+	values := map[string]interface{}{"user": "ada"}
+	token, err := store.Save(ctx, "", values, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["user"] = "eve"
+
+	loaded, err := store.Load(ctx, token)
+	if err != nil || loaded["user"] != "ada" {
+		t.Fatalf("expected the saved values to be kept apart, got %v, %v", loaded, err)
+	}
+	loaded["user"] = "eve"
+	if again, _ := store.Load(ctx, token); again["user"] != "ada" {
+		t.Fatalf("expected the loaded values to be kept apart, got %v", again)
+	}
+}
+
+type failingSessionStore struct{ SessionStore }
+
+func (failingSessionStore) Save(context.Context, string, map[string]interface{}, time.Duration) (string, error) {
+	return "", errors.New("store down")
+}
+
+func TestSessionSaveError(t *testing.T) {
+	var buf bytes.Buffer
+	app := owl.New(owl.AppConfig{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+	app.Use(Session(failingSessionStore{NewMemorySessionStore()}, SessionConfig{}))
+	app.GET("/login", func(c *owl.Ctx) error {
+		c.Session().Set("user", "ada")
+		return c.Text("ok")
+	})
+
+	if _, cookie := sessionRequest(app, "/login", nil); cookie != nil {
+		t.Fatal("expected no cookie when the session can't be saved")
+	}
+	if !strings.Contains(buf.String(), "saving session") || !strings.Contains(buf.String(), "store down") {
+		t.Fatalf("expected the error to go to the App's logger, got %q", buf.String())
+	}
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// SignatureKeyIDCtxKey is the context.Context key to store the key ID
+	// of a request authenticated by Signature.
+	SignatureKeyIDCtxKey = &contextKey{"SignatureKeyID"}
+)
+
+// SignatureScheme is the Authorization scheme of signed requests.
+const SignatureScheme = "OWL-HMAC-SHA256"
+
+// Errors reported to SignatureConfig.ErrorHandler.
+var (
+	ErrSignatureMissing = errors.New("signature: missing signature")
+	ErrSignatureInvalid = errors.New("signature: invalid signature")
+	ErrSignatureExpired = errors.New("signature: date outside of the allowed clock skew")
+	ErrSignatureUnknown = errors.New("signature: unknown key")
+)
+
+// SignatureKeys looks up the secret shared with a caller by its key ID.
+// Implementations backed by a database or a secret manager let keys be
+// issued and revoked without restarting.
+type SignatureKeys interface {
+	// SignatureKey returns the secret of keyID, or ErrSignatureUnknown.
+	SignatureKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// SignatureKeysFunc adapts a function to the SignatureKeys interface.
+type SignatureKeysFunc func(ctx context.Context, keyID string) ([]byte, error)
+
+// SignatureKey implements SignatureKeys.
+func (f SignatureKeysFunc) SignatureKey(ctx context.Context, keyID string) ([]byte, error) {
+	return f(ctx, keyID)
+}
+
+// StaticSignatureKeys returns SignatureKeys from a map of key IDs to
+// secrets.
+func StaticSignatureKeys(keys map[string][]byte) SignatureKeys {
+	return SignatureKeysFunc(func(ctx context.Context, keyID string) ([]byte, error) {
+		if key, ok := keys[keyID]; ok {
+			return key, nil
+		}
+		return nil, ErrSignatureUnknown
+	})
+}
+
+// SignatureConfig defines the configuration of the Signature middleware.
+type SignatureConfig struct {
+	// Keys looks up the secret of the key ID of a request. Required.
+	Keys SignatureKeys
+
+	// MaxSkew is the accepted difference between the Date header of a
+	// request and the server clock (default: 5m). It bounds how long a
+	// captured request can be replayed.
+	MaxSkew time.Duration
+
+	// MaxBody is the largest body read to verify its hash (default: 10MB).
+	MaxBody int64
+
+	// ErrorHandler answers requests without a valid signature (default:
+	// 401 with a WWW-Authenticate header).
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Signature is a middleware authenticating server-to-server requests
+// signed with a secret shared with the caller, see SignRequest. A signed
+// request carries a Date header and
+//
+//	Authorization: OWL-HMAC-SHA256 KeyId=<key ID>, Signature=<signature>
+//
+// where the signature is the base64 HMAC-SHA256, with the secret of the
+// key ID, of the canonical string
+//
+//	<method>\n<escaped path>?<query>\n<Date header>\n<hex SHA-256 of the body>
+//
+// Requests whose Date is more than MaxSkew away from the server clock are
+// rejected. The key ID of authenticated requests is returned by
+// SignatureKeyID:
+//
+//	api.Use(middleware.Signature(middleware.SignatureConfig{
+//		Keys: middleware.StaticSignatureKeys(map[string][]byte{"billing": secret}),
+//	}))
+func Signature(config SignatureConfig) func(http.Handler) http.Handler {
+	if config.Keys == nil {
+		panic("chi/middleware: Signature needs Keys")
+	}
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = 5 * time.Minute
+	}
+	if config.MaxBody <= 0 {
+		config.MaxBody = 10 << 20
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = signatureFailed
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			keyID, sig, ok := parseSignature(r.Header.Get("Authorization"))
+			if !ok {
+				config.ErrorHandler(w, r, ErrSignatureMissing)
+				return
+			}
+			date, err := http.ParseTime(r.Header.Get("Date"))
+			if err != nil {
+				config.ErrorHandler(w, r, ErrSignatureInvalid)
+				return
+			}
+			if skew := time.Since(date); skew > config.MaxSkew || skew < -config.MaxSkew {
+				config.ErrorHandler(w, r, ErrSignatureExpired)
+				return
+			}
+			key, err := config.Keys.SignatureKey(r.Context(), keyID)
+			if err != nil {
+				config.ErrorHandler(w, r, err)
+				return
+			}
+
+			body, err := readSignedBody(r, config.MaxBody)
+			if err != nil {
+				config.ErrorHandler(w, r, ErrSignatureInvalid)
+				return
+			}
+			if !hmac.Equal(sig, signatureMAC(key, r, body)) {
+				config.ErrorHandler(w, r, ErrSignatureInvalid)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), SignatureKeyIDCtxKey, keyID)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// SignatureKeyID returns the key ID of a request authenticated by
+// Signature, or "".
+func SignatureKeyID(ctx context.Context) string {
+	keyID, _ := ctx.Value(SignatureKeyIDCtxKey).(string)
+	return keyID
+}
+
+// SignRequest signs r for the Signature middleware with the secret of
+// keyID. It sets the Date header when missing and reads the body, which
+// is restored, to hash it:
+//
+//	req, _ := http.NewRequest("POST", "https://billing.internal/charges", body)
+//	if err := middleware.SignRequest(req, "checkout", secret); err != nil {
+//		return err
+//	}
+//	resp, err := http.DefaultClient.Do(req)
+func SignRequest(r *http.Request, keyID string, secret []byte) error {
+	if r.Header.Get("Date") == "" {
+		r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	body, err := readSignedBody(r, -1)
+	if err != nil {
+		return err
+	}
+	sig := base64.StdEncoding.EncodeToString(signatureMAC(secret, r, body))
+	r.Header.Set("Authorization", SignatureScheme+" KeyId="+keyID+", Signature="+sig)
+	return nil
+}
+
+// readSignedBody reads the body of r, up to max bytes when max >= 0, and
+// replaces it with a copy.
+func readSignedBody(r *http.Request, max int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	var src io.Reader = r.Body
+	if max >= 0 {
+		src = io.LimitReader(r.Body, max+1)
+	}
+	body, err := io.ReadAll(src)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if max >= 0 && int64(len(body)) > max {
+		return nil, errors.New("signature: body too large")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if r.GetBody != nil {
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return body, nil
+}
+
+// signatureMAC returns the HMAC-SHA256 of the canonical string of r.
+func signatureMAC(key []byte, r *http.Request, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, r.Method+"\n"+r.URL.EscapedPath()+"?"+r.URL.RawQuery+"\n"+r.Header.Get("Date")+"\n"+hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
+
+// parseSignature parses an Authorization header of the SignatureScheme.
+func parseSignature(header string) (keyID string, sig []byte, ok bool) {
+	params, found := strings.CutPrefix(header, SignatureScheme+" ")
+	if !found {
+		return "", nil, false
+	}
+	var encoded string
+	for _, param := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch k {
+		case "KeyId":
+			keyID = v
+		case "Signature":
+			encoded = v
+		}
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || keyID == "" || len(sig) == 0 {
+		return "", nil, false
+	}
+	return keyID, sig, true
+}
+
+func signatureFailed(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", SignatureScheme)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
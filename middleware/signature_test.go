@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	secret := []byte("s3cr3t")
+	srv := httptest.NewServer(Signature(SignatureConfig{
+		Keys: StaticSignatureKeys(map[string][]byte{"billing": secret}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(SignatureKeyID(r.Context()) + ":" + string(body)))
+	})))
+	defer srv.Close()
+
+	send := func(r *http.Request) (int, string) {
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	signed := func(method, path, body string, keyID string, key []byte) *http.Request {
+		r, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err := SignRequest(r, keyID, key); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	if code, body := send(signed("POST", "/charges?x=1", `{"amount":42}`, "billing", secret)); code != 200 || body != `billing:{"amount":42}` {
+		t.Fatalf("signed request: got %d %q", code, body)
+	}
+
+	unsigned, _ := http.NewRequest("GET", srv.URL+"/", nil)
+	tamperedBody := signed("POST", "/charges", `{"amount":42}`, "billing", secret)
+	tamperedBody.Body = io.NopCloser(strings.NewReader(`{"amount":4200}`))
+	tamperedBody.ContentLength = 15
+	tamperedPath := signed("GET", "/charges", "", "billing", secret)
+	tamperedPath.URL.Path = "/refunds"
+	stale, _ := http.NewRequest("GET", srv.URL+"/", nil)
+	stale.Header.Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+	SignRequest(stale, "billing", secret)
+
+	for name, r := range map[string]*http.Request{
+		"unsigned":      unsigned,
+		"tampered body": tamperedBody,
+		"tampered path": tamperedPath,
+		"stale date":    stale,
+		"unknown key":   signed("GET", "/", "", "other", secret),
+		"wrong secret":  signed("GET", "/", "", "billing", []byte("guess")),
+	} {
+		if code, _ := send(r); code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, code)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package middleware
 import (
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
+
+	"github.com/go-owl/owl"
 )
 
 const (
@@ -23,6 +26,11 @@ type ThrottleOpts struct {
 	BacklogLimit   int
 	BacklogTimeout time.Duration
 	StatusCode     int
+
+	// PerRoute gives every route pattern its own Limit and BacklogLimit,
+	// so that one slow endpoint can't use up the capacity of the others.
+	// Requests matching no route share one limit.
+	PerRoute bool
 }
 
 // Throttle is a middleware that limits number of currently processed requests
@@ -41,6 +49,18 @@ func ThrottleBacklog(limit, backlogLimit int, backlogTimeout time.Duration) func
 }
 
 // ThrottleWithOpts is a middleware that limits number of currently processed requests using passed ThrottleOpts.
+//
+// To protect a database during traffic spikes, cap the in-flight requests
+// of each route, queue a few, and shed the rest with a 503:
+//
+//	r.Use(middleware.ThrottleWithOpts(middleware.ThrottleOpts{
+//		Limit:          20,
+//		BacklogLimit:   50,
+//		BacklogTimeout: 5 * time.Second,
+//		StatusCode:     http.StatusServiceUnavailable,
+//		RetryAfterFn:   func(bool) time.Duration { return 10 * time.Second },
+//		PerRoute:       true,
+//	}))
 func ThrottleWithOpts(opts ThrottleOpts) func(http.Handler) http.Handler {
 	if opts.Limit < 1 {
 		panic("chi/middleware: Throttle expects limit > 0")
@@ -55,78 +75,119 @@ func ThrottleWithOpts(opts ThrottleOpts) func(http.Handler) http.Handler {
 		statusCode = http.StatusTooManyRequests
 	}
 
-	t := throttler{
-		tokens:         make(chan token, opts.Limit),
-		backlogTokens:  make(chan token, opts.Limit+opts.BacklogLimit),
-		backlogTimeout: opts.BacklogTimeout,
-		statusCode:     statusCode,
-		retryAfterFn:   opts.RetryAfterFn,
+	newThrottler := func() *throttler {
+		t := &throttler{
+			tokens:         make(chan token, opts.Limit),
+			backlogTokens:  make(chan token, opts.Limit+opts.BacklogLimit),
+			backlogTimeout: opts.BacklogTimeout,
+			statusCode:     statusCode,
+			retryAfterFn:   opts.RetryAfterFn,
+		}
+
+		// Filling tokens.
+		for i := 0; i < opts.Limit+opts.BacklogLimit; i++ {
+			if i < opts.Limit {
+				t.tokens <- token{}
+			}
+			t.backlogTokens <- token{}
+		}
+		return t
 	}
 
-	// Filling tokens.
-	for i := 0; i < opts.Limit+opts.BacklogLimit; i++ {
-		if i < opts.Limit {
-			t.tokens <- token{}
+	if !opts.PerRoute {
+		t := newThrottler()
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.serve(w, r, next)
+			})
 		}
-		t.backlogTokens <- token{}
 	}
 
+	var mu sync.Mutex
+	routes := map[string]*throttler{}
 	return func(next http.Handler) http.Handler {
-		fn := func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			select {
-
-			case <-ctx.Done():
-				t.setRetryAfterHeaderIfNeeded(w, true)
-				http.Error(w, errContextCanceled, t.statusCode)
-				return
-
-			case btok := <-t.backlogTokens:
-				defer func() {
-					t.backlogTokens <- btok
-				}()
-
-				// Try to get a processing token immediately first
-				select {
-				case tok := <-t.tokens:
-					defer func() {
-						t.tokens <- tok
-					}()
-					next.ServeHTTP(w, r)
-					return
-				default:
-					// No immediate token available, need to wait with timer
-				}
-
-				timer := time.NewTimer(t.backlogTimeout)
-				select {
-				case <-timer.C:
-					t.setRetryAfterHeaderIfNeeded(w, false)
-					http.Error(w, errTimedOut, t.statusCode)
-					return
-				case <-ctx.Done():
-					timer.Stop()
-					t.setRetryAfterHeaderIfNeeded(w, true)
-					http.Error(w, errContextCanceled, t.statusCode)
-					return
-				case tok := <-t.tokens:
-					defer func() {
-						timer.Stop()
-						t.tokens <- tok
-					}()
-					next.ServeHTTP(w, r)
-				}
-				return
-
-			default:
-				t.setRetryAfterHeaderIfNeeded(w, false)
-				http.Error(w, errCapacityExceeded, t.statusCode)
-				return
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := routePatternOf(r)
+			mu.Lock()
+			t := routes[pattern]
+			if t == nil {
+				t = newThrottler()
+				routes[pattern] = t
 			}
+			mu.Unlock()
+			t.serve(w, r, next)
+		})
+	}
+}
+
+// routePatternOf returns the pattern, relative to the current router, of
+// the route r will be routed to, so that middlewares running before
+// routing can key on it.
+func routePatternOf(r *http.Request) string {
+	rctx := owl.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	path := rctx.RoutePath
+	if path == "" {
+		path = r.URL.Path
+	}
+	return rctx.Routes.Find(owl.NewRouteContext(), r.Method, path)
+}
+
+// serve runs next when a token is available, waiting in the backlog if
+// needed, or rejects the request.
+func (t *throttler) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	ctx := r.Context()
+
+	select {
+
+	case <-ctx.Done():
+		t.setRetryAfterHeaderIfNeeded(w, true)
+		http.Error(w, errContextCanceled, t.statusCode)
+		return
+
+	case btok := <-t.backlogTokens:
+		defer func() {
+			t.backlogTokens <- btok
+		}()
+
+		// Try to get a processing token immediately first
+		select {
+		case tok := <-t.tokens:
+			defer func() {
+				t.tokens <- tok
+			}()
+			next.ServeHTTP(w, r)
+			return
+		default:
+			// No immediate token available, need to wait with timer
 		}
 
-		return http.HandlerFunc(fn)
+		timer := time.NewTimer(t.backlogTimeout)
+		select {
+		case <-timer.C:
+			t.setRetryAfterHeaderIfNeeded(w, false)
+			http.Error(w, errTimedOut, t.statusCode)
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			t.setRetryAfterHeaderIfNeeded(w, true)
+			http.Error(w, errContextCanceled, t.statusCode)
+			return
+		case tok := <-t.tokens:
+			defer func() {
+				timer.Stop()
+				t.tokens <- tok
+			}()
+			next.ServeHTTP(w, r)
+		}
+		return
+
+	default:
+		t.setRetryAfterHeaderIfNeeded(w, false)
+		http.Error(w, errCapacityExceeded, t.statusCode)
+		return
 	}
 }
 
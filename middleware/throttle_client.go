@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ThrottleClientOpts represents a set of per-client throttling options.
+type ThrottleClientOpts struct {
+	// Limit is the max number of in-flight requests per client.
+	Limit int
+
+	// KeyFn identifies the client of a request, e.g. by API key. The
+	// default uses the remote IP, so install RealIP first when behind a
+	// trusted proxy.
+	KeyFn func(r *http.Request) string
+
+	// QueueTimeout is how long a request waits for a slot of its client
+	// before being rejected (default: 0, reject immediately).
+	QueueTimeout time.Duration
+
+	// StatusCode is sent when a request is rejected (default: 429).
+	StatusCode int
+
+	// RetryAfter, when set, is sent as the Retry-After header on rejection.
+	RetryAfter time.Duration
+}
+
+// ThrottleClient is a middleware that limits the number of in-flight
+// requests per client IP, so one heavy client can't monopolize worker
+// capacity shared with others. Unlike Throttle, which caps concurrency
+// across all users, each client gets its own limit.
+func ThrottleClient(limit int) func(http.Handler) http.Handler {
+	return ThrottleClientWithOpts(ThrottleClientOpts{Limit: limit})
+}
+
+// ThrottleClientWithOpts is a middleware that limits the number of in-flight
+// requests per client using passed ThrottleClientOpts.
+func ThrottleClientWithOpts(opts ThrottleClientOpts) func(http.Handler) http.Handler {
+	if opts.Limit < 1 {
+		panic("chi/middleware: ThrottleClient expects limit > 0")
+	}
+	if opts.KeyFn == nil {
+		opts.KeyFn = remoteIP
+	}
+	if opts.StatusCode == 0 {
+		opts.StatusCode = http.StatusTooManyRequests
+	}
+
+	ct := &clientThrottler{limit: opts.Limit, clients: map[string]*clientSlots{}}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := opts.KeyFn(r)
+			slots := ct.acquire(key)
+			defer ct.release(key)
+
+			select {
+			case slots.tokens <- token{}:
+			default:
+				if opts.QueueTimeout <= 0 {
+					rejectThrottled(w, opts, errCapacityExceeded)
+					return
+				}
+				timer := time.NewTimer(opts.QueueTimeout)
+				defer timer.Stop()
+				select {
+				case slots.tokens <- token{}:
+				case <-timer.C:
+					rejectThrottled(w, opts, errTimedOut)
+					return
+				case <-r.Context().Done():
+					rejectThrottled(w, opts, errContextCanceled)
+					return
+				}
+			}
+			defer func() { <-slots.tokens }()
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// rejectThrottled answers a request over the limit of its client.
+// Retry-After is rounded up to whole seconds, so that it is never 0.
+func rejectThrottled(w http.ResponseWriter, opts ThrottleClientOpts, msg string) {
+	if opts.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(opts.RetryAfter.Seconds()))))
+	}
+	http.Error(w, msg, opts.StatusCode)
+}
+
+// clientThrottler keeps a semaphore per client. Entries are reference
+// counted and removed once no request of the client is pending, so the map
+// only holds active clients.
+type clientThrottler struct {
+	mu      sync.Mutex
+	limit   int
+	clients map[string]*clientSlots
+}
+
+type clientSlots struct {
+	tokens chan token
+	refs   int
+}
+
+func (ct *clientThrottler) acquire(key string) *clientSlots {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	s, ok := ct.clients[key]
+	if !ok {
+		s = &clientSlots{tokens: make(chan token, ct.limit)}
+		ct.clients[key] = s
+	}
+	s.refs++
+	return s
+}
+
+func (ct *clientThrottler) release(key string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if s := ct.clients[key]; s != nil {
+		if s.refs--; s.refs == 0 {
+			delete(ct.clients, key)
+		}
+	}
+}
+
+// remoteIP returns the IP part of the request's RemoteAddr.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestThrottleClient(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+
+	r := owl.NewRouter()
+	r.Use(ThrottleClientWithOpts(ThrottleClientOpts{
+		Limit:        1,
+		KeyFn:        func(r *http.Request) string { return r.Header.Get("X-Api-Key") },
+		QueueTimeout: 20 * time.Millisecond,
+		RetryAfter:   500 * time.Millisecond,
+	}))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte("ok"))
+	})
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Api-Key", key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		do("heavy")
+	}()
+	<-started
+
+	// The heavy client is at its limit and gets rejected after queueing.
+	if w := do("heavy"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+
+	// Another client is unaffected.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if w := do("light"); w.Code != http.StatusOK {
+			t.Errorf("other client should pass, got %d", w.Code)
+		}
+	}()
+	<-started
+	close(release)
+	wg.Wait()
+}
@@ -328,3 +328,37 @@ func BenchmarkThrottle(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestThrottlePerRoute(t *testing.T) {
+	r := owl.NewRouter()
+	r.Use(ThrottleWithOpts(ThrottleOpts{
+		Limit:        1,
+		StatusCode:   http.StatusServiceUnavailable,
+		RetryAfterFn: func(bool) time.Duration { return 10 * time.Second },
+		PerRoute:     true,
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	r.Get("/reports/{id}", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/reports/1", nil))
+	<-started
+	defer close(release)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/reports/2", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "10" {
+		t.Fatalf("expected the busy route to shed with 503, got %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected other routes to keep their capacity, got %d", w.Code)
+	}
+}
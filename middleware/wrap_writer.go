@@ -8,6 +8,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+
+	"github.com/go-owl/owl"
 )
 
 // NewWrapResponseWriter wraps an http.ResponseWriter, returning a proxy that allows you to
@@ -43,6 +45,17 @@ func NewWrapResponseWriter(w http.ResponseWriter, protoMajor int) WrapResponseWr
 	return &bw
 }
 
+// trackResponse returns w and its owl.ResponseState when w already tracks
+// one, such as the writer of an owl.App or a WrapResponseWriter, or else a
+// new WrapResponseWriter, so that stacked middlewares share one wrapper.
+func trackResponse(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, owl.ResponseState) {
+	if state, ok := owl.ResponseStateOf(w); ok {
+		return w, state
+	}
+	ww := NewWrapResponseWriter(w, r.ProtoMajor)
+	return ww, ww
+}
+
 // WrapResponseWriter is a proxy around an http.ResponseWriter that allows you to hook
 // into various parts of the response process.
 type WrapResponseWriter interface {
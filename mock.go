@@ -0,0 +1,175 @@
+package owl
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MockConfig configures App.Mock.
+type MockConfig struct {
+	Latency        time.Duration // Delay added before every response
+	Jitter         time.Duration // Random extra delay in [0, Jitter)
+	ScenarioHeader string        // Request header selecting a scenario (default: "X-Mock-Scenario")
+}
+
+// Mock serves example responses from fsys for every request below prefix,
+// so frontend teams can build against an API before it is implemented.
+// Fixtures are looked up by path and method, relative to prefix:
+//
+//	GET.json                  GET    /api/v1/orders
+//	POST.201.json             POST   /api/v1/orders, answered with 201
+//	_/GET.json                GET    /api/v1/orders/{any}
+//	_/items/GET.json          GET    /api/v1/orders/{any}/items
+//	GET.empty.json            GET    /api/v1/orders, scenario "empty"
+//	GET.outage.503.json       GET    /api/v1/orders, scenario "outage", answered with 503
+//
+// A directory named "_" matches any path segment that has no directory of
+// its own. The scenario is chosen with the ScenarioHeader request header;
+// without it the fixture with no scenario is served. The Content-Type is
+// derived from the file extension and every mocked response carries an
+// X-Mock: true header.
+//
+//	app.Mock("/api/v1/orders", os.DirFS("mocks/orders"), owl.MockConfig{
+//		Latency: 200 * time.Millisecond,
+//	})
+//
+// Routes registered later for the same pattern and method replace the mock,
+// and more specific routes take precedence over it, so the mock can stay in
+// place while endpoints are implemented one by one.
+func (a *App) Mock(prefix string, fsys fs.FS, config ...MockConfig) *App {
+	cfg := MockConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.ScenarioHeader == "" {
+		cfg.ScenarioHeader = "X-Mock-Scenario"
+	}
+
+	h := &mockHandler{fsys: fsys, cfg: cfg}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" {
+		a.mux.Handle(prefix, h)
+	}
+	a.mux.Handle(prefix+"/*", h)
+	return a
+}
+
+type mockHandler struct {
+	fsys fs.FS
+	cfg  MockConfig
+}
+
+func (h *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	dir, err := h.lookupDir(URLParam(r, "*"))
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	name, status, err := h.lookupFixture(dir, r.Method, r.Header.Get(h.cfg.ScenarioHeader))
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	data, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+
+	if delay := h.delay(); delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		}
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Mock", "true")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// lookupDir resolves the fixture directory for the request path, falling
+// back to "_" for segments without a directory of their own.
+func (h *mockHandler) lookupDir(rest string) (string, error) {
+	dir := "."
+	for _, seg := range strings.Split(strings.Trim(path.Clean("/"+rest), "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if fi, err := fs.Stat(h.fsys, path.Join(dir, seg)); err == nil && fi.IsDir() {
+			dir = path.Join(dir, seg)
+			continue
+		}
+		if fi, err := fs.Stat(h.fsys, path.Join(dir, "_")); err == nil && fi.IsDir() {
+			dir = path.Join(dir, "_")
+			continue
+		}
+		return "", fs.ErrNotExist
+	}
+	return dir, nil
+}
+
+// lookupFixture finds the fixture for method and scenario in dir and returns
+// its name and response status.
+func (h *mockHandler) lookupFixture(dir, method, scenario string) (string, int, error) {
+	entries, err := fs.ReadDir(h.fsys, dir)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m, s, status := parseFixtureName(e.Name())
+		if m == method && s == scenario {
+			return path.Join(dir, e.Name()), status, nil
+		}
+	}
+	return "", 0, fs.ErrNotExist
+}
+
+// parseFixtureName splits a fixture file name of the form
+// METHOD[.scenario][.status].ext.
+func parseFixtureName(name string) (method, scenario string, status int) {
+	parts := strings.Split(strings.TrimSuffix(name, path.Ext(name)), ".")
+	method, parts = parts[0], parts[1:]
+	status = http.StatusOK
+	if n := len(parts); n > 0 && len(parts[n-1]) == 3 {
+		if code, err := strconv.Atoi(parts[n-1]); err == nil && code >= 100 && code <= 599 {
+			status, parts = code, parts[:n-1]
+		}
+	}
+	return method, strings.Join(parts, "."), status
+}
+
+func (h *mockHandler) delay() time.Duration {
+	d := h.cfg.Latency
+	if h.cfg.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(h.cfg.Jitter)))
+	}
+	return d
+}
+
+func (h *mockHandler) error(w http.ResponseWriter, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, "no mock fixture for this request", http.StatusNotFound)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,80 @@
+package owl
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMock(t *testing.T) {
+	fsys := fstest.MapFS{
+		"GET.json":            {Data: []byte(`[{"id":1}]`)},
+		"GET.empty.json":      {Data: []byte(`[]`)},
+		"GET.outage.503.json": {Data: []byte(`{"error":"down"}`)},
+		"POST.201.json":       {Data: []byte(`{"id":2}`)},
+		"_/GET.json":          {Data: []byte(`{"id":1}`)},
+		"_/items/GET.xml":     {Data: []byte(`<items/>`)},
+		"export/GET.json":     {Data: []byte(`"export"`)},
+	}
+
+	app := New()
+	app.GET("/api/v1/orders/{id}/invoice", func(c *Ctx) error { return c.Text("real") })
+	app.Mock("/api/v1/orders", fsys)
+
+	tests := []struct {
+		method, path, scenario string
+		code                   int
+		body, ctype            string
+	}{
+		{"GET", "/api/v1/orders", "", 200, `[{"id":1}]`, "application/json"},
+		{"GET", "/api/v1/orders", "empty", 200, `[]`, "application/json"},
+		{"GET", "/api/v1/orders", "outage", 503, `{"error":"down"}`, "application/json"},
+		{"POST", "/api/v1/orders", "", 201, `{"id":2}`, "application/json"},
+		{"GET", "/api/v1/orders/42", "", 200, `{"id":1}`, "application/json"},
+		{"GET", "/api/v1/orders/42/items", "", 200, "<items/>", "text/xml; charset=utf-8"},
+		{"GET", "/api/v1/orders/export", "", 200, `"export"`, "application/json"},
+		{"GET", "/api/v1/orders/42/invoice", "", 200, "real", "text/plain; charset=utf-8"},
+		{"DELETE", "/api/v1/orders", "", 404, "", ""},
+		{"GET", "/api/v1/orders", "missing", 404, "", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.scenario != "" {
+			r.Header.Set("X-Mock-Scenario", tt.scenario)
+		}
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s %s [%s]: got status %d, want %d", tt.method, tt.path, tt.scenario, w.Code, tt.code)
+			continue
+		}
+		if tt.code == 404 {
+			continue
+		}
+		if w.Body.String() != tt.body || w.Header().Get("Content-Type") != tt.ctype {
+			t.Errorf("%s %s [%s]: got %q (%s)", tt.method, tt.path, tt.scenario, w.Body.String(), w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestMockLatency(t *testing.T) {
+	app := New()
+	app.Mock("/slow", fstest.MapFS{"GET.json": {Data: []byte(`{}`)}}, MockConfig{Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if time.Since(start) < 50*time.Millisecond || w.Header().Get("X-Mock") != "true" {
+		t.Fatalf("expected delayed mock response, got %d after %v", w.Code, time.Since(start))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil).WithContext(ctx))
+	if w.Body.Len() != 0 {
+		t.Fatalf("canceled request should not be answered, got %q", w.Body.String())
+	}
+}
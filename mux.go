@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -45,6 +46,21 @@ type Mux struct {
 	// Controls the behaviour of middleware chain generation when a mux
 	// is registered as an inline group inside another mux.
 	inline bool
+
+	// autoHead serves HEAD requests with the GET handler when no HEAD
+	// handler is registered for the route.
+	autoHead bool
+
+	// autoOptions answers OPTIONS requests with the allowed methods when no
+	// OPTIONS handler is registered for the route.
+	autoOptions bool
+
+	// trailingSlash is the policy for paths that only differ from a route
+	// by a trailing slash.
+	trailingSlash TrailingSlashPolicy
+
+	// caseInsensitive matches request paths regardless of ASCII letter case.
+	caseInsensitive bool
 }
 
 // NewMux returns a newly initialized Mux object that implements the Router
@@ -251,6 +267,8 @@ func (mx *Mux) With(middlewares ...func(http.Handler) http.Handler) Router {
 	im := &Mux{
 		pool: mx.pool, inline: true, parent: mx, tree: mx.tree, middlewares: mws,
 		notFoundHandler: mx.notFoundHandler, methodNotAllowedHandler: mx.methodNotAllowedHandler,
+		autoHead: mx.autoHead, autoOptions: mx.autoOptions,
+		trailingSlash: mx.trailingSlash, caseInsensitive: mx.caseInsensitive,
 	}
 
 	return im
@@ -418,6 +436,10 @@ func (mx *Mux) handle(method methodTyp, pattern string, handler http.Handler) *n
 		panic(fmt.Sprintf("chi: routing pattern must begin with '/' in '%s'", pattern))
 	}
 
+	if mx.caseInsensitive {
+		pattern = lowerStatic(pattern)
+	}
+
 	// Build the computed routing handler for this routing pattern.
 	if !mx.inline && mx.handler == nil {
 		mx.updateRouteHandler()
@@ -465,8 +487,22 @@ func (mx *Mux) routeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if mx.trailingSlash == TrailingSlashStrip && len(routePath) > 1 {
+		if routePath = strings.TrimRight(routePath, "/"); routePath == "" {
+			routePath = "/"
+		}
+	}
+	searchPath := routePath
+	if mx.caseInsensitive {
+		searchPath = asciiLower(routePath)
+	}
+
 	// Find the route
-	if _, _, h := mx.tree.FindRoute(rctx, method, routePath); h != nil {
+	if _, _, h := mx.tree.FindRoute(rctx, method, searchPath); h != nil {
+		if searchPath != routePath {
+			restoreParamCase(rctx, routePath)
+		}
+
 		// Set http.Request path values from our request context
 		for i, key := range rctx.URLParams.Keys {
 			value := rctx.URLParams.Values[i]
@@ -480,10 +516,34 @@ func (mx *Mux) routeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if rctx.methodNotAllowed {
+		if mx.autoHead && method == mHEAD && hasMethod(rctx.methodsAllowed, mGET) {
+			rctx.RouteMethod = http.MethodGet
+			rctx.methodNotAllowed = false
+			rctx.methodsAllowed = rctx.methodsAllowed[:0]
+			mx.routeHTTP(&headWriter{w}, r)
+			return
+		}
+		if mx.autoOptions && method == mOPTIONS {
+			// Copy the methods, which are kept in the pooled routing context.
+			allowed := append([]methodTyp(nil), rctx.methodsAllowed...)
+			if mx.autoHead && hasMethod(allowed, mGET) && !hasMethod(allowed, mHEAD) {
+				allowed = append(allowed, mHEAD)
+			}
+			allowed = append(allowed, mOPTIONS)
+			sort.Slice(allowed, func(i, j int) bool { return allowed[i] < allowed[j] })
+			for _, m := range allowed {
+				w.Header().Add("Allow", reverseMethodMap[m])
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 		mx.MethodNotAllowedHandler(rctx.methodsAllowed...).ServeHTTP(w, r)
-	} else {
-		mx.NotFoundHandler().ServeHTTP(w, r)
+		return
+	}
+	if mx.trailingSlash == TrailingSlashRedirect && mx.redirectTrailingSlash(w, r, method, searchPath) {
+		return
 	}
+	mx.NotFoundHandler().ServeHTTP(w, r)
 }
 
 func (mx *Mux) nextRoutePath(rctx *Context) string {
@@ -514,6 +574,39 @@ func (mx *Mux) updateRouteHandler() {
 	mx.handler = chain(mx.middlewares, http.HandlerFunc(mx.routeHTTP))
 }
 
+// hasMethod reports whether m is in methods.
+func hasMethod(methods []methodTyp, m methodTyp) bool {
+	for _, x := range methods {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
+
+// headWriter discards the response body so a GET handler can answer a
+// HEAD request with the same status and headers.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Flush sends the status and headers written so far, for handlers that
+// stream their response.
+func (w *headWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // methodNotAllowedHandler is a helper function to respond with a 405,
 // method not allowed. It sets the Allow header with the list of allowed
 // methods for the route.
@@ -0,0 +1,443 @@
+package owl
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// OpenAPI is an OpenAPI 3.1 document, see App.OpenAPI.
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"` // Operations by path and lowercase method
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIInfo describes the API.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIComponents holds the schemas of the named struct types used by
+// operations, referenced as "#/components/schemas/<name>".
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// OpenAPIOperation documents a route.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"` // By status code, or "default"
+}
+
+// OpenAPIParameter documents a path or query parameter.
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// OpenAPIRequestBody documents the body of a request, by media type.
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse documents a response.
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a body.
+type OpenAPIMediaType struct {
+	Schema  *Schema     `json:"schema"`
+	Example interface{} `json:"example,omitempty"`
+}
+
+// Doc documents a route in the OpenAPI document, see RouteBuilder.Doc.
+// Request and the values of Responses are only used for their type, so
+// zero values do:
+//
+//	owl.Doc{
+//		Summary:   "Create a user",
+//		Request:   CreateUserRequest{},
+//		Responses: map[int]interface{}{201: User{}, 409: APIError{}},
+//	}
+type Doc struct {
+	Summary     string
+	Description string
+	Tags        []string // Added to the tags declared with Group.Tag
+	OperationID string   // Default: the method and path, e.g. "getUsersId"
+	Deprecated  bool
+
+	Request   interface{}         // Query parameters and body, see App.OpenAPI
+	Responses map[int]interface{} // Bodies by status, nil for none
+
+	// Example is a request body shown in the OpenAPI document and sent by
+	// the requests of App.Postman, instead of one made up from Request.
+	Example interface{}
+}
+
+// Doc documents the routes registered by the previous call, see
+// RouteBuilder.Doc.
+func (a *App) Doc(doc Doc) *App {
+	document(a.last, doc)
+	return a
+}
+
+// Doc documents the routes registered by the previous call, see
+// RouteBuilder.Doc.
+func (g *Group) Doc(doc Doc) *Group {
+	document(g.last, doc)
+	return g
+}
+
+// Doc documents the handlers registered by the previous call in the
+// OpenAPI document, and in the output of App.DumpMiddlewares:
+//
+//	v1.Route("/users").POST(createUser).Doc(owl.Doc{
+//		Summary:   "Create a user",
+//		Tags:      []string{"users"},
+//		Request:   CreateUserRequest{},
+//		Responses: map[int]interface{}{201: User{}},
+//	})
+func (rb *RouteBuilder) Doc(doc Doc) *RouteBuilder {
+	document(rb.last, doc)
+	return rb
+}
+
+func document(routes []*conditionalRoute, doc Doc) {
+	if len(routes) == 0 {
+		panic("owl: Doc must follow a route registration")
+	}
+	for _, route := range routes {
+		d := doc
+		if route.doc != nil {
+			// Keep the types of handlers made with H.
+			if d.Request == nil {
+				d.Request = route.doc.Request
+			}
+			if d.Responses == nil {
+				d.Responses = route.doc.Responses
+			}
+			if d.Example == nil {
+				d.Example = route.doc.Example
+			}
+		}
+		route.doc = &d
+	}
+}
+
+// openAPIMethods are the methods OpenAPI has operations for.
+var openAPIMethods = map[string]bool{
+	http.MethodGet: true, http.MethodPut: true, http.MethodPost: true, http.MethodDelete: true,
+	http.MethodOptions: true, http.MethodHead: true, http.MethodPatch: true, http.MethodTrace: true,
+}
+
+// OpenAPI generates the OpenAPI 3.1 document of the routes registered on
+// the App, titled with AppConfig.Name and Version. Every route gets an
+// operation with its path parameters, typed after the constraints of the
+// pattern such as {id:int}, and the tags declared with Group.Tag.
+// Routes documented with Doc get their summary, and their query parameters
+// and request and response bodies described from the request and response
+// types, reflecting their json, query and form tags, and their doc and
+// deprecated tags.
+//
+// Routes matching every method or a method OpenAPI doesn't know, routes
+// registered with Host and routes of Apps attached with Mount are not
+// listed.
+func (a *App) OpenAPI() *OpenAPI {
+	a.compile()
+	gen := newSchemaGen("#/components/schemas/")
+	spec := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info:    OpenAPIInfo{Title: a.name, Version: a.version},
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+
+	keys := make([]routeKey, 0, len(a.routes))
+	for key := range a.routes {
+		if key.mux == a.mux && openAPIMethods[key.method] {
+			keys = append(keys, key)
+		}
+	}
+	// Sorted so that colliding schema names are qualified the same way
+	// every time.
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	for _, key := range keys {
+		route := documentedRoute(a.routes[key])
+		path, params := openAPIPath(key.path, route.wildcard)
+		op := &OpenAPIOperation{
+			OperationID: operationID(key.method, path),
+			Tags:        route.opts.tags,
+			Parameters:  params,
+			Responses:   map[string]*OpenAPIResponse{},
+		}
+		if doc := route.doc; doc != nil {
+			op.Summary, op.Description, op.Deprecated = doc.Summary, doc.Description, doc.Deprecated
+			if doc.OperationID != "" {
+				op.OperationID = doc.OperationID
+			}
+			op.Tags = append(op.Tags[:len(op.Tags):len(op.Tags)], doc.Tags...)
+			gen.describeRequest(op, key.method, doc.Request)
+			gen.describeResponses(op, doc.Responses)
+			if doc.Example != nil {
+				if op.RequestBody == nil {
+					op.RequestBody = &OpenAPIRequestBody{Required: true, Content: map[string]*OpenAPIMediaType{
+						"application/json": {Schema: &Schema{}},
+					}}
+				}
+				for _, media := range op.RequestBody.Content {
+					media.Example = doc.Example
+				}
+			}
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["default"] = &OpenAPIResponse{Description: "Response"}
+		}
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = map[string]*OpenAPIOperation{}
+		}
+		spec.Paths[path][strings.ToLower(key.method)] = op
+	}
+
+	if len(gen.defs) > 0 {
+		spec.Components = &OpenAPIComponents{Schemas: gen.defs}
+	}
+	return spec
+}
+
+// MountOpenAPI registers GET /openapi.json, serving the document generated
+// by OpenAPI. Middlewares, such as authentication, apply to it.
+func (a *App) MountOpenAPI(middlewares ...Middleware) *App {
+	return a.GET("/openapi.json", func(c *Ctx) error {
+		return c.JSON(a.OpenAPI())
+	}, middlewares...)
+}
+
+// documentedRoute picks the route of set described in the document: the
+// first one documented with Doc, or the unconditional one.
+func documentedRoute(set *routeSet) *conditionalRoute {
+	for _, route := range set.routes {
+		if route.doc != nil {
+			return route
+		}
+	}
+	return set.routes[len(set.routes)-1]
+}
+
+// openAPIPath converts a routing pattern to an OpenAPI path template, e.g.
+// "/users/{id:int}" to "/users/{id}", and returns its parameters. The
+// catch-all is named after wildcard, or "path".
+func openAPIPath(pattern, wildcard string) (string, []*OpenAPIParameter) {
+	var b strings.Builder
+	var params []*OpenAPIParameter
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			// Placeholders may hold regexps with braces, such as {:\d{4}}.
+			depth, end := 0, i
+			for ; end < len(pattern); end++ {
+				if pattern[end] == '{' {
+					depth++
+				} else if pattern[end] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			name, expr, _ := strings.Cut(pattern[i+1:end], ":")
+			if name == "" {
+				name = "param" + strconv.Itoa(len(params)+1)
+			}
+			params = append(params, &OpenAPIParameter{Name: name, In: "path", Required: true, Schema: paramSchema(expr)})
+			b.WriteString("{" + name + "}")
+			i = end
+		case '*':
+			name := wildcard
+			if name == "" {
+				name = "path"
+			}
+			params = append(params, &OpenAPIParameter{
+				Name: name, In: "path", Required: true,
+				Description: "Rest of the path, including slashes",
+				Schema:      &Schema{Type: "string"},
+			})
+			b.WriteString("{" + name + "}")
+			return b.String(), params
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String(), params
+}
+
+// paramSchema returns the schema of a path parameter constrained by expr,
+// the name of a param type or a regular expression.
+func paramSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: "string"}
+	case "int", "uint":
+		return &Schema{Type: "integer"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	if re, ok := paramTypes[expr]; ok {
+		expr = re
+	}
+	return &Schema{Type: "string", Pattern: "^" + expr + "$"}
+}
+
+// operationID names an operation after its method and path, e.g.
+// "getUsersId" for GET /users/{id}.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// describeRequest adds the query parameters and body of the request type
+// of a route to op. Methods without a body take every field from the
+// query, like Binder.Query; the others take fields tagged query from the
+// query and the rest from a JSON body, or a form when fields are tagged
+// form.
+func (g *schemaGen) describeRequest(op *OpenAPIOperation, method string, request interface{}) {
+	t := reflect.TypeOf(request)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	hasBody := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+	var query, form, files bool
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("json") == "-" || hasTag(f, "param") {
+			continue
+		}
+		if hasBody && !hasTag(f, "query") {
+			form = form || hasTag(f, "form")
+			files = files || f.Type == fileHeaderType || f.Type == reflect.SliceOf(fileHeaderType)
+			continue
+		}
+		query = true
+		name := tagName(f, "form", "query", "json")
+		if hasBody {
+			name = tagName(f, "query")
+		}
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{
+			Name:        name,
+			In:          "query",
+			Description: f.Tag.Get("doc"),
+			Deprecated:  hasTag(f, "deprecated"),
+			Schema:      g.schema(f.Type),
+		})
+	}
+	if !hasBody {
+		return
+	}
+
+	body := &OpenAPIRequestBody{Required: true, Content: map[string]*OpenAPIMediaType{}}
+	switch {
+	case files:
+		body.Content["multipart/form-data"] = &OpenAPIMediaType{Schema: g.formSchema(t)}
+	case form:
+		body.Content["application/x-www-form-urlencoded"] = &OpenAPIMediaType{Schema: g.formSchema(t)}
+	case !query && !hasTaggedField(t, "param") && t.Name() != "":
+		body.Content["application/json"] = &OpenAPIMediaType{Schema: g.schema(t)}
+	default:
+		body.Content["application/json"] = &OpenAPIMediaType{Schema: g.structSchema(t, inBody)}
+	}
+	op.RequestBody = body
+}
+
+// inBody reports whether the field f is bound from the body by H.
+func inBody(f reflect.StructField) bool {
+	return !hasTag(f, "query") && !hasTag(f, "param")
+}
+
+// formSchema returns the schema of the fields of t sent as a form, named
+// like Binder.Form names them.
+func (g *schemaGen) formSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || hasTag(f, "query") || hasTag(f, "param") {
+			continue
+		}
+		fs := g.schema(f.Type)
+		fs.Description = f.Tag.Get("doc")
+		fs.Deprecated = hasTag(f, "deprecated")
+		s.Properties[tagName(f, "form", "json")] = fs
+	}
+	return s
+}
+
+// hasTaggedField reports whether a field of struct type t is tagged key.
+func hasTaggedField(t reflect.Type, key string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if hasTag(t.Field(i), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeResponses adds the responses of a route to op, encoded as JSON.
+func (g *schemaGen) describeResponses(op *OpenAPIOperation, responses map[int]interface{}) {
+	codes := make([]int, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		v := responses[code]
+		resp := &OpenAPIResponse{Description: http.StatusText(code)}
+		if resp.Description == "" {
+			resp.Description = "Response"
+		}
+		if v != nil && code != http.StatusNoContent {
+			resp.Content = map[string]*OpenAPIMediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(v))},
+			}
+		}
+		op.Responses[strconv.Itoa(code)] = resp
+	}
+}
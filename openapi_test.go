@@ -0,0 +1,110 @@
+package owl
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type apiUser struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name" doc:"Display name"`
+	Email    string    `json:"email,omitempty"`
+	Manager  *apiUser  `json:"manager"`
+	Created  time.Time `json:"created"`
+	Nickname string    `json:"nickname,omitempty" deprecated:"use name"`
+}
+
+type createUserRequest struct {
+	Name   string `json:"name"`
+	DryRun bool   `query:"dry_run"`
+}
+
+type listUsersRequest struct {
+	Page  int    `query:"page"`
+	Query string `json:"q"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	ok := func(c *Ctx) error { return nil }
+	app := New(AppConfig{Name: "users", Version: "2.1.0"})
+	api := app.Group("/api").Tag("users")
+	api.GET("/users", ok).Doc(Doc{
+		Request:   listUsersRequest{},
+		Responses: map[int]interface{}{200: []apiUser{}},
+	})
+	api.Route("/users").POST(ok).Doc(Doc{
+		Summary:     "Create a user",
+		Tags:        []string{"admin"},
+		OperationID: "createUser",
+		Request:     createUserRequest{},
+		Responses:   map[int]interface{}{201: apiUser{}, 400: nil},
+	})
+	api.GET("/users/{id:int}/files/*filepath", ok)
+	app.GET("/date/{:\\d{4}}", ok)
+	app.ANY("/any", ok)
+	app.MountOpenAPI()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	var spec OpenAPI
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.OpenAPI != "3.1.0" || spec.Info.Title != "users" || spec.Info.Version != "2.1.0" {
+		t.Fatalf("unexpected header %+v", spec)
+	}
+	if _, ok := spec.Paths["/any"]; ok {
+		t.Error("routes matching every method shouldn't be listed")
+	}
+
+	list := spec.Paths["/api/users"]["get"]
+	if list == nil || list.OperationID != "getApiUsers" || strings.Join(list.Tags, ",") != "users" {
+		t.Fatalf("unexpected list operation %+v", list)
+	}
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "page" || list.Parameters[1].Name != "q" || list.RequestBody != nil {
+		t.Fatalf("expected every field of GET requests in the query, got %+v", list.Parameters)
+	}
+	if items := list.Responses["200"].Content["application/json"].Schema.Items; items.Ref != "#/components/schemas/apiUser" {
+		t.Fatalf("expected a reference to the user schema, got %+v", items)
+	}
+
+	create := spec.Paths["/api/users"]["post"]
+	if create.OperationID != "createUser" || create.Summary != "Create a user" || strings.Join(create.Tags, ",") != "users,admin" {
+		t.Fatalf("expected the documentation of the route, got %+v", create)
+	}
+	if len(create.Parameters) != 1 || create.Parameters[0].Name != "dry_run" || create.Parameters[0].In != "query" {
+		t.Fatalf("expected the query field as a parameter, got %+v", create.Parameters)
+	}
+	body := create.RequestBody.Content["application/json"].Schema
+	if len(body.Properties) != 1 || body.Properties["name"].Type != "string" {
+		t.Fatalf("expected the body to only hold JSON fields, got %+v", body)
+	}
+	if create.Responses["201"].Content == nil || create.Responses["400"].Description != "Bad Request" || create.Responses["400"].Content != nil {
+		t.Fatalf("unexpected responses %+v", create.Responses)
+	}
+
+	files := spec.Paths["/api/users/{id}/files/{filepath}"]["get"]
+	if files == nil || len(files.Parameters) != 2 || files.Parameters[0].Schema.Type != "integer" || files.Parameters[1].Name != "filepath" {
+		t.Fatalf("unexpected path parameters %+v", files)
+	}
+	if files.Responses["default"] == nil {
+		t.Fatal("expected a default response for undocumented routes")
+	}
+	if date := spec.Paths["/date/{param1}"]["get"]; date == nil || date.Parameters[0].Schema.Pattern != `^\d{4}$` {
+		t.Fatalf("expected the regexp as a pattern, got %+v", spec.Paths)
+	}
+
+	user := spec.Components.Schemas["apiUser"]
+	if user == nil || user.Properties["manager"].Ref != "#/components/schemas/apiUser" {
+		t.Fatalf("expected the recursive user schema, got %+v", user)
+	}
+	if got := strings.Join(user.Required, ","); got != "id,name,created" {
+		t.Errorf("unexpected required fields %s", got)
+	}
+	if user.Properties["created"].Format != "date-time" || user.Properties["name"].Description != "Display name" || !user.Properties["nickname"].Deprecated {
+		t.Errorf("unexpected properties %+v", user.Properties)
+	}
+}
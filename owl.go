@@ -40,9 +40,16 @@
 // matched. An anonymous regexp pattern is allowed, using an empty string
 // before the colon in the placeholder, such as {:\\d+}
 //
+// Instead of a regular expression, a placeholder may name a built-in
+// constraint: {id:int}, {id:uint}, {name:alpha}, {code:alnum}, {s:slug} or
+// {id:uuid}. More can be added with RegisterParamType. Requests whose
+// segment doesn't satisfy the constraint don't match the route.
+//
 // The special placeholder of asterisk matches the rest of the requested
 // URL. Any trailing characters in the pattern are ignored. This is the only
-// placeholder which will match / characters.
+// placeholder which will match / characters. Routes registered through an
+// App may name it, as in "/files/*filepath", making the value available
+// under that name as well as "*".
 //
 // Examples:
 //
@@ -0,0 +1,15 @@
+module github.com/go-owl/owl/owlautocert
+
+go 1.22
+
+require (
+	github.com/go-owl/owl v1.0.0
+	golang.org/x/crypto v0.31.0
+)
+
+require (
+	golang.org/x/net v0.21.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+
+replace github.com/go-owl/owl => ../
@@ -0,0 +1,173 @@
+// Package owlautocert serves an Owl App over HTTPS with certificates
+// obtained and renewed automatically from Let's Encrypt, for small
+// deployments without a reverse proxy terminating TLS:
+//
+//	app := owl.New()
+//	app.GET("/", home)
+//	log.Fatal(owlautocert.GracefulAutoTLS(app, "/var/cache/certs", "example.com", "www.example.com"))
+//
+// ACME challenges are answered with TLS-ALPN-01 on the HTTPS listener and
+// HTTP-01 on the HTTP one, which otherwise redirects to HTTPS. Certificates
+// are cached in a directory so restarts don't hit the Let's Encrypt rate
+// limits.
+package owlautocert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-owl/owl"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Config configures Run.
+type Config struct {
+	// Domains are the host names certificates are requested for. Requests
+	// for other hosts fail the TLS handshake.
+	Domains []string
+
+	// CacheDir stores the account key and certificates (required).
+	CacheDir string
+
+	// Email is the contact address of the ACME account, used by Let's
+	// Encrypt for expiry notices (default: none).
+	Email string
+
+	// HTTPAddr serves HTTP-01 challenges and redirects to HTTPS
+	// (default: ":80").
+	HTTPAddr string
+
+	// HTTPSAddr serves the App (default: ":443").
+	HTTPSAddr string
+
+	// ShutdownTimeout is the deadline for in-flight requests once shutdown
+	// starts (default: 30s).
+	ShutdownTimeout time.Duration
+
+	// Manager replaces the certificate manager built from the fields
+	// above, e.g. to use the Let's Encrypt staging directory.
+	Manager *autocert.Manager
+}
+
+// Manager returns an autocert.Manager accepting the Let's Encrypt terms of
+// service, caching in cacheDir and limited to domains. Its TLSConfig can be
+// set as owl.AppConfig.TLSConfig of Apps run by an owl.Supervisor, along
+// with an App on :80 using its HTTPHandler as middleware:
+//
+//	m := owlautocert.Manager("/var/cache/certs", "example.com")
+//	app := owl.New(owl.AppConfig{TLSConfig: m.TLSConfig()})
+//	challenges := owl.New()
+//	challenges.Use(m.HTTPHandler)
+//	sup := owl.NewSupervisor()
+//	sup.AddTLS(":443", app, "", "").Add(":80", challenges)
+func Manager(cacheDir string, domains ...string) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cacheDir),
+		HostPolicy: autocert.HostWhitelist(domains...),
+	}
+}
+
+// GracefulAutoTLS serves app over HTTPS on :443 for domains, with
+// certificates cached in cacheDir, until SIGINT or SIGTERM. See Run.
+func GracefulAutoTLS(app *owl.App, cacheDir string, domains ...string) error {
+	return Run(context.Background(), app, Config{Domains: domains, CacheDir: cacheDir})
+}
+
+// Run builds app and serves it over HTTPS until ctx is done, SIGINT or
+// SIGTERM is received or a listener fails, then shuts both listeners down
+// within ShutdownTimeout. It returns nil after a clean stop.
+func Run(ctx context.Context, app *owl.App, config Config) error {
+	m := config.Manager
+	if m == nil {
+		if config.CacheDir == "" {
+			return errors.New("owlautocert: CacheDir is required")
+		}
+		if len(config.Domains) == 0 {
+			return errors.New("owlautocert: no domains")
+		}
+		m = Manager(config.CacheDir, config.Domains...)
+		m.Email = config.Email
+	}
+	if config.HTTPAddr == "" {
+		config.HTTPAddr = ":80"
+	}
+	if config.HTTPSAddr == "" {
+		config.HTTPSAddr = ":443"
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+
+	if err := app.Build(); err != nil {
+		return err
+	}
+	httpsLn, err := net.Listen("tcp", config.HTTPSAddr)
+	if err != nil {
+		return err
+	}
+	httpLn, err := net.Listen("tcp", config.HTTPAddr)
+	if err != nil {
+		httpsLn.Close()
+		return err
+	}
+
+	srv := app.Listen(config.HTTPSAddr)
+	tlsConfig := m.TLSConfig()
+	if srv.TLSConfig != nil {
+		// Keep the settings of owl.AppConfig.TLSConfig, such as
+		// MinVersion, but let the manager provide the certificates.
+		tlsConfig = srv.TLSConfig
+		tlsConfig.GetCertificate = m.GetCertificate
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, acme.ALPNProto)
+	}
+	srv.TLSConfig = tlsConfig
+	challenges := &http.Server{
+		Handler:           m.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, 2)
+	go func() {
+		if err := srv.ServeTLS(httpsLn, "", ""); !errors.Is(err, http.ErrServerClosed) {
+			failed <- fmt.Errorf("owlautocert: %s: %w", config.HTTPSAddr, err)
+		}
+	}()
+	go func() {
+		if err := challenges.Serve(httpLn); !errors.Is(err, http.ErrServerClosed) {
+			failed <- fmt.Errorf("owlautocert: %s: %w", config.HTTPAddr, err)
+		}
+	}()
+	app.Logger().Info("server starting", "addr", config.HTTPSAddr, "domains", config.Domains)
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+	case err := <-failed:
+		errs = append(errs, err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := challenges.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
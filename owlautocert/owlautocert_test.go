@@ -0,0 +1,72 @@
+package owlautocert
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestRunConfig(t *testing.T) {
+	if err := Run(context.Background(), owl.New(), Config{Domains: []string{"example.com"}}); err == nil {
+		t.Fatal("expected an error without CacheDir")
+	}
+	if err := Run(context.Background(), owl.New(), Config{CacheDir: t.TempDir()}); err == nil {
+		t.Fatal("expected an error without domains")
+	}
+}
+
+func TestRun(t *testing.T) {
+	httpAddr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, owl.New(), Config{
+			Domains:   []string{"example.com"},
+			CacheDir:  t.TempDir(),
+			HTTPAddr:  httpAddr,
+			HTTPSAddr: freeAddr(t),
+		})
+	}()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		req, _ := http.NewRequest("GET", "http://"+httpAddr+"/docs?page=2", nil)
+		req.Host = "example.com"
+		if resp, err = client.Do(req); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "https://example.com/docs?page=2" {
+		t.Fatalf("expected a redirect to HTTPS, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean stop, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run didn't return")
+	}
+}
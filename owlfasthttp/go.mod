@@ -0,0 +1,16 @@
+module github.com/go-owl/owl/owlfasthttp
+
+go 1.22
+
+require (
+	github.com/go-owl/owl v1.0.0
+	github.com/valyala/fasthttp v1.51.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.5 // indirect
+	github.com/klauspost/compress v1.17.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+)
+
+replace github.com/go-owl/owl => ../
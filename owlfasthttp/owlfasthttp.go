@@ -0,0 +1,82 @@
+// Package owlfasthttp is an experimental owl.Transport backed by
+// valyala/fasthttp, for extreme-throughput services.
+//
+// Requests are converted to net/http types through fasthttpadaptor, so
+// handlers, Ctx, binding and routing are unchanged. The conversion costs
+// some of fasthttp's advantage; measure before adopting it. Streaming
+// features such as http.Hijacker and http.Flusher are not available.
+//
+//	app := owl.New(owl.AppConfig{Transport: owlfasthttp.New(owlfasthttp.Config{})})
+//	app.Start(":8080")
+package owlfasthttp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-owl/owl"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+var _ owl.Transport = (*Transport)(nil)
+
+// Config holds fasthttp server settings. Zero values keep fasthttp's
+// defaults.
+type Config struct {
+	Concurrency        int           // Max concurrent connections
+	ReadTimeout        time.Duration // Max duration for reading the full request
+	WriteTimeout       time.Duration // Max duration for writing the response
+	IdleTimeout        time.Duration // Max keep-alive wait
+	MaxRequestBodySize int           // Max request body size in bytes
+}
+
+// Transport serves an http.Handler with fasthttp.
+type Transport struct {
+	config Config
+
+	mu     sync.Mutex // Guards server and closed, see Shutdown
+	server *fasthttp.Server
+	closed bool
+}
+
+// New returns a fasthttp Transport.
+func New(config Config) *Transport {
+	return &Transport{config: config}
+}
+
+// ListenAndServe implements owl.Transport.
+func (t *Transport) ListenAndServe(addr string, h http.Handler) error {
+	server := &fasthttp.Server{
+		Handler:            fasthttpadaptor.NewFastHTTPHandler(h),
+		Concurrency:        t.config.Concurrency,
+		ReadTimeout:        t.config.ReadTimeout,
+		WriteTimeout:       t.config.WriteTimeout,
+		IdleTimeout:        t.config.IdleTimeout,
+		MaxRequestBodySize: t.config.MaxRequestBodySize,
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	t.server = server
+	t.mu.Unlock()
+	return server.ListenAndServe(addr)
+}
+
+// Shutdown implements owl.Transport. It may be called from another
+// goroutine than ListenAndServe, such as a signal handler; a Transport
+// shut down before serving doesn't serve.
+func (t *Transport) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	server := t.server
+	t.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+	return server.ShutdownWithContext(ctx)
+}
@@ -0,0 +1,66 @@
+package owlfasthttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-owl/owl"
+)
+
+func TestTransport(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	tr := New(Config{})
+	app := owl.New(owl.AppConfig{Transport: tr, DisableBanner: true})
+	app.GET("/users/{id}", func(c *owl.Ctx) error {
+		return c.Text("user " + c.Param("id") + " " + c.Query("q"))
+	})
+	served := make(chan error, 1)
+	go func() { served <- app.Start(addr) }()
+
+	var resp *http.Response
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if resp, err = http.Get("http://" + addr + "/users/7?q=x"); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "user 7 x" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	// Shut down from another goroutine, like a signal handler.
+	go app.ShutdownWithContext(context.Background())
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("expected a clean stop, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the transport didn't stop")
+	}
+}
+
+func TestShutdownBeforeServe(t *testing.T) {
+	tr := New(Config{})
+	if err := tr.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.ListenAndServe("127.0.0.1:0", http.NotFoundHandler()); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected a shut down transport not to serve, got %v", err)
+	}
+}
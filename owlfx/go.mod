@@ -0,0 +1,18 @@
+module github.com/go-owl/owl/owlfx
+
+go 1.22
+
+require (
+	github.com/go-owl/owl v1.0.0
+	go.uber.org/fx v1.20.0
+)
+
+require (
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/dig v1.17.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/zap v1.23.0 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+)
+
+replace github.com/go-owl/owl => ../
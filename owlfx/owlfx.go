@@ -0,0 +1,84 @@
+// Package owlfx wires an Owl App into an uber/fx application.
+//
+// It formalizes the pattern from _example/uberfx: the App is provided to
+// the graph, route modules contribute to the "owl.routes" value group, and
+// the HTTP server follows the fx lifecycle.
+//
+// Example:
+//
+//	fx.New(
+//		owlfx.Module(":8080", owl.AppConfig{Name: "billing"}),
+//		fx.Provide(owlfx.AsRoutes(NewUserRoutes)),
+//	).Run()
+package owlfx
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-owl/owl"
+	"go.uber.org/fx"
+)
+
+// Routes is implemented by route modules that register handlers on the App.
+type Routes interface {
+	Register(app *owl.App)
+}
+
+// RoutesFunc adapts a plain function to the Routes interface.
+type RoutesFunc func(app *owl.App)
+
+// Register calls f(app).
+func (f RoutesFunc) Register(app *owl.App) {
+	f(app)
+}
+
+// Module provides an *owl.App built from config, registers every Routes in
+// the "owl.routes" group and serves on addr for the lifetime of the fx app.
+func Module(addr string, config ...owl.AppConfig) fx.Option {
+	return fx.Module("owl",
+		fx.Provide(func() *owl.App { return owl.New(config...) }),
+		fx.Invoke(fx.Annotate(register, fx.ParamTags(``, `group:"owl.routes"`))),
+		fx.Invoke(func(lc fx.Lifecycle, app *owl.App) { Lifecycle(lc, app, addr) }),
+	)
+}
+
+// AsRoutes annotates a constructor so its result joins the "owl.routes"
+// value group consumed by Module.
+func AsRoutes(constructor interface{}) interface{} {
+	return fx.Annotate(constructor, fx.As(new(Routes)), fx.ResultTags(`group:"owl.routes"`))
+}
+
+// Lifecycle appends hooks that start app on addr and shut it down
+// gracefully when fx stops. The App is built and the listener opened inside
+// OnStart, so invalid routes and bind errors abort startup instead of being
+// logged from a goroutine. The App is then served with App.Serve, which
+// runs the OnListen hooks like Start does.
+func Lifecycle(lc fx.Lifecycle, app *owl.App, addr string) {
+	var ln net.Listener
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := app.Build(); err != nil {
+				return err
+			}
+			var err error
+			if ln, err = net.Listen("tcp", addr); err != nil {
+				return err
+			}
+			go app.Serve(ln)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			err := app.ShutdownWithContext(ctx)
+			// Also stops a Serve that hasn't started its server yet.
+			ln.Close()
+			return err
+		},
+	})
+}
+
+func register(app *owl.App, routes []Routes) {
+	for _, r := range routes {
+		r.Register(app)
+	}
+}
@@ -0,0 +1,67 @@
+package owlfx
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-owl/owl"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type pingRoutes struct{}
+
+func (pingRoutes) Register(app *owl.App) {
+	app.GET("/ping", func(c *owl.Ctx) error { return c.Text("pong") })
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestModule(t *testing.T) {
+	addr := freeAddr(t)
+	listened := make(chan string, 1)
+	app := fxtest.New(t,
+		Module(addr, owl.AppConfig{DisableBanner: true}),
+		fx.Provide(AsRoutes(func() pingRoutes { return pingRoutes{} })),
+		fx.Invoke(func(app *owl.App) { app.Hooks().OnListen(func(addr string) { listened <- addr }) }),
+	)
+	app.RequireStart()
+	if got := <-listened; got != addr {
+		t.Fatalf("expected the OnListen hooks to run with %s, got %s", addr, got)
+	}
+
+	resp, err := http.Get("http://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("expected the routes of the group to be served, got %q", body)
+	}
+
+	app.RequireStop()
+	if _, err := http.Get("http://" + addr + "/ping"); err == nil {
+		t.Fatal("expected the server to stop with fx")
+	}
+}
+
+func TestLifecycleBuildError(t *testing.T) {
+	app := fxtest.New(t,
+		Module(freeAddr(t), owl.AppConfig{DisableBanner: true}),
+		fx.Invoke(func(app *owl.App) { app.GET("/nil", nil) }),
+	)
+	if err := app.Start(context.Background()); err == nil {
+		t.Fatal("expected invalid routes to abort the start")
+	}
+}
@@ -0,0 +1,315 @@
+// Package owlgen generates Owl project scaffolds, controllers and middleware
+// stubs. It holds no CLI of its own; a command-line tool (or a test, or a
+// go:generate directive) calls into it so every service ends up with the
+// same layout.
+//
+// Example:
+//
+//	files, err := owlgen.Scaffold(owlgen.Project{
+//		Module: "github.com/acme/billing",
+//		Name:   "billing",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	return owlgen.Write("./billing", files, false)
+package owlgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// File is a single generated file, with a slash-separated path relative to
+// the project root.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Project describes a service to scaffold.
+type Project struct {
+	Module string // Go module path, e.g. "github.com/acme/billing" (required)
+	Name   string // Server name passed to owl.AppConfig (default: last module element)
+	Addr   string // Listen address (default: ":8080")
+}
+
+// Controller describes a resource controller to generate.
+type Controller struct {
+	Name    string   // Resource name, e.g. "user" (required)
+	Package string   // Go package name (default: "handlers")
+	Methods []string // HTTP methods to generate (default: GET, POST, PUT, DELETE)
+}
+
+// ErrExists is returned by Write when a file already exists and overwrite
+// is false.
+var ErrExists = errors.New("owlgen: file already exists")
+
+// Scaffold returns the files for a new Owl service: go.mod, main.go, a
+// routes file and a health handler.
+func Scaffold(p Project) ([]File, error) {
+	if p.Module == "" {
+		return nil, errors.New("owlgen: project module is required")
+	}
+	if p.Name == "" {
+		p.Name = p.Module[strings.LastIndex(p.Module, "/")+1:]
+	}
+	if p.Addr == "" {
+		p.Addr = ":8080"
+	}
+
+	files := []File{
+		{Path: "go.mod", Content: []byte("module " + p.Module + "\n\ngo 1.22\n\nrequire github.com/go-owl/owl v1.0.0\n")},
+	}
+	for _, t := range []struct{ path, tmpl string }{
+		{"main.go", mainTmpl},
+		{"internal/routes/routes.go", routesTmpl},
+		{"internal/handlers/health.go", healthTmpl},
+	} {
+		src, err := render(t.tmpl, p)
+		if err != nil {
+			return nil, fmt.Errorf("owlgen: %s: %w", t.path, err)
+		}
+		files = append(files, File{Path: t.path, Content: src})
+	}
+	return files, nil
+}
+
+// GenerateController returns a controller file with one handler per method
+// and a Register function that mounts them on an *owl.Group.
+func GenerateController(c Controller) (File, error) {
+	if c.Name == "" {
+		return File{}, errors.New("owlgen: controller name is required")
+	}
+	if c.Package == "" {
+		c.Package = "handlers"
+	}
+	if err := checkNames(c.Name, c.Package); err != nil {
+		return File{}, err
+	}
+	methods := []string{"GET", "POST", "PUT", "DELETE"}
+	if len(c.Methods) > 0 {
+		methods = make([]string, len(c.Methods))
+	}
+	seen := make(map[string]bool, len(c.Methods))
+	for i, m := range c.Methods {
+		m = strings.ToUpper(m)
+		if _, ok := methodActions[m]; !ok {
+			return File{}, fmt.Errorf("owlgen: unsupported method %q", m)
+		}
+		if seen[m] {
+			return File{}, fmt.Errorf("owlgen: duplicate method %q", m)
+		}
+		seen[m] = true
+		methods[i] = m
+	}
+
+	src, err := render(controllerTmpl, map[string]interface{}{
+		"Package": c.Package,
+		"Type":    exported(c.Name),
+		"Path":    "/" + strings.ToLower(c.Name) + "s",
+		"Methods": methods,
+	})
+	if err != nil {
+		return File{}, fmt.Errorf("owlgen: controller %s: %w", c.Name, err)
+	}
+	return File{Path: "internal/" + c.Package + "/" + strings.ToLower(c.Name) + ".go", Content: src}, nil
+}
+
+// GenerateMiddleware returns an Owl-style middleware stub named name in
+// package pkg (default: "middleware").
+func GenerateMiddleware(name, pkg string) (File, error) {
+	if name == "" {
+		return File{}, errors.New("owlgen: middleware name is required")
+	}
+	if pkg == "" {
+		pkg = "middleware"
+	}
+	if err := checkNames(name, pkg); err != nil {
+		return File{}, err
+	}
+	src, err := render(middlewareTmpl, map[string]string{
+		"Package": pkg,
+		"Func":    exported(name),
+	})
+	if err != nil {
+		return File{}, fmt.Errorf("owlgen: middleware %s: %w", name, err)
+	}
+	return File{Path: "internal/" + pkg + "/" + strings.ToLower(name) + ".go", Content: src}, nil
+}
+
+// Write writes files below dir, creating directories as needed. Unless
+// overwrite is set, nothing is written and ErrExists is returned when any
+// of the files already exists.
+func Write(dir string, files []File, overwrite bool) error {
+	if !overwrite {
+		for _, f := range files {
+			path := filepath.Join(dir, filepath.FromSlash(f.Path))
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%w: %s", ErrExists, path)
+			}
+		}
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// methodActions maps an HTTP method to the generated handler name and the
+// Group method used to register it.
+var methodActions = map[string]struct{ action, path string }{
+	"GET":    {"List", ""},
+	"POST":   {"Create", ""},
+	"PUT":    {"Update", "/{id}"},
+	"PATCH":  {"Patch", "/{id}"},
+	"DELETE": {"Delete", "/{id}"},
+}
+
+var funcs = template.FuncMap{
+	"action": func(m string) string { return methodActions[m].action },
+	"suffix": func(m string) string { return methodActions[m].path },
+}
+
+// render executes a template and gofmt's the result.
+func render(tmpl string, data interface{}) ([]byte, error) {
+	t, err := template.New("").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// checkNames reports an error unless name, once exported, and pkg are
+// valid Go identifiers, so the generated code compiles.
+func checkNames(name, pkg string) error {
+	if !token.IsIdentifier(exported(name)) {
+		return fmt.Errorf("owlgen: %q isn't a valid Go name", name)
+	}
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("owlgen: %q isn't a valid Go package name", pkg)
+	}
+	return nil
+}
+
+// exported turns "user-profile" or "user_profile" into "UserProfile".
+func exported(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '-' || r == '_' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+const mainTmpl = `package main
+
+import (
+	"log"
+
+	"github.com/go-owl/owl"
+	"github.com/go-owl/owl/middleware"
+
+	"{{.Module}}/internal/routes"
+)
+
+func main() {
+	app := owl.New(owl.AppConfig{Name: "{{.Name}}"})
+
+	app.Use(middleware.RequestID)
+	app.Use(middleware.Logger)
+	app.Use(middleware.Recoverer)
+
+	routes.Register(app)
+
+	log.Fatal(app.Start("{{.Addr}}"))
+}
+`
+
+const routesTmpl = `package routes
+
+import (
+	"github.com/go-owl/owl"
+
+	"{{.Module}}/internal/handlers"
+)
+
+// Register mounts all application routes on app.
+func Register(app *owl.App) {
+	app.GET("/health", handlers.Health)
+
+	_ = app.Group("/api/v1")
+}
+`
+
+const healthTmpl = `package handlers
+
+import "github.com/go-owl/owl"
+
+// Health reports that the service is up.
+func Health(c *owl.Ctx) error {
+	return c.JSON(map[string]string{"status": "ok"})
+}
+`
+
+const controllerTmpl = `package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/go-owl/owl"
+)
+
+// {{.Type}}Controller handles {{.Path}} routes.
+type {{.Type}}Controller struct{}
+
+// Register mounts the controller routes on g.
+func (ctl *{{.Type}}Controller) Register(g *owl.Group) {
+{{- range .Methods}}
+	g.{{.}}("{{$.Path}}{{suffix .}}", ctl.{{action .}})
+{{- end}}
+}
+{{range .Methods}}
+// {{action .}} handles {{.}} {{$.Path}}{{suffix .}}.
+func (ctl *{{$.Type}}Controller) {{action .}}(c *owl.Ctx) error {
+	return owl.NewHTTPError(http.StatusNotImplemented, "{{$.Type}}.{{action .}} not implemented")
+}
+{{end}}`
+
+const middlewareTmpl = `package {{.Package}}
+
+import "github.com/go-owl/owl"
+
+// {{.Func}} is an Owl middleware.
+func {{.Func}}(next owl.Handler) owl.Handler {
+	return func(c *owl.Ctx) error {
+		return next(c)
+	}
+}
+`
@@ -0,0 +1,111 @@
+package owlgen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	files, err := Scaffold(Project{Module: "github.com/acme/billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, f := range files {
+		got[f.Path] = string(f.Content)
+	}
+	for _, p := range []string{"go.mod", "main.go", "internal/routes/routes.go", "internal/handlers/health.go"} {
+		if _, ok := got[p]; !ok {
+			t.Fatalf("missing scaffold file %s", p)
+		}
+	}
+	if !strings.Contains(got["main.go"], `owl.AppConfig{Name: "billing"}`) {
+		t.Fatalf("main.go should default name to last module element:\n%s", got["main.go"])
+	}
+	if !strings.Contains(got["main.go"], `"github.com/acme/billing/internal/routes"`) {
+		t.Fatalf("main.go should import routes package:\n%s", got["main.go"])
+	}
+
+	if _, err := Scaffold(Project{}); err == nil {
+		t.Fatal("expected error for missing module")
+	}
+}
+
+func TestGenerateController(t *testing.T) {
+	methods := []string{"get", "delete"}
+	f, err := GenerateController(Controller{Name: "user-profile", Methods: methods})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if methods[0] != "get" {
+		t.Fatalf("expected the methods of the caller to be left alone, got %v", methods)
+	}
+	if f.Path != "internal/handlers/user-profile.go" {
+		t.Fatalf("unexpected path %s", f.Path)
+	}
+	src := string(f.Content)
+	for _, want := range []string{
+		"type UserProfileController struct{}",
+		`g.GET("/user-profiles", ctl.List)`,
+		`g.DELETE("/user-profiles/{id}", ctl.Delete)`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("controller missing %q:\n%s", want, src)
+		}
+	}
+
+	for _, c := range []Controller{
+		{Name: "user", Methods: []string{"BREW"}},
+		{Name: "user", Methods: []string{"get", "GET"}},
+		{Name: "2fa"},
+		{Name: "user.v2"},
+		{Name: "user", Package: "api-v1"},
+		{Name: "user", Package: "func"},
+	} {
+		if _, err := GenerateController(c); err == nil {
+			t.Fatalf("expected an error for %+v", c)
+		}
+	}
+}
+
+func TestGenerateMiddleware(t *testing.T) {
+	f, err := GenerateMiddleware("audit_trail", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(f.Content), "func AuditTrail(next owl.Handler) owl.Handler") {
+		t.Fatalf("unexpected middleware:\n%s", f.Content)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	files := []File{{Path: "a/b.txt", Content: []byte("one")}}
+
+	if err := Write(dir, files, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(dir, files, false); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	more := []File{{Path: "c.txt", Content: []byte("new")}, files[0]}
+	if err := Write(dir, more, false); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.txt")); err == nil {
+		t.Fatal("expected nothing to be written when a file exists")
+	}
+
+	files[0].Content = []byte("two")
+	if err := Write(dir, files, true); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "a", "b.txt"))
+	if string(b) != "two" {
+		t.Fatalf("expected overwritten content, got %q", b)
+	}
+}
@@ -0,0 +1,91 @@
+package owllambda
+
+// The event types below mirror the JSON shapes AWS delivers to Lambda, so
+// they decode directly from the runtime payload without depending on
+// github.com/aws/aws-lambda-go. Only the fields the adapter needs are
+// declared; unknown fields are ignored by encoding/json.
+
+// APIGatewayProxyRequest is an API Gateway REST API (payload v1) event.
+type APIGatewayProxyRequest struct {
+	HTTPMethod                      string                        `json:"httpMethod"`
+	Path                            string                        `json:"path"`
+	Headers                         map[string]string             `json:"headers"`
+	MultiValueHeaders               map[string][]string           `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string             `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string           `json:"multiValueQueryStringParameters"`
+	RequestContext                  APIGatewayProxyRequestContext `json:"requestContext"`
+	Body                            string                        `json:"body"`
+	IsBase64Encoded                 bool                          `json:"isBase64Encoded"`
+}
+
+// APIGatewayProxyRequestContext is the subset of the v1 request context
+// used to populate the http.Request.
+type APIGatewayProxyRequestContext struct {
+	RequestID string `json:"requestId"`
+	Identity  struct {
+		SourceIP string `json:"sourceIp"`
+	} `json:"identity"`
+}
+
+// APIGatewayProxyResponse is the v1 response returned to API Gateway.
+type APIGatewayProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// APIGatewayV2HTTPRequest is an API Gateway HTTP API (payload v2) event.
+type APIGatewayV2HTTPRequest struct {
+	RawPath         string                         `json:"rawPath"`
+	RawQueryString  string                         `json:"rawQueryString"`
+	Cookies         []string                       `json:"cookies"`
+	Headers         map[string]string              `json:"headers"`
+	RequestContext  APIGatewayV2HTTPRequestContext `json:"requestContext"`
+	Body            string                         `json:"body"`
+	IsBase64Encoded bool                           `json:"isBase64Encoded"`
+}
+
+// APIGatewayV2HTTPRequestContext is the subset of the v2 request context
+// used to populate the http.Request.
+type APIGatewayV2HTTPRequestContext struct {
+	RequestID string `json:"requestId"`
+	HTTP      struct {
+		Method   string `json:"method"`
+		SourceIP string `json:"sourceIp"`
+	} `json:"http"`
+}
+
+// APIGatewayV2HTTPResponse is the v2 response returned to API Gateway.
+type APIGatewayV2HTTPResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Cookies         []string          `json:"cookies"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// ALBTargetGroupRequest is an Application Load Balancer target event.
+type ALBTargetGroupRequest struct {
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	Body                            string              `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+// ALBTargetGroupResponse is the response returned to the load balancer.
+// Headers is used unless the target group enables multi-value headers, in
+// which case MultiValueHeaders must be set instead.
+type ALBTargetGroupResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
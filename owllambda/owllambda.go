@@ -0,0 +1,275 @@
+// Package owllambda serves an Owl App (or any http.Handler) from AWS Lambda
+// behind API Gateway or an Application Load Balancer.
+//
+// Each method converts the incoming event to an *http.Request, runs it
+// through the handler and converts the recorded response back. The methods
+// have the signatures aws-lambda-go expects, so they can be passed straight
+// to lambda.Start:
+//
+//	app := owl.New()
+//	app.GET("/hello", hello)
+//	lambda.Start(owllambda.New(app).ProxyV2)
+package owllambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Handler adapts an http.Handler to Lambda events.
+type Handler struct {
+	handler http.Handler
+}
+
+// New returns a Handler serving h.
+func New(h http.Handler) *Handler {
+	return &Handler{handler: h}
+}
+
+// ProxyV1 serves an API Gateway REST API (payload v1) event.
+func (h *Handler) ProxyV1(ctx context.Context, e APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+	query := mergeValues(e.QueryStringParameters, e.MultiValueQueryStringParameters)
+	u := &url.URL{Path: e.Path, RawQuery: query.Encode()}
+	r, err := newRequest(ctx, e.HTTPMethod, u, e.Body, e.IsBase64Encoded)
+	if err != nil {
+		return APIGatewayProxyResponse{}, err
+	}
+	r.Header = mergeHeader(e.Headers, e.MultiValueHeaders)
+	r.Host = r.Header.Get("Host")
+	r.RemoteAddr = e.RequestContext.Identity.SourceIP
+	setRequestID(r, e.RequestContext.RequestID)
+
+	w := h.serve(r)
+	body, b64 := w.encodeBody()
+	return APIGatewayProxyResponse{
+		StatusCode:        w.status,
+		Headers:           singleHeader(w.header),
+		MultiValueHeaders: w.header,
+		Body:              body,
+		IsBase64Encoded:   b64,
+	}, nil
+}
+
+// ProxyV2 serves an API Gateway HTTP API (payload v2) event.
+func (h *Handler) ProxyV2(ctx context.Context, e APIGatewayV2HTTPRequest) (APIGatewayV2HTTPResponse, error) {
+	// Unlike REST APIs, HTTP APIs pass the path still percent-encoded.
+	path, err := url.PathUnescape(e.RawPath)
+	if err != nil {
+		return APIGatewayV2HTTPResponse{}, err
+	}
+	u := &url.URL{Path: path, RawPath: e.RawPath, RawQuery: e.RawQueryString}
+	r, err := newRequest(ctx, e.RequestContext.HTTP.Method, u, e.Body, e.IsBase64Encoded)
+	if err != nil {
+		return APIGatewayV2HTTPResponse{}, err
+	}
+	for k, v := range e.Headers {
+		// v2 joins repeated headers with commas, which can't be told apart
+		// from commas within a value, such as in dates or quoted strings.
+		r.Header.Set(k, v)
+	}
+	if len(e.Cookies) > 0 {
+		r.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+	r.Host = r.Header.Get("Host")
+	r.RemoteAddr = e.RequestContext.HTTP.SourceIP
+	setRequestID(r, e.RequestContext.RequestID)
+
+	w := h.serve(r)
+	cookies := w.header.Values("Set-Cookie")
+	w.header.Del("Set-Cookie")
+	body, b64 := w.encodeBody()
+	return APIGatewayV2HTTPResponse{
+		StatusCode:      w.status,
+		Headers:         joinHeader(w.header),
+		Cookies:         cookies,
+		Body:            body,
+		IsBase64Encoded: b64,
+	}, nil
+}
+
+// ALB serves an Application Load Balancer event. Multi-value headers are
+// used in the response when the request carried them.
+func (h *Handler) ALB(ctx context.Context, e ALBTargetGroupRequest) (ALBTargetGroupResponse, error) {
+	// Unlike API Gateway, ALB passes query parameters still URL-encoded.
+	query := mergeValues(e.QueryStringParameters, e.MultiValueQueryStringParameters)
+	u := &url.URL{Path: e.Path, RawQuery: rawQuery(query)}
+	r, err := newRequest(ctx, e.HTTPMethod, u, e.Body, e.IsBase64Encoded)
+	if err != nil {
+		return ALBTargetGroupResponse{}, err
+	}
+	r.Header = mergeHeader(e.Headers, e.MultiValueHeaders)
+	r.Host = r.Header.Get("Host")
+
+	w := h.serve(r)
+	body, b64 := w.encodeBody()
+	res := ALBTargetGroupResponse{
+		StatusCode:        w.status,
+		StatusDescription: strconv.Itoa(w.status) + " " + http.StatusText(w.status),
+		Body:              body,
+		IsBase64Encoded:   b64,
+	}
+	if e.MultiValueHeaders != nil {
+		res.MultiValueHeaders = w.header
+	} else {
+		res.Headers = singleHeader(w.header)
+	}
+	return res, nil
+}
+
+func (h *Handler) serve(r *http.Request) *responseWriter {
+	w := &responseWriter{header: http.Header{}}
+	h.handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w
+}
+
+// newRequest builds the http.Request shared by every event type.
+func newRequest(ctx context.Context, method string, u *url.URL, body string, isBase64 bool) (*http.Request, error) {
+	data := []byte(body)
+	if isBase64 {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(body); err != nil {
+			return nil, err
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	r.RequestURI = u.RequestURI()
+	return r, nil
+}
+
+// setRequestID exposes the gateway request id to middleware.RequestID.
+func setRequestID(r *http.Request, id string) {
+	if id != "" && r.Header.Get("X-Request-Id") == "" {
+		r.Header.Set("X-Request-Id", id)
+	}
+}
+
+func mergeValues(single map[string]string, multi map[string][]string) url.Values {
+	v := url.Values{}
+	for k, vs := range multi {
+		v[k] = append(v[k], vs...)
+	}
+	for k, s := range single {
+		if _, ok := v[k]; !ok {
+			v.Set(k, s)
+		}
+	}
+	return v
+}
+
+// rawQuery joins already encoded query parameters, sorted by key.
+func rawQuery(v url.Values) string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		for _, s := range v[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(k + "=" + s)
+		}
+	}
+	return b.String()
+}
+
+func mergeHeader(single map[string]string, multi map[string][]string) http.Header {
+	h := http.Header{}
+	for k, vs := range multi {
+		for _, s := range vs {
+			h.Add(k, s)
+		}
+	}
+	for k, s := range single {
+		if h.Get(k) == "" {
+			h.Set(k, s)
+		}
+	}
+	return h
+}
+
+func singleHeader(h http.Header) map[string]string {
+	m := make(map[string]string, len(h))
+	for k, vs := range h {
+		if len(vs) > 0 {
+			m[k] = vs[len(vs)-1]
+		}
+	}
+	return m
+}
+
+func joinHeader(h http.Header) map[string]string {
+	m := make(map[string]string, len(h))
+	for k, vs := range h {
+		m[k] = strings.Join(vs, ",")
+	}
+	return m
+}
+
+// responseWriter records the handler's response in memory.
+type responseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// encodeBody returns the body as a string, base64 encoding it unless the
+// content type is textual.
+func (w *responseWriter) encodeBody() (string, bool) {
+	if w.body.Len() == 0 {
+		return "", false
+	}
+	ct := w.header.Get("Content-Type")
+	if ct == "" {
+		ct = http.DetectContentType(w.body.Bytes())
+	}
+	if isText(ct) && w.header.Get("Content-Encoding") == "" {
+		return w.body.String(), false
+	}
+	return base64.StdEncoding.EncodeToString(w.body.Bytes()), true
+}
+
+func isText(ct string) bool {
+	ct = strings.ToLower(ct)
+	if strings.HasPrefix(ct, "text/") {
+		return true
+	}
+	for _, s := range []string{"json", "xml", "javascript", "x-www-form-urlencoded"} {
+		if strings.Contains(ct, s) {
+			return true
+		}
+	}
+	return false
+}
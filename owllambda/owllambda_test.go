@@ -0,0 +1,112 @@
+package owllambda
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func testApp() *owl.App {
+	app := owl.New()
+	app.GET("/users/{id}", func(c *owl.Ctx) error {
+		c.SetHeader("Set-Cookie", "a=1")
+		return c.JSON(map[string]string{"id": c.Param("id"), "q": c.Query("q"), "h": c.Header("X-Test")})
+	})
+	app.POST("/echo", func(c *owl.Ctx) error {
+		var b []byte
+		if err := c.Bind().Bytes(&b); err != nil {
+			return err
+		}
+		c.SetHeader("Content-Type", "application/octet-stream")
+		_, err := c.Response.Write(b)
+		return err
+	})
+	return app
+}
+
+func TestProxyV1(t *testing.T) {
+	res, err := New(testApp()).ProxyV1(context.Background(), APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		Path:                  "/users/42",
+		QueryStringParameters: map[string]string{"q": "x"},
+		Headers:               map[string]string{"X-Test": "yes"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || res.IsBase64Encoded {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+	if res.Body != `{"h":"yes","id":"42","q":"x"}`+"\n" {
+		t.Fatalf("unexpected body: %q", res.Body)
+	}
+}
+
+func TestProxyV2(t *testing.T) {
+	e := APIGatewayV2HTTPRequest{RawPath: "/users/7", RawQueryString: "q=z", Headers: map[string]string{"x-test": "a, b"}}
+	e.RequestContext.HTTP.Method = "GET"
+	res, err := New(testApp()).ProxyV2(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || len(res.Cookies) != 1 || res.Cookies[0] != "a=1" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+	if _, ok := res.Headers["Set-Cookie"]; ok {
+		t.Fatal("Set-Cookie should be moved to Cookies")
+	}
+	if res.Body != `{"h":"a, b","id":"7","q":"z"}`+"\n" {
+		t.Fatalf("expected headers to be kept whole, got %q", res.Body)
+	}
+}
+
+func TestALBQuery(t *testing.T) {
+	res, err := New(testApp()).ALB(context.Background(), ALBTargetGroupRequest{
+		HTTPMethod:            "GET",
+		Path:                  "/users/7",
+		QueryStringParameters: map[string]string{"q": "a%20b%26c%25"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body != `{"h":"","id":"7","q":"a b\u0026c%"}`+"\n" {
+		t.Fatalf("expected the query to be decoded once, got %q", res.Body)
+	}
+}
+
+func TestALBBinaryBody(t *testing.T) {
+	payload := []byte{0x00, 0xff, 0x10}
+	res, err := New(testApp()).ALB(context.Background(), ALBTargetGroupRequest{
+		HTTPMethod:      "POST",
+		Path:            "/echo",
+		Body:            base64.StdEncoding.EncodeToString(payload),
+		IsBase64Encoded: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsBase64Encoded {
+		t.Fatal("binary response should be base64 encoded")
+	}
+	got, _ := base64.StdEncoding.DecodeString(res.Body)
+	if string(got) != string(payload) {
+		t.Fatalf("unexpected body %v", got)
+	}
+	if res.StatusDescription != "200 OK" {
+		t.Fatalf("unexpected status description %q", res.StatusDescription)
+	}
+}
+
+func TestProxyV2EncodedPath(t *testing.T) {
+	e := APIGatewayV2HTTPRequest{RawPath: "/users/a%20b"}
+	e.RequestContext.HTTP.Method = "GET"
+	res, err := New(testApp()).ProxyV2(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || res.Body != `{"h":"","id":"a b","q":""}`+"\n" {
+		t.Fatalf("expected the path to be decoded, got %d %q", res.StatusCode, res.Body)
+	}
+}
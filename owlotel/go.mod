@@ -0,0 +1,11 @@
+module github.com/go-owl/owl/owlotel
+
+go 1.22
+
+require (
+	github.com/go-owl/owl v1.0.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+replace github.com/go-owl/owl => ../
@@ -0,0 +1,50 @@
+// Package owlotel attaches Owl route information to OpenTelemetry spans.
+//
+// It complements an HTTP instrumentation such as otelhttp, which starts the
+// request span before routing and therefore can't know the matched route:
+//
+//	app := owl.New()
+//	app.Use(owlotel.RouteAttributes)
+//
+//	billing := app.Group("/billing").Attr("team", "payments").Attr("domain", "billing")
+//	billing.POST("/invoices", createInvoice)
+//
+//	http.ListenAndServe(":8080", otelhttp.NewHandler(app, "http"))
+//
+// Spans of requests served by billing routes then carry http.route,
+// team=payments and domain=billing, which makes ownership visible in
+// tracing tools without maintaining path-prefix mappings.
+package owlotel
+
+import (
+	"net/http"
+
+	"github.com/go-owl/owl"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RouteAttributes is a middleware setting http.route and the attributes
+// declared with Group.Attr or RouteBuilder.Attr on the span found in the
+// request context, once the request has been routed and served.
+func RouteAttributes(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		span := trace.SpanFromContext(r.Context())
+		rctx := owl.RouteContext(r.Context())
+		if !span.IsRecording() || rctx == nil {
+			return
+		}
+
+		attrs := make([]attribute.KeyValue, 0, len(rctx.RouteAttrs())+1)
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			attrs = append(attrs, attribute.String("http.route", pattern))
+		}
+		for _, a := range rctx.RouteAttrs() {
+			attrs = append(attrs, attribute.String(a.Key, a.Value))
+		}
+		span.SetAttributes(attrs...)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,41 @@
+package owlotel
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-owl/owl"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan records the attributes set on it.
+type recordingSpan struct {
+	noop.Span
+	attrs []attribute.KeyValue
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) SetAttributes(kv ...attribute.KeyValue) { s.attrs = append(s.attrs, kv...) }
+
+func TestRouteAttributes(t *testing.T) {
+	app := owl.New()
+	app.Use(RouteAttributes)
+	billing := app.Group("/billing").Attr("team", "payments")
+	billing.POST("/invoices/{id}", func(c *owl.Ctx) error { return c.Text("ok") })
+
+	span := &recordingSpan{}
+	r := httptest.NewRequest("POST", "/billing/invoices/42", nil)
+	r = r.WithContext(trace.ContextWithSpan(r.Context(), span))
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	got := map[attribute.Key]string{}
+	for _, kv := range span.attrs {
+		got[kv.Key] = kv.Value.AsString()
+	}
+	if got["http.route"] != "/billing/invoices/{id}" || got["team"] != "payments" || len(got) != 2 {
+		t.Fatalf("unexpected span attributes %v", got)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/go-owl/owl/owlwire
+
+go 1.22
+
+require (
+	github.com/go-owl/owl v1.0.0
+	github.com/google/wire v0.5.0
+)
+
+replace github.com/go-owl/owl => ../
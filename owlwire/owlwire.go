@@ -0,0 +1,73 @@
+// Package owlwire provides google/wire providers for an Owl App and its
+// HTTP server.
+//
+// Example injector:
+//
+//	func InitializeServer(cfg owl.AppConfig, addr owlwire.Addr) (*owlwire.Server, func(), error) {
+//		wire.Build(owlwire.ProviderSet)
+//		return nil, nil, nil
+//	}
+//
+// Routes are registered on srv.App() before calling srv.Serve().
+package owlwire
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-owl/owl"
+	"github.com/google/wire"
+)
+
+// ProviderSet provides *owl.App and *Server.
+var ProviderSet = wire.NewSet(NewApp, NewServer)
+
+// Addr is the listen address injected into NewServer.
+type Addr string
+
+// ShutdownTimeout bounds the cleanup function returned by NewServer.
+var ShutdownTimeout = 30 * time.Second
+
+// NewApp provides an App built from cfg.
+func NewApp(cfg owl.AppConfig) *owl.App {
+	return owl.New(cfg)
+}
+
+// Server is a bound, not yet serving, Owl HTTP server.
+type Server struct {
+	app *owl.App
+	ln  net.Listener
+}
+
+// NewServer binds addr for app. The returned cleanup function gracefully
+// shuts the server down, which is how wire injectors release it.
+func NewServer(app *owl.App, addr Addr) (*Server, func(), error) {
+	ln, err := net.Listen("tcp", string(addr))
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &Server{app: app, ln: ln}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		_ = app.ShutdownWithContext(ctx)
+		_ = ln.Close()
+	}
+	return s, cleanup, nil
+}
+
+// Serve builds the App and serves requests until the cleanup function is
+// called, see owl.App.Serve. It returns nil after a graceful shutdown.
+func (s *Server) Serve() error {
+	if err := s.app.Serve(s.ln); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// App returns the served App.
+func (s *Server) App() *owl.App {
+	return s.app
+}
@@ -0,0 +1,53 @@
+package owlwire
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-owl/owl"
+)
+
+func TestServer(t *testing.T) {
+	app := NewApp(owl.AppConfig{DisableBanner: true})
+	srv, cleanup, err := NewServer(app, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.App().GET("/ping", func(c *owl.Ctx) error { return c.Text("pong") })
+	listened := make(chan string, 1)
+	srv.App().Hooks().OnListen(func(addr string) { listened <- addr })
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+
+	addr := srv.ln.Addr().String()
+	if got := <-listened; got != addr {
+		t.Fatalf("expected the OnListen hooks to run with %s, got %s", addr, got)
+	}
+	url := "http://" + addr + "/ping"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	http.DefaultClient.CloseIdleConnections()
+	cleanup()
+	if err := <-served; err != nil {
+		t.Fatalf("expected Serve to return nil after cleanup, got %v", err)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("expected the listener to be closed")
+	}
+}
+
+func TestNewServerBindError(t *testing.T) {
+	if _, _, err := NewServer(NewApp(owl.AppConfig{}), "127.0.0.1:-1"); err == nil {
+		t.Fatal("expected an invalid address to fail")
+	}
+}
@@ -0,0 +1,131 @@
+package owl
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TrailingSlashPolicy controls how request paths that differ from a route
+// only by a trailing slash are handled.
+type TrailingSlashPolicy uint8
+
+const (
+	// TrailingSlashStrict matches paths exactly: "/users/" doesn't match
+	// a "/users" route. This is the default.
+	TrailingSlashStrict TrailingSlashPolicy = iota
+
+	// TrailingSlashRedirect redirects to the path with or without the
+	// trailing slash when only that variant has a route. GET and HEAD
+	// requests get a 301, other methods a 308 so the body is resent.
+	TrailingSlashRedirect
+
+	// TrailingSlashStrip removes trailing slashes before routing, so
+	// "/users/" is served by the "/users" route.
+	TrailingSlashStrip
+)
+
+// redirectTrailingSlash answers with a redirect to the variant of the
+// request path that has a route, and reports whether it did.
+func (mx *Mux) redirectTrailingSlash(w http.ResponseWriter, r *http.Request, method methodTyp, searchPath string) bool {
+	if searchPath == "/" {
+		return false
+	}
+	alt := toggleTrailingSlash(searchPath)
+	if _, _, h := mx.tree.FindRoute(NewRouteContext(), method, alt); h == nil {
+		return false
+	}
+
+	u := *r.URL
+	u.Path = toggleTrailingSlash(u.Path)
+	if u.RawPath != "" {
+		u.RawPath = toggleTrailingSlash(u.RawPath)
+	}
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(w, r, u.String(), code)
+	return true
+}
+
+func toggleTrailingSlash(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return strings.TrimRight(p, "/")
+	}
+	return p + "/"
+}
+
+// asciiLower lower-cases ASCII letters only, so byte offsets into the
+// result are valid in the original string.
+func asciiLower(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; 'A' <= c && c <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if 'A' <= b[j] && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// lowerStatic lower-cases a routing pattern outside of {param} segments,
+// leaving param names and regular expressions intact.
+func lowerStatic(pattern string) string {
+	b := []byte(pattern)
+	depth := 0
+	for i, c := range b {
+		switch {
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case depth == 0 && 'A' <= c && c <= 'Z':
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// restoreParamCase replaces the route param values captured while matching
+// the lower-cased path with the same bytes of the original path, so
+// case-insensitive routing doesn't change what c.Param returns. The offset
+// of each value is recovered by walking the matched pattern: its static
+// text matched the path byte for byte, and asciiLower keeps the length.
+func restoreParamCase(rctx *Context, original string) {
+	pattern, values := rctx.routePattern, rctx.routeParams.Values
+	n := len(rctx.URLParams.Values) - len(values)
+	off, i := 0, 0
+	for j := 0; j < len(pattern) && i < len(values); j++ {
+		switch pattern[j] {
+		case '{':
+			// Skip the param name and regexp, which may contain braces.
+			for depth := 1; depth > 0 && j+1 < len(pattern); {
+				j++
+				switch pattern[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+		case '*':
+		default:
+			off++
+			continue
+		}
+		v := values[i]
+		if off+len(v) > len(original) {
+			return
+		}
+		values[i] = original[off : off+len(v)]
+		if n+i >= 0 {
+			rctx.URLParams.Values[n+i] = values[i]
+		}
+		off += len(v)
+		i++
+	}
+}
@@ -0,0 +1,84 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrailingSlashPolicy(t *testing.T) {
+	newApp := func(policy TrailingSlashPolicy) *App {
+		app := New(AppConfig{TrailingSlash: policy})
+		app.GET("/users", func(c *Ctx) error { return c.Text("users") })
+		app.POST("/users", func(c *Ctx) error { return c.Text("created") })
+		app.GET("/docs/", func(c *Ctx) error { return c.Text("docs") })
+		return app
+	}
+
+	tests := []struct {
+		policy       TrailingSlashPolicy
+		method, path string
+		code         int
+		location     string
+	}{
+		{TrailingSlashStrict, "GET", "/users/", 404, ""},
+		{TrailingSlashRedirect, "GET", "/users/?page=2", 301, "/users?page=2"},
+		{TrailingSlashRedirect, "POST", "/users/", 308, "/users"},
+		{TrailingSlashRedirect, "GET", "/docs", 301, "/docs/"},
+		{TrailingSlashRedirect, "GET", "/nothing/", 404, ""},
+		{TrailingSlashStrip, "GET", "/users//", 200, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		newApp(tt.policy).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("policy %d %s %s: got %d location=%q", tt.policy, tt.method, tt.path, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	app := New(AppConfig{CaseInsensitive: true})
+	app.GET("/Users/{Name}/Files/*", func(c *Ctx) error {
+		return c.Text(c.Param("Name") + "|" + c.Param("*"))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/USERS/JohnDoe/files/Docs/CV.pdf", nil))
+	if w.Body.String() != "JohnDoe|Docs/CV.pdf" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	app.GET("/Teams/{Team}/{Code:[a-z]{2}}/{Year:[0-9]+}", func(c *Ctx) error {
+		return c.Text(c.Param("Team") + "|" + c.Param("Code") + "|" + c.Param("Year"))
+	})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/teams/RedSox/AB/2024", nil))
+	if w.Body.String() != "RedSox|AB|2024" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	New().GET("/users", func(c *Ctx) error { return nil }).ServeHTTP(w, httptest.NewRequest("GET", "/Users", nil))
+	if w.Code != 404 {
+		t.Fatalf("routing should be case-sensitive by default, got %d", w.Code)
+	}
+}
+
+func TestWithKeepsPolicies(t *testing.T) {
+	mx := NewRouter()
+	mx.trailingSlash, mx.caseInsensitive = TrailingSlashRedirect, true
+	im := mx.With().(*Mux)
+	if im.trailingSlash != TrailingSlashRedirect || !im.caseInsensitive {
+		t.Fatalf("expected inline muxes to keep the routing policies, got %+v", im)
+	}
+}
+
+func TestLowerStatic(t *testing.T) {
+	got := lowerStatic("/API/{ID:[A-Z]+}/Items")
+	if got != "/api/{ID:[A-Z]+}/items" {
+		t.Fatalf("unexpected pattern %q", got)
+	}
+	if asciiLower("/ÄbC") != "/Äbc" {
+		t.Fatalf("unexpected lower %q", asciiLower("/ÄbC"))
+	}
+}
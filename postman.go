@@ -0,0 +1,341 @@
+package owl
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// PostmanCollection is a Postman collection of the v2.1 format, which
+// Insomnia imports too, see App.Postman.
+type PostmanCollection struct {
+	Info     PostmanInfo        `json:"info"`
+	Item     []*PostmanItem     `json:"item"`
+	Variable []*PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanInfo describes the collection.
+type PostmanInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Schema  string `json:"schema"`
+}
+
+// PostmanItem is a request of the collection, or a folder of items.
+type PostmanItem struct {
+	Name    string          `json:"name"`
+	Item    []*PostmanItem  `json:"item,omitempty"` // Folders only
+	Request *PostmanRequest `json:"request,omitempty"`
+}
+
+// PostmanRequest is a request of the collection.
+type PostmanRequest struct {
+	Method      string              `json:"method"`
+	Description string              `json:"description,omitempty"`
+	Header      []*PostmanVariable  `json:"header"`
+	URL         PostmanURL          `json:"url"`
+	Body        *PostmanRequestBody `json:"body,omitempty"`
+}
+
+// PostmanURL is the URL of a request, relative to the {{baseUrl}}
+// variable of the collection.
+type PostmanURL struct {
+	Raw      string             `json:"raw"`
+	Host     []string           `json:"host"`
+	Path     []string           `json:"path"` // Path parameters as ":name"
+	Query    []*PostmanVariable `json:"query,omitempty"`
+	Variable []*PostmanVariable `json:"variable,omitempty"` // Path parameters
+}
+
+// PostmanRequestBody is the body of a request, raw for JSON and with
+// fields for forms.
+type PostmanRequestBody struct {
+	Mode       string             `json:"mode"` // "raw", "urlencoded" or "formdata"
+	Raw        string             `json:"raw,omitempty"`
+	URLEncoded []*PostmanVariable `json:"urlencoded,omitempty"`
+	FormData   []*PostmanVariable `json:"formdata,omitempty"`
+	Options    *PostmanOptions    `json:"options,omitempty"`
+}
+
+// PostmanOptions holds the language of raw bodies.
+type PostmanOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// PostmanVariable is a key and value: a variable of the collection or of a
+// URL, a header, a query parameter or a form field.
+type PostmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"` // "text" or "file" for form fields
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// postmanSchema is the format of the collections exported by App.Postman.
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanMethods orders the requests of a path like an API is used.
+var postmanMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// Postman exports the routes listed by App.OpenAPI as a Postman collection,
+// for QA and manual testing. Requests are named after the summary of their
+// Doc, or their method and path, and placed in a folder named after their
+// first tag. Their URLs start with the {{baseUrl}} variable, set to
+// baseURL, with a variable per path parameter and the query parameters
+// disabled. Bodies hold the Example of the Doc, or a payload made up from
+// the schema of the request:
+//
+//	b, _ := json.MarshalIndent(app.Postman("http://localhost:8080"), "", "  ")
+//	os.WriteFile("api.postman_collection.json", b, 0o644)
+func (a *App) Postman(baseURL string) *PostmanCollection {
+	spec := a.OpenAPI()
+	var schemas map[string]*Schema
+	if spec.Components != nil {
+		schemas = spec.Components.Schemas
+	}
+	ex := examples{refPrefix: "#/components/schemas/", schemas: schemas}
+
+	collection := &PostmanCollection{
+		Info: PostmanInfo{Name: a.name, Version: a.version, Schema: postmanSchema},
+		Item: []*PostmanItem{},
+		Variable: []*PostmanVariable{
+			{Key: "baseUrl", Value: strings.TrimSuffix(baseURL, "/")},
+		},
+	}
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	folders := map[string]*PostmanItem{}
+	for _, path := range paths {
+		for _, method := range postmanMethods {
+			op := spec.Paths[path][method]
+			if op == nil {
+				continue
+			}
+			item := &PostmanItem{Name: op.Summary, Request: ex.request(method, path, op)}
+			if item.Name == "" {
+				item.Name = strings.ToUpper(method) + " " + path
+			}
+			if len(op.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+			folder := folders[op.Tags[0]]
+			if folder == nil {
+				folder = &PostmanItem{Name: op.Tags[0]}
+				folders[op.Tags[0]] = folder
+				collection.Item = append(collection.Item, folder)
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+	return collection
+}
+
+// MountPostman registers GET /postman.json in DevMode, serving the
+// collection exported by Postman with the scheme and host of the request
+// as the base URL. Outside of DevMode, no route is registered.
+// Middlewares, such as authentication, apply to it.
+func (a *App) MountPostman(middlewares ...Middleware) *App {
+	if !a.devMode {
+		return a
+	}
+	return a.GET("/postman.json", func(c *Ctx) error {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		c.SetHeader("Content-Disposition", `attachment; filename="`+postmanFilename(a.name)+`"`)
+		return c.JSON(a.Postman(scheme + "://" + c.Request.Host))
+	}, middlewares...)
+}
+
+// postmanFilename names the downloaded collection after the App.
+func postmanFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name) + ".postman_collection.json"
+}
+
+// examples makes up values matching schemas, for the requests of
+// collections.
+type examples struct {
+	refPrefix string
+	schemas   map[string]*Schema // Referenced schemas by name
+	visiting  map[string]bool    // Referenced schemas being made up, for recursive types
+}
+
+// request returns the collection request of the operation op of path.
+func (ex *examples) request(method, path string, op *OpenAPIOperation) *PostmanRequest {
+	req := &PostmanRequest{
+		Method:      strings.ToUpper(method),
+		Description: op.Description,
+		Header:      []*PostmanVariable{},
+		URL:         PostmanURL{Host: []string{"{{baseUrl}}"}, Path: []string{}},
+	}
+
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			seg = ":" + seg[1:len(seg)-1]
+		}
+		if seg != "" {
+			req.URL.Path = append(req.URL.Path, seg)
+		}
+	}
+	for _, p := range op.Parameters {
+		v := &PostmanVariable{Key: p.Name, Value: ex.text(p.Schema), Description: p.Description}
+		if p.In == "path" {
+			v.Value = ""
+			req.URL.Variable = append(req.URL.Variable, v)
+			continue
+		}
+		v.Disabled = true
+		req.URL.Query = append(req.URL.Query, v) // Disabled, so left out of Raw
+	}
+	req.URL.Raw = "{{baseUrl}}/" + strings.Join(req.URL.Path, "/")
+
+	if op.RequestBody == nil {
+		return req
+	}
+	for _, contentType := range []string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		media := op.RequestBody.Content[contentType]
+		if media == nil {
+			continue
+		}
+		value := media.Example
+		if value == nil {
+			value = ex.value(media.Schema, 0)
+		}
+		if contentType == "multipart/form-data" {
+			req.Body = &PostmanRequestBody{Mode: "formdata", FormData: ex.fields(media.Schema, value)}
+			return req // Postman sets the Content-Type with the boundary
+		}
+		req.Header = append(req.Header, &PostmanVariable{Key: "Content-Type", Value: contentType})
+		if contentType == "application/json" {
+			raw, _ := json.MarshalIndent(value, "", "  ")
+			req.Body = &PostmanRequestBody{Mode: "raw", Raw: string(raw), Options: &PostmanOptions{}}
+			req.Body.Options.Raw.Language = "json"
+		} else {
+			req.Body = &PostmanRequestBody{Mode: "urlencoded", URLEncoded: ex.fields(media.Schema, value)}
+		}
+		return req
+	}
+	return req
+}
+
+// maxExampleDepth bounds the nesting of made up values.
+const maxExampleDepth = 8
+
+// value makes up a value matching s.
+func (ex *examples) value(s *Schema, depth int) interface{} {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, ex.refPrefix)
+		if ex.visiting[name] {
+			return nil // Recursive types stop at the first repetition
+		}
+		if ex.visiting == nil {
+			ex.visiting = map[string]bool{}
+		}
+		ex.visiting[name] = true
+		defer delete(ex.visiting, name)
+		return ex.value(ex.schemas[name], depth+1)
+	}
+
+	switch s.Type {
+	case "object":
+		obj := map[string]interface{}{}
+		for name, prop := range s.Properties {
+			if prop.Deprecated {
+				continue
+			}
+			obj[name] = ex.value(prop, depth+1)
+		}
+		return obj
+	case "array":
+		if item := ex.value(s.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "date":
+			return "2006-01-02"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "binary":
+			return ""
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// text makes up the text of a parameter or form field matching s.
+func (ex *examples) text(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	if s.Type == "array" {
+		s = s.Items
+	}
+	switch v := ex.value(s, maxExampleDepth).(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// fields returns the form fields of the object schema s, with the values
+// of example, a value encoding to a JSON object, when it has them.
+func (ex *examples) fields(s *Schema, example interface{}) []*PostmanVariable {
+	var values map[string]interface{}
+	if b, err := json.Marshal(example); err == nil {
+		_ = json.Unmarshal(b, &values) // Made up values otherwise
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]*PostmanVariable, 0, len(names))
+	for _, name := range names {
+		prop := s.Properties[name]
+		field := &PostmanVariable{Key: name, Type: "text", Description: prop.Description}
+		if prop.Format == "binary" || (prop.Items != nil && prop.Items.Format == "binary") {
+			field.Type = "file"
+		} else if v, ok := values[name].(string); ok {
+			field.Value = v
+		} else if v, ok := values[name]; ok && v != nil {
+			b, _ := json.Marshal(v)
+			field.Value = string(b)
+		} else {
+			field.Value = ex.text(prop)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
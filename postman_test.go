@@ -0,0 +1,82 @@
+package owl
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+)
+
+type uploadAvatarRequest struct {
+	Caption string                `form:"caption"`
+	File    *multipart.FileHeader `form:"file"`
+}
+
+func TestPostman(t *testing.T) {
+	ok := func(c *Ctx) error { return nil }
+	app := New(AppConfig{Name: "shop api", DevMode: true})
+	api := app.Group("/api").Tag("users")
+	api.GET("/users", ok).Doc(Doc{Request: listUsersRequest{}})
+	api.POST("/users", ok).Doc(Doc{Summary: "Create a user", Request: createUserRequest{}})
+	api.PUT("/users/{id:int}", ok).Doc(Doc{Request: apiUser{}})
+	api.POST("/users/{id}/avatar", ok).Doc(Doc{Request: uploadAvatarRequest{}})
+	app.POST("/orders", ok).Doc(Doc{Example: map[string]interface{}{"customer": "ada"}})
+	app.MountPostman()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/postman.json", nil))
+	var collection PostmanCollection
+	if err := json.Unmarshal(w.Body.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection.Info.Name != "shop api" || collection.Info.Schema != postmanSchema || collection.Variable[0].Value != "http://example.com" {
+		t.Fatalf("unexpected collection %+v", collection.Info)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="shop_api.postman_collection.json"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+
+	if len(collection.Item) != 3 {
+		t.Fatalf("expected a folder and two requests, got %d items", len(collection.Item))
+	}
+	users := collection.Item[0]
+	if users.Name != "users" || len(users.Item) != 4 {
+		t.Fatalf("expected the routes tagged users in a folder, got %+v", users)
+	}
+	list, create, update, avatar := users.Item[0].Request, users.Item[1], users.Item[2].Request, users.Item[3].Request
+	if list.URL.Raw != "{{baseUrl}}/api/users" || len(list.URL.Query) != 2 || !list.URL.Query[0].Disabled || list.URL.Query[0].Value != "0" {
+		t.Fatalf("unexpected list request %+v", list.URL)
+	}
+	if create.Name != "Create a user" || create.Request.Body.Raw != "{\n  \"name\": \"string\"\n}" || create.Request.Header[0].Value != "application/json" {
+		t.Fatalf("unexpected create request %+v", create.Request.Body)
+	}
+	if update.URL.Raw != "{{baseUrl}}/api/users/:id" || update.URL.Variable[0].Key != "id" {
+		t.Fatalf("unexpected update request %+v", update.URL)
+	}
+	var user map[string]interface{}
+	if err := json.Unmarshal([]byte(update.Body.Raw), &user); err != nil {
+		t.Fatal(err)
+	}
+	if manager, ok := user["manager"]; !ok || manager != nil {
+		t.Errorf("expected recursive types to stop at null, got %v", manager)
+	}
+	if _, ok := user["nickname"]; ok || user["created"] != "2006-01-02T15:04:05Z" {
+		t.Errorf("unexpected payload %v", user)
+	}
+	if avatar.Body.Mode != "formdata" || len(avatar.Body.FormData) != 2 || avatar.Body.FormData[1].Type != "file" || len(avatar.Header) != 0 {
+		t.Fatalf("unexpected avatar request %+v", avatar.Body)
+	}
+
+	orders := collection.Item[1]
+	if orders.Name != "POST /orders" || orders.Request.Body.Raw != "{\n  \"customer\": \"ada\"\n}" {
+		t.Fatalf("expected the example of the Doc, got %+v", orders.Request.Body)
+	}
+
+	app = New()
+	app.MountPostman()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/postman.json", nil))
+	if w.Code != 404 {
+		t.Errorf("expected no endpoint outside of DevMode, got %d", w.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package owl
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Predicate reports whether a request should be served by a conditional
+// route, see Group.When.
+type Predicate func(r *http.Request) bool
+
+// HeaderIs matches requests whose header key equals value, ignoring case.
+func HeaderIs(key, value string) Predicate {
+	return func(r *http.Request) bool {
+		return strings.EqualFold(r.Header.Get(key), value)
+	}
+}
+
+// HeaderContains matches requests whose header key contains substr,
+// ignoring case. It is handy for list-valued headers such as Accept:
+//
+//	HeaderContains("Accept", "text/csv")
+func HeaderContains(key, substr string) Predicate {
+	substr = strings.ToLower(substr)
+	return func(r *http.Request) bool {
+		for _, v := range r.Header.Values(key) {
+			if strings.Contains(strings.ToLower(v), substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderExists matches requests carrying header key, whatever its value.
+func HeaderExists(key string) Predicate {
+	return func(r *http.Request) bool {
+		_, ok := r.Header[http.CanonicalHeaderKey(key)]
+		return ok
+	}
+}
+
+// When makes the routes registered by the previous call conditional: they
+// only serve requests matching all predicates. Several handlers can then
+// share a method and path:
+//
+//	g.GET("/export", exportCSV).When(owl.HeaderContains("Accept", "text/csv"))
+//	g.GET("/export", exportJSON)
+//
+// Conditional routes are tried in registration order; the last
+// unconditional route for the method and path serves the remaining
+// requests, and without one they get a 404.
+func (g *Group) When(predicates ...Predicate) *Group {
+	when(g.last, predicates)
+	return g
+}
+
+// When makes the handlers registered by the previous call conditional, see
+// Group.When.
+func (rb *RouteBuilder) When(predicates ...Predicate) *RouteBuilder {
+	when(rb.last, predicates)
+	return rb
+}
+
+// When makes the routes registered by the previous call conditional, see
+// Group.When.
+func (a *App) When(predicates ...Predicate) *App {
+	when(a.last, predicates)
+	return a
+}
+
+func when(routes []*conditionalRoute, predicates []Predicate) {
+	if len(routes) == 0 {
+		panic("owl: When must follow a route registration")
+	}
+	for _, route := range routes {
+		route.when = append(route.when, predicates...)
+	}
+}
+
+// routeKey identifies the handlers registered for a method and path.
+type routeKey struct {
+	mux    RouterBackend
+	method string
+	path   string
+}
+
+// routeSet dispatches a request to the first conditional route whose
+// predicates match, or else to the last unconditional route.
+type routeSet struct {
+	app    *App
+	mux    RouterBackend
+	routes []*conditionalRoute
+
+	handler http.Handler // Set on compilation when a single route can't fall through
+}
+
+type conditionalRoute struct {
+	when     []Predicate
+	h        Handler
+	scope    *scope
+	opts     routeOptions
+	wildcard string // Name of the catch-all, see splitWildcard
+	source   string // Call site of the registration, see App.CheckRoutes
+	doc      *Doc   // See RouteBuilder.Doc
+
+	// Set by Build.
+	handler     http.Handler
+	middlewares []string // Owl-style middleware names, see App.MiddlewareChain
+}
+
+func (s *routeSet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.app.stale.Load() {
+		s.app.compile()
+	}
+	if s.handler != nil {
+		s.handler.ServeHTTP(w, r)
+		return
+	}
+
+	var fallback http.Handler
+	for _, route := range s.routes {
+		if len(route.when) == 0 {
+			fallback = route.handler
+			continue
+		}
+		if route.matches(r) {
+			route.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	if fallback == nil {
+		fallback = s.mux.NotFoundHandler()
+	}
+	fallback.ServeHTTP(w, r)
+}
+
+func (route *conditionalRoute) matches(r *http.Request) bool {
+	for _, p := range route.when {
+		if !p(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalRoutes(t *testing.T) {
+	text := func(s string) Handler {
+		return func(c *Ctx) error { return c.Text(s) }
+	}
+
+	app := New()
+	api := app.Group("/api")
+	api.GET("/export", text("csv")).When(HeaderContains("Accept", "text/csv"))
+	api.GET("/export", text("xml")).When(HeaderIs("Accept", "application/XML"))
+	api.GET("/export", text("json"))
+	api.Route("/beta").GET(text("beta")).When(HeaderExists("X-Beta"))
+	app.GET("/legacy", text("v1")).When(HeaderIs("X-Version", "1"))
+	app.GET("/legacy", text("v2"))
+
+	tests := []struct {
+		path, header, value string
+		code                int
+		body                string
+	}{
+		{"/api/export", "Accept", "text/csv, */*", 200, "csv"},
+		{"/api/export", "Accept", "application/xml", 200, "xml"},
+		{"/api/export", "Accept", "application/json", 200, "json"},
+		{"/api/export", "", "", 200, "json"},
+		{"/api/beta", "X-Beta", "", 200, "beta"},
+		{"/api/beta", "", "", 404, ""},
+		{"/legacy", "X-Version", "1", 200, "v1"},
+		{"/legacy", "X-Version", "2", 200, "v2"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			r.Header.Set(tt.header, tt.value)
+		}
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s %s=%q: got %d %q", tt.path, tt.header, tt.value, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !tinygo
+// +build !tinygo
+
+package owl
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// MountProfiler registers net/http/pprof and expvar below prefix, behind
+// middlewares such as authentication:
+//
+//	app.MountProfiler("/debug", requireAdmin)
+//
+// serves /debug/pprof/ with its profiles, such as
+// /debug/pprof/profile?seconds=30 or /debug/pprof/heap, and /debug/vars.
+// Unlike http.DefaultServeMux, which pprof registers itself on, nothing is
+// exposed unless mounted. MountProfiler panics when called without
+// middlewares while the OWL_ENV or APP_ENV environment variable is
+// "production" or "prod".
+func (a *App) MountProfiler(prefix string, middlewares ...Middleware) *App {
+	if len(middlewares) == 0 && IsProduction() {
+		panic("owl: MountProfiler without middlewares in production, profiles would be public")
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	noStore := func(h http.Handler) Handler {
+		return func(c *Ctx) error {
+			c.SetHeader("Cache-Control", "no-store")
+			h.ServeHTTP(c.Response, c.Request)
+			return nil
+		}
+	}
+
+	g := a.Group(prefix, middlewares...)
+	g.GET("/pprof", func(c *Ctx) error {
+		http.Redirect(c.Response, c.Request, prefix+"/pprof/", http.StatusMovedPermanently)
+		return nil
+	})
+	g.GET("/pprof/", noStore(http.HandlerFunc(pprof.Index)))
+	g.GET("/pprof/cmdline", noStore(http.HandlerFunc(pprof.Cmdline)))
+	g.GET("/pprof/profile", noStore(http.HandlerFunc(pprof.Profile)))
+	g.Match([]string{http.MethodGet, http.MethodPost}, "/pprof/symbol", noStore(http.HandlerFunc(pprof.Symbol)))
+	g.GET("/pprof/trace", noStore(http.HandlerFunc(pprof.Trace)))
+	// pprof.Index only serves named profiles below /debug/pprof/.
+	g.GET("/pprof/{name}", func(c *Ctx) error {
+		return noStore(pprof.Handler(c.Param("name")))(c)
+	})
+	g.GET("/vars", noStore(expvar.Handler()))
+	return a
+}
@@ -0,0 +1,51 @@
+//go:build !tinygo
+// +build !tinygo
+
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountProfiler(t *testing.T) {
+	requireAdmin := Guard(HeaderIs("X-Admin", "1"), NewHTTPError(http.StatusForbidden, "Forbidden"))
+	app := New()
+	app.MountProfiler("/_debug", requireAdmin)
+
+	get := func(path string, admin bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if admin {
+			r.Header.Set("X-Admin", "1")
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("/_debug/pprof/", false); w.Code != http.StatusForbidden {
+		t.Fatalf("expected the profiler behind middlewares, got %d", w.Code)
+	}
+	if w := get("/_debug/pprof/", true); w.Code != 200 || !strings.Contains(w.Body.String(), "goroutine") || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("index: got %d, headers %v", w.Code, w.Header())
+	}
+	if w := get("/_debug/pprof/goroutine?debug=1", true); w.Code != 200 || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("goroutine profile: got %d %.100s", w.Code, w.Body.String())
+	}
+	if w := get("/_debug/vars", true); w.Code != 200 || !strings.Contains(w.Body.String(), "memstats") {
+		t.Fatalf("vars: got %d", w.Code)
+	}
+	if w := get("/_debug/pprof", true); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/_debug/pprof/" {
+		t.Fatalf("redirect: got %d, headers %v", w.Code, w.Header())
+	}
+
+	t.Setenv("OWL_ENV", "production")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic without middlewares in production")
+		}
+	}()
+	New().MountProfiler("/debug")
+}
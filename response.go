@@ -1,21 +1,115 @@
 package owl
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
+	"sync"
 )
 
-// JSON sends a JSON response with the given status code.
+// JSON sends a JSON response with the given status code. The value is
+// encoded before anything is written, so that an encoding error leaves
+// the response untouched for the error handler, and the response carries
+// a Content-Length.
 func JSON(w http.ResponseWriter, code int, data interface{}) error {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return writeJSON(w, code, data, "")
+}
+
+// bufferPool holds the buffers responses are encoded in, see getBuffer.
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which buffers aren't pooled, so
+// that a few large responses don't pin memory.
+const maxPooledBuffer = 64 << 10
+
+// getBuffer returns an empty buffer, to give back with putBuffer once
+// written.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// copyBufferPool holds the buffers of copyBuffer.
+var copyBufferPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 32<<10)
+	return &b
+}}
+
+// copyBuffer is io.Copy with a pooled buffer, for writers that don't
+// implement io.ReaderFrom.
+func copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	b := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(b)
+	return io.CopyBuffer(dst, src, *b)
+}
+
+// writeBuffer sends buf as the response body, with a Content-Length.
+func writeBuffer(w http.ResponseWriter, code int, contentType string, buf *bytes.Buffer) error {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Length", strconv.Itoa(buf.Len()))
 	w.WriteHeader(code)
-	return json.NewEncoder(w).Encode(data)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSON sends data encoded in a pooled buffer, indented with indent
+// when not empty.
+func writeJSON(w http.ResponseWriter, code int, data interface{}, indent string) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	enc := json.NewEncoder(buf)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+	return writeBuffer(w, code, "application/json; charset=utf-8", buf)
 }
 
 // Text sends a plain text response with the given status code.
 func Text(w http.ResponseWriter, code int, text string) error {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	return writeString(w, code, "text/plain; charset=utf-8", text)
+}
+
+// HTML sends an HTML response with the given status code.
+func HTML(w http.ResponseWriter, code int, html string) error {
+	return writeString(w, code, "text/html; charset=utf-8", html)
+}
+
+// CSV sends records as a CSV response with the given status code. The
+// records are encoded before anything is written, like with JSON.
+func CSV(w http.ResponseWriter, code int, records [][]string) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	cw := csv.NewWriter(buf)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return writeBuffer(w, code, "text/csv; charset=utf-8", buf)
+}
+
+// writeString sends s as the response body, with a Content-Length. The
+// string isn't copied when w implements io.StringWriter, as the writers of
+// net/http and Apps do.
+func writeString(w http.ResponseWriter, code int, contentType, s string) error {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Length", strconv.Itoa(len(s)))
 	w.WriteHeader(code)
-	_, err := w.Write([]byte(text))
+	_, err := io.WriteString(w, s)
 	return err
 }
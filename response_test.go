@@ -0,0 +1,88 @@
+package owl
+
+import (
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJSONContentLength(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := JSON(w, 201, map[string]string{"name": "owl"}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != 201 || w.Body.String() != "{\"name\":\"owl\"}\n" || w.Header().Get("Content-Length") != "15" {
+		t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	if err := JSON(w, 200, math.NaN()); err == nil {
+		t.Fatal("expected the encoding error")
+	}
+	if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Fatalf("expected nothing to be written on encoding errors, got %q %v", w.Body.String(), w.Header())
+	}
+}
+
+func TestTextHTMLCSV(t *testing.T) {
+	tests := []struct {
+		write       func(w *httptest.ResponseRecorder) error
+		contentType string
+		body        string
+	}{
+		{func(w *httptest.ResponseRecorder) error { return Text(w, 200, "héllo") }, "text/plain; charset=utf-8", "héllo"},
+		{func(w *httptest.ResponseRecorder) error { return HTML(w, 200, "<p>hi</p>") }, "text/html; charset=utf-8", "<p>hi</p>"},
+		{func(w *httptest.ResponseRecorder) error {
+			return CSV(w, 200, [][]string{{"id", "name"}, {"1", "owl, barn"}})
+		}, "text/csv; charset=utf-8", "id,name\n1,\"owl, barn\"\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := tt.write(w); err != nil {
+			t.Fatal(err)
+		}
+		if w.Body.String() != tt.body || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("unexpected response %q %v", w.Body.String(), w.Header())
+		}
+		if w.Header().Get("Content-Length") != strconv.Itoa(len(tt.body)) {
+			t.Errorf("%s: unexpected Content-Length %q", tt.contentType, w.Header().Get("Content-Length"))
+		}
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	data := map[string]interface{}{"id": 1, "name": "owl", "tags": []string{"a", "b"}}
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Body.Reset()
+		JSON(w, 200, data)
+	}
+}
+
+func BenchmarkText(b *testing.B) {
+	text := strings.Repeat("owl ", 16<<10)
+	rec := httptest.NewRecorder()
+	w := &responseWriter{ResponseWriter: rec} // As served by an App
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+	for i := 0; i < b.N; i++ {
+		rec.Body.Reset()
+		Text(w, 200, text)
+	}
+}
+
+func BenchmarkCSV(b *testing.B) {
+	records := make([][]string, 1000)
+	for i := range records {
+		records[i] = []string{strconv.Itoa(i), "owl", "barn owl, tyto alba"}
+	}
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Body.Reset()
+		CSV(w, 200, records)
+	}
+}
@@ -0,0 +1,139 @@
+package owl
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseState reports what was written to a response. The writer App
+// hands to its middlewares and handlers implements it, as does
+// middleware.WrapResponseWriter, so that middlewares needing the status or
+// size of the response, such as loggers and metrics, reuse it rather than
+// stacking their own wrappers:
+//
+//	state, ok := owl.ResponseStateOf(w)
+//	if !ok {
+//		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+//		w, state = ww, ww
+//	}
+//	next.ServeHTTP(w, r)
+//	metrics.Observe(state.Status(), state.BytesWritten())
+type ResponseState interface {
+	// Status returns the status sent, or 0 before the response is written.
+	Status() int
+	// BytesWritten returns the number of body bytes written.
+	BytesWritten() int
+}
+
+// ResponseStateOf returns the ResponseState of w, when w tracks one itself.
+// Writers wrapping it are not looked through, since they may buffer.
+func ResponseStateOf(w http.ResponseWriter) (ResponseState, bool) {
+	s, ok := w.(ResponseState)
+	return s, ok
+}
+
+// responseWriter is the ResponseState tracking writer of App.ServeHTTP. It
+// implements http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom,
+// which report http.ErrNotSupported when the writer it wraps doesn't.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) Status() int       { return w.status }
+func (w *responseWriter) BytesWritten() int { return w.bytes }
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 && (code < 100 || code > 199 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := io.WriteString(w.ResponseWriter, s)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = copyBuffer(w.ResponseWriter, r)
+	}
+	w.bytes += int(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ResponseStatus returns the status sent, or 0 before the response is
+// written, see ResponseState. It is also 0 when a middleware replaced
+// c.Response with a writer not tracking it.
+func (c *Ctx) ResponseStatus() int {
+	if s, ok := ResponseStateOf(c.Response); ok {
+		return s.Status()
+	}
+	return 0
+}
+
+// BytesWritten returns the number of body bytes written, see
+// ResponseStatus.
+func (c *Ctx) BytesWritten() int {
+	if s, ok := ResponseStateOf(c.Response); ok {
+		return s.BytesWritten()
+	}
+	return 0
+}
+
+// Written reports whether the response was started, after which its
+// status and headers can't change.
+func (c *Ctx) Written() bool {
+	return c.ResponseStatus() != 0
+}
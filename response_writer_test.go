@@ -0,0 +1,46 @@
+package owl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseState(t *testing.T) {
+	app := New()
+	var states []int
+	app.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state, ok := ResponseStateOf(w)
+			if !ok {
+				t.Error("expected the App writer to track the response")
+				return
+			}
+			next.ServeHTTP(w, r)
+			states = append(states, state.Status(), state.BytesWritten())
+		})
+	})
+	app.Use(Middleware(func(next Handler) Handler {
+		return func(c *Ctx) error {
+			if c.Written() {
+				t.Error("expected the response not to be written yet")
+			}
+			err := next(c)
+			states = append(states, c.ResponseStatus(), c.BytesWritten())
+			return err
+		}
+	}))
+	app.GET("/", func(c *Ctx) error {
+		if _, _, err := c.Response.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("expected Hijack to be unsupported by the recorder, got %v", err)
+		}
+		return c.Status(http.StatusAccepted).Text("hello")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusAccepted || len(states) != 4 || states[0] != 202 || states[1] != 5 || states[2] != 202 || states[3] != 5 {
+		t.Fatalf("unexpected response states %v", states)
+	}
+}
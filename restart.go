@@ -0,0 +1,116 @@
+package owl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Environment of a process started by a Supervisor restart, see
+// SupervisorConfig.RestartSignal.
+const (
+	envRestartAddrs = "OWL_RESTART_ADDRS" // Addresses of the inherited listeners, comma-separated
+	envRestartPPID  = "OWL_RESTART_PPID"  // PID of the process to stop once serving
+)
+
+// inheritedFirstFD is the file descriptor of the first listener passed to
+// the new process, after stdin, stdout and stderr.
+const inheritedFirstFD = 3
+
+// restartArgs returns the arguments of the new process.
+var restartArgs = func() []string { return os.Args[1:] }
+
+// inheritedListeners returns the listeners passed by the process that
+// restarted this one, by the address they were opened for, and unsets the
+// restart environment.
+func inheritedListeners() (map[string]net.Listener, error) {
+	addrs := os.Getenv(envRestartAddrs)
+	os.Unsetenv(envRestartAddrs)
+	if addrs == "" {
+		return nil, nil
+	}
+	listeners := map[string]net.Listener{}
+	for i, addr := range strings.Split(addrs, ",") {
+		f := os.NewFile(uintptr(inheritedFirstFD+i), "listener "+addr)
+		ln, err := net.FileListener(f)
+		f.Close() // FileListener holds a duplicate
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("owl: inherited listener '%s': %w", addr, err)
+		}
+		listeners[addr] = ln
+	}
+	return listeners, nil
+}
+
+// stopParent asks the process that restarted this one to shut down, with
+// the first shutdown signal, once this one serves.
+func (s *Supervisor) stopParent() {
+	ppid := os.Getenv(envRestartPPID)
+	os.Unsetenv(envRestartPPID)
+	if ppid == "" || ppid != strconv.Itoa(os.Getppid()) {
+		return
+	}
+	if p, err := os.FindProcess(os.Getppid()); err == nil {
+		p.Signal(s.signals[0])
+	}
+}
+
+// restart starts a new instance of the executable with the same arguments,
+// handing it the listeners opened by the Supervisor, by the address of the
+// supervised App. The new process stops this one once it serves, and this
+// one keeps serving until then, so that no connection is refused.
+func (s *Supervisor) restart(members []*supervised, listeners []net.Listener) error {
+	var addrs []string
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for i, m := range members {
+		if m.ln != nil || listeners[i] == nil {
+			continue // Given to AddListener, or opened by a transport
+		}
+		fl, ok := listeners[i].(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		if ul, ok := listeners[i].(*net.UnixListener); ok {
+			// The socket file is now shared with the new process.
+			ul.SetUnlinkOnClose(false)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return err
+		}
+		addrs = append(addrs, m.listen)
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return errors.New("owl: no listener to hand over")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, restartArgs()...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		envRestartAddrs+"="+strings.Join(addrs, ","),
+		envRestartPPID+"="+strconv.Itoa(os.Getpid()),
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // Reaps the new process should it fail to start
+	return nil
+}
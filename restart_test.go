@@ -0,0 +1,84 @@
+//go:build unix
+
+package owl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSupervisorRestart(t *testing.T) {
+	if os.Getenv(envRestartAddrs) != "" {
+		// The new process started by the restart below.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		app := New()
+		app.GET("/", func(c *Ctx) error { return c.Text("new") })
+		app.GET("/stop", func(c *Ctx) error {
+			cancel()
+			return c.Text("stopping")
+		})
+		sup := NewSupervisor(SupervisorConfig{RestartSignal: syscall.SIGUSR2})
+		sup.Add("127.0.0.1:0", app)
+		if err := sup.Run(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	defer func(args func() []string) { restartArgs = args }(restartArgs)
+	restartArgs = func() []string { return []string{"-test.run=^TestSupervisorRestart$"} }
+
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("old") })
+	sup := NewSupervisor(SupervisorConfig{RestartSignal: syscall.SIGUSR2, ShutdownTimeout: time.Second})
+	sup.Add("127.0.0.1:0", app)
+	done := make(chan error)
+	go func() { done <- sup.Run(context.Background()) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("App didn't start: %+v", apps)
+		}
+	}
+	base := "http://" + apps[0].Addr
+	get := func() string {
+		resp, err := http.Get(base + "/")
+		if err != nil {
+			t.Fatalf("expected no request to be refused, got %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := get(); body != "old" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the old process to stop cleanly, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new process didn't take over")
+	}
+	http.DefaultClient.CloseIdleConnections()
+	if body := get(); body != "new" {
+		t.Fatalf("expected the new process to serve the listener, got %q", body)
+	}
+	if resp, err := http.Get(base + "/stop"); err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,136 @@
+package owl
+
+import (
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResultHandler is a handler returning its response value instead of
+// writing it, see H2.
+type ResultHandler func(c *Ctx) (interface{}, error)
+
+// StatusCoder is implemented by result values choosing their own status.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// H2 adapts a ResultHandler to a Handler, for teams preferring
+// result-style handlers over `return c.JSON(...)`:
+//
+//	app.GET("/users/{id}", owl.H2(func(c *owl.Ctx) (interface{}, error) {
+//		return users.Get(c.Param("id"))
+//	}))
+//
+// Errors go to the ErrorHandler as usual. Values are encoded as JSON, or
+// as XML when the Accept header prefers it and the value has an XML
+// encoding; requests accepting neither get 406 Not Acceptable. The status is, in order of precedence, one other
+// than 200 set with c.Status, the one of a value implementing StatusCoder,
+// 204 No Content for a nil value, 201 Created for POST and 200 OK
+// otherwise.
+func H2(h ResultHandler) Handler {
+	return func(c *Ctx) error {
+		v, err := h(c)
+		if err != nil {
+			return err
+		}
+		return writeResult(c, v)
+	}
+}
+
+// writeResult writes the value returned by a result-style handler, see H2.
+func writeResult(c *Ctx, v interface{}) error {
+	status := c.status
+	if status == http.StatusOK {
+		switch sc, ok := v.(StatusCoder); {
+		case ok:
+			status = sc.StatusCode()
+		case v == nil:
+			status = http.StatusNoContent
+		case c.Request.Method == http.MethodPost:
+			status = http.StatusCreated
+		}
+	}
+	if v == nil || status == http.StatusNoContent {
+		c.Response.WriteHeader(status)
+		return nil
+	}
+
+	c.Response.Header().Add("Vary", "Accept")
+	accept := c.Request.Header.Get("Accept")
+	contentType := negotiate(accept, "application/json", "application/xml", "text/xml")
+	if contentType == "application/xml" || contentType == "text/xml" {
+		b, err := xml.Marshal(v)
+		if err == nil {
+			c.Response.Header().Set("Content-Type", contentType+"; charset=utf-8")
+			c.Response.WriteHeader(status)
+			_, err = c.Response.Write(append([]byte(xml.Header), b...))
+			return err
+		}
+		// Values such as maps have no XML encoding: fall back to JSON
+		// when the client accepts it.
+		contentType = negotiate(accept, "application/json")
+	}
+	if contentType == "application/json" {
+		return JSON(c.Response, status, v)
+	}
+	return NewHTTPError(http.StatusNotAcceptable, "Not Acceptable")
+}
+
+// negotiate returns the offer preferred by the Accept header accept, the
+// first offer when accept is empty, or "" when none is acceptable.
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	type rangeQ struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []rangeQ
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, rangeQ{mediaType, q})
+	}
+	// More specific ranges win over wildcards at equal quality.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+
+	excluded := map[string]bool{}
+	for _, r := range ranges {
+		if r.q <= 0 {
+			excluded[r.mediaType] = true
+		}
+	}
+	for _, r := range ranges {
+		if r.q <= 0 {
+			continue
+		}
+		for _, offer := range offers {
+			if excluded[offer] {
+				continue
+			}
+			if r.mediaType == offer || r.mediaType == "*/*" ||
+				(strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(r.mediaType, "*"))) {
+				return offer
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type accepted struct {
+	ID string `json:"id" xml:"id"`
+}
+
+func (accepted) StatusCode() int { return 202 }
+
+type testUser struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestH2(t *testing.T) {
+	app := New()
+	app.GET("/user", H2(func(c *Ctx) (interface{}, error) {
+		return testUser{Name: "ada"}, nil
+	}))
+	app.POST("/user", H2(func(c *Ctx) (interface{}, error) {
+		return testUser{Name: "bob"}, nil
+	}))
+	app.DELETE("/user", H2(func(c *Ctx) (interface{}, error) {
+		return nil, nil
+	}))
+	app.POST("/jobs", H2(func(c *Ctx) (interface{}, error) {
+		return accepted{ID: "1"}, nil
+	}))
+	app.PUT("/teapot", H2(func(c *Ctx) (interface{}, error) {
+		c.Status(418)
+		return accepted{}, nil
+	}))
+	app.GET("/missing", H2(func(c *Ctx) (interface{}, error) {
+		return nil, ErrNotFound
+	}))
+	app.GET("/counts", H2(func(c *Ctx) (interface{}, error) {
+		return map[string]int{"users": 2}, nil
+	}))
+
+	tests := []struct {
+		method, path, accept string
+		code                 int
+		contentType, body    string
+	}{
+		{"GET", "/user", "", 200, "application/json", `{"name":"ada"}`},
+		{"GET", "/user", "application/xml, application/json;q=0.5", 200, "application/xml", "<testUser><name>ada</name></testUser>"},
+		{"GET", "/user", "text/*", 200, "text/xml", "<name>ada</name>"},
+		{"GET", "/user", "application/json;q=0, */*", 200, "application/xml", "<name>ada</name>"},
+		{"GET", "/user", "text/html", 406, "application/json", "Not Acceptable"},
+		{"POST", "/user", "*/*", 201, "application/json", `{"name":"bob"}`},
+		{"DELETE", "/user", "", 204, "", ""},
+		{"POST", "/jobs", "", 202, "application/json", `{"id":"1"}`},
+		{"PUT", "/teapot", "", 418, "application/json", `{"id":""}`},
+		{"GET", "/missing", "", 404, "application/json", "Not Found"},
+		{"GET", "/counts", "application/xml, application/json;q=0.5", 200, "application/json", `{"users":2}`},
+		{"GET", "/counts", "application/xml", 406, "application/json", "Not Acceptable"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code || !strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType) || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s %s (Accept %q): got %d %q %s", tt.method, tt.path, tt.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+}
@@ -1,164 +1,378 @@
 package owl
 
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Middleware composition
+//
+// A route runs the Owl-style middlewares of its App, Group and
+// RouteBuilder, followed by the route's own middlewares, in that order.
+// Chains are assembled when the App is built, so a middleware added with
+// Use or With applies to every route below that App, Group or RouteBuilder,
+// whether it was registered before or after. Middlewares passed to one
+// route never leak into another. Once the App is built, adding middlewares
+// panics, the same way Mux.Use does for net/http middlewares.
+//
+// Route options such as BodyLimit, Meta or Timeout are still copied when a
+// route or sub-group is created, and must be set before.
+
 // Group represents a route group.
 type Group struct {
-	app         *App
-	prefix      string
-	middlewares []Middleware
+	app    *App
+	mux    RouterBackend
+	prefix string
+	scope  *scope
+	opts   routeOptions
+	sealed bool                // set once routes or sub-groups were derived from the group
+	last   []*conditionalRoute // routes of the last registration, see When
 }
 
-// Use adds middlewares to this group.
+// Use adds middlewares to this group. They apply to all routes of the group
+// and its sub-groups, including those registered earlier. It panics once
+// the App is built.
 func (g *Group) Use(middlewares ...Middleware) *Group {
-	g.middlewares = append(g.middlewares, middlewares...)
+	g.app.mutate("Use")
+	g.scope.middlewares = append(g.scope.middlewares, middlewares...)
+	return g
+}
+
+// BodyLimit overrides the App's BodyLimit for routes of this group and its
+// sub-groups (0 = unlimited). It must be called before routes or
+// sub-groups are created.
+//
+//	app.Group("/auth").BodyLimit(64 * owl.KB).POST("/login", login)
+//	app.Group("/files").BodyLimit(200 * owl.MB).POST("/", upload)
+func (g *Group) BodyLimit(n int64) *Group {
+	if g.sealed {
+		panic("owl: BodyLimit must be set before routes on a group")
+	}
+	g.opts.bodyLimit = n
+	return g
+}
+
+// StrictJSON overrides the App's StrictJSON setting for routes of this
+// group and its sub-groups. It must be called before routes or
+// sub-groups are created.
+func (g *Group) StrictJSON(strict bool) *Group {
+	if g.sealed {
+		panic("owl: StrictJSON must be set before routes on a group")
+	}
+	g.opts.strictJSON = strict
+	return g
+}
+
+// ValidateJSON overrides the App's ValidateJSON setting for routes of this
+// group and its sub-groups. It must be called before routes or
+// sub-groups are created.
+func (g *Group) ValidateJSON(validate bool) *Group {
+	if g.sealed {
+		panic("owl: ValidateJSON must be set before routes on a group")
+	}
+	g.opts.validateJSON = validate
+	return g
+}
+
+// Timeout sets a deadline for the handlers of this group and its
+// sub-groups, see RouteBuilder.Timeout. It must be called before
+// routes or sub-groups are created.
+func (g *Group) Timeout(d time.Duration) *Group {
+	if g.sealed {
+		panic("owl: Timeout must be set before routes on a group")
+	}
+	g.opts.timeout = d
+	return g
+}
+
+// Meta attaches a metadata key/value to the routes of this group and its
+// sub-groups. Owl-style middlewares and handlers read it with Ctx.Meta, so
+// cross-cutting concerns can branch on declared route properties instead
+// of path prefixes:
+//
+//	admin := app.Group("/admin").Meta("auth", "required")
+//	admin.Use(func(next owl.Handler) owl.Handler {
+//		return func(c *owl.Ctx) error {
+//			if c.Meta("auth") == "required" && !loggedIn(c) {
+//				return owl.NewHTTPError(http.StatusUnauthorized, "login required")
+//			}
+//			return next(c)
+//		}
+//	})
+//
+// It must be called before routes or sub-groups are created.
+func (g *Group) Meta(key string, value interface{}) *Group {
+	if g.sealed {
+		panic("owl: Meta must be set before routes on a group")
+	}
+	g.opts = g.opts.withMeta(key, value)
+	return g
+}
+
+// Attr declares a static attribute, such as the owning team, domain or
+// criticality, for the routes of this group and its sub-groups:
+//
+//	billing := app.Group("/billing").Attr("team", "payments").Attr("criticality", "high")
+//
+// Attributes are meant for observability: the Logger middleware appends
+// them to its log lines, owlotel sets them on the request span, and custom
+// middlewares read them with Context.RouteAttrs after calling the next
+// handler. It must be called before routes or sub-groups are
+// created.
+func (g *Group) Attr(key, value string) *Group {
+	if g.sealed {
+		panic("owl: Attr must be set before routes on a group")
+	}
+	g.opts = g.opts.withAttr(key, value)
+	return g
+}
+
+// Tag adds tags to the routes of this group and its sub-groups, readable
+// with Ctx.Tags and Ctx.HasTag. It must be called before routes
+// or sub-groups are created.
+func (g *Group) Tag(tags ...string) *Group {
+	if g.sealed {
+		panic("owl: Tag must be set before routes on a group")
+	}
+	g.opts = g.opts.withTags(tags...)
 	return g
 }
 
 // Group creates a sub-group.
 func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(g.middlewares))
-	copy(mws, g.middlewares)
-	mws = append(mws, middlewares...)
-
+	g.sealed = true
 	return &Group{
-		app:         g.app,
-		prefix:      g.prefix + prefix,
-		middlewares: mws,
+		app:    g.app,
+		mux:    g.mux,
+		prefix: g.prefix + prefix,
+		scope:  g.scope.child(middlewares...),
+		opts:   g.opts,
 	}
 }
 
 // Route creates a RouteBuilder.
 func (g *Group) Route(path string, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(g.middlewares))
-	copy(mws, g.middlewares)
-	mws = append(mws, middlewares...)
-
+	g.sealed = true
 	return &RouteBuilder{
-		app:         g.app,
-		path:        g.prefix + path,
-		middlewares: mws,
+		app:   g.app,
+		mux:   g.mux,
+		path:  g.prefix + path,
+		scope: g.scope.child(middlewares...),
+		opts:  g.opts,
 	}
 }
 
 // GET registers a GET handler.
 func (g *Group) GET(path string, h Handler, middlewares ...Middleware) *Group {
-	fullPath := g.prefix + path
-	mws := append(g.middlewares, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	g.app.mux.Get(fullPath, g.app.wrapHandler(handler))
-	return g
+	return g.add([]string{http.MethodGet}, path, h, middlewares)
 }
 
 // POST registers a POST handler.
 func (g *Group) POST(path string, h Handler, middlewares ...Middleware) *Group {
-	fullPath := g.prefix + path
-	mws := append(g.middlewares, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	g.app.mux.Post(fullPath, g.app.wrapHandler(handler))
-	return g
+	return g.add([]string{http.MethodPost}, path, h, middlewares)
 }
 
 // PUT registers a PUT handler.
 func (g *Group) PUT(path string, h Handler, middlewares ...Middleware) *Group {
-	fullPath := g.prefix + path
-	mws := append(g.middlewares, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	g.app.mux.Put(fullPath, g.app.wrapHandler(handler))
-	return g
+	return g.add([]string{http.MethodPut}, path, h, middlewares)
 }
 
 // PATCH registers a PATCH handler.
 func (g *Group) PATCH(path string, h Handler, middlewares ...Middleware) *Group {
-	fullPath := g.prefix + path
-	mws := append(g.middlewares, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	g.app.mux.Patch(fullPath, g.app.wrapHandler(handler))
-	return g
+	return g.add([]string{http.MethodPatch}, path, h, middlewares)
 }
 
 // DELETE registers a DELETE handler.
 func (g *Group) DELETE(path string, h Handler, middlewares ...Middleware) *Group {
-	fullPath := g.prefix + path
-	mws := append(g.middlewares, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	g.app.mux.Delete(fullPath, g.app.wrapHandler(handler))
+	return g.add([]string{http.MethodDelete}, path, h, middlewares)
+}
+
+// ANY registers a handler for every HTTP method.
+func (g *Group) ANY(path string, h Handler, middlewares ...Middleware) *Group {
+	return g.add([]string{""}, path, h, middlewares)
+}
+
+// Match registers a handler for each of the given HTTP methods.
+func (g *Group) Match(methods []string, path string, h Handler, middlewares ...Middleware) *Group {
+	return g.add(methods, path, h, middlewares)
+}
+
+// Handle registers a handler for an arbitrary HTTP method, including
+// non-standard ones such as REPORT, PROPFIND or PURGE, which are registered
+// with RegisterMethod on first use.
+func (g *Group) Handle(method, path string, h Handler, middlewares ...Middleware) *Group {
+	RegisterMethod(method)
+	return g.add([]string{strings.ToUpper(method)}, path, h, middlewares)
+}
+
+// HandleHTTP registers a net/http handler for an arbitrary HTTP method, with
+// the group middlewares applied around it.
+func (g *Group) HandleHTTP(method, path string, h http.Handler, middlewares ...Middleware) *Group {
+	return g.Handle(method, path, WrapHTTP(h), middlewares...)
+}
+
+// add registers h for methods below the group prefix.
+func (g *Group) add(methods []string, path string, h Handler, middlewares []Middleware) *Group {
+	g.sealed = true
+	g.last = g.app.register(g.mux, methods, g.prefix+path, h, g.scope.child(middlewares...), g.opts)
 	return g
 }
 
 // RouteBuilder for method chaining.
 type RouteBuilder struct {
-	app         *App
-	path        string
-	middlewares []Middleware
+	app    *App
+	mux    RouterBackend
+	path   string
+	scope  *scope
+	opts   routeOptions
+	sealed bool                // set once handlers or sub-routes were derived from the builder
+	last   []*conditionalRoute // routes of the last registration, see When
 }
 
-// With adds middlewares to this route.
+// With adds middlewares to this route. They apply to all handlers and
+// sub-routes of the builder, including those registered earlier. It panics
+// once the App is built.
 func (rb *RouteBuilder) With(middlewares ...Middleware) *RouteBuilder {
-	rb.middlewares = append(rb.middlewares, middlewares...)
+	rb.app.mutate("With")
+	rb.scope.middlewares = append(rb.scope.middlewares, middlewares...)
+	return rb
+}
+
+// BodyLimit overrides the BodyLimit for handlers of this route (0 =
+// unlimited). It must be called before handlers are registered.
+func (rb *RouteBuilder) BodyLimit(n int64) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: BodyLimit must be set before handlers on a route")
+	}
+	rb.opts.bodyLimit = n
+	return rb
+}
+
+// StrictJSON overrides the StrictJSON setting for handlers of this route.
+// It must be called before handlers are registered.
+func (rb *RouteBuilder) StrictJSON(strict bool) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: StrictJSON must be set before handlers on a route")
+	}
+	rb.opts.strictJSON = strict
+	return rb
+}
+
+// ValidateJSON overrides the ValidateJSON setting for handlers of this
+// route. It must be called before handlers are registered.
+func (rb *RouteBuilder) ValidateJSON(validate bool) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: ValidateJSON must be set before handlers on a route")
+	}
+	rb.opts.validateJSON = validate
+	return rb
+}
+
+// Timeout sets a deadline for the handlers of this route. The request
+// context is canceled once d elapses and the client gets a 504 through the
+// App's error handler. The response is buffered until the handler returns,
+// so writes made after the deadline are discarded (they fail with
+// http.ErrHandlerTimeout) and streaming responses are not supported. It
+// must be called before handlers are registered.
+//
+//	app.Group("/reports").Route("/export").Timeout(5 * time.Second).GET(export)
+func (rb *RouteBuilder) Timeout(d time.Duration) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: Timeout must be set before handlers on a route")
+	}
+	rb.opts.timeout = d
+	return rb
+}
+
+// Meta attaches a metadata key/value to the handlers of this route, readable
+// with Ctx.Meta. It must be called before handlers are registered.
+func (rb *RouteBuilder) Meta(key string, value interface{}) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: Meta must be set before handlers on a route")
+	}
+	rb.opts = rb.opts.withMeta(key, value)
+	return rb
+}
+
+// Attr declares a static observability attribute for the handlers of this
+// route, see Group.Attr. It must be called before handlers are registered.
+func (rb *RouteBuilder) Attr(key, value string) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: Attr must be set before handlers on a route")
+	}
+	rb.opts = rb.opts.withAttr(key, value)
+	return rb
+}
+
+// Tag adds tags to the handlers of this route, readable with Ctx.Tags and
+// Ctx.HasTag. It must be called before handlers are registered.
+func (rb *RouteBuilder) Tag(tags ...string) *RouteBuilder {
+	if rb.sealed {
+		panic("owl: Tag must be set before handlers on a route")
+	}
+	rb.opts = rb.opts.withTags(tags...)
 	return rb
 }
 
 // GET registers a GET handler.
 func (rb *RouteBuilder) GET(h Handler, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(rb.middlewares))
-	copy(mws, rb.middlewares)
-	mws = append(mws, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	rb.app.mux.Get(rb.path, rb.app.wrapHandler(handler))
-	return rb
+	return rb.add([]string{http.MethodGet}, h, middlewares)
 }
 
 // POST registers a POST handler.
 func (rb *RouteBuilder) POST(h Handler, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(rb.middlewares))
-	copy(mws, rb.middlewares)
-	mws = append(mws, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	rb.app.mux.Post(rb.path, rb.app.wrapHandler(handler))
-	return rb
+	return rb.add([]string{http.MethodPost}, h, middlewares)
 }
 
 // PUT registers a PUT handler.
 func (rb *RouteBuilder) PUT(h Handler, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(rb.middlewares))
-	copy(mws, rb.middlewares)
-	mws = append(mws, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	rb.app.mux.Put(rb.path, rb.app.wrapHandler(handler))
-	return rb
+	return rb.add([]string{http.MethodPut}, h, middlewares)
 }
 
 // PATCH registers a PATCH handler.
 func (rb *RouteBuilder) PATCH(h Handler, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(rb.middlewares))
-	copy(mws, rb.middlewares)
-	mws = append(mws, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	rb.app.mux.Patch(rb.path, rb.app.wrapHandler(handler))
-	return rb
+	return rb.add([]string{http.MethodPatch}, h, middlewares)
 }
 
 // DELETE registers a DELETE handler.
 func (rb *RouteBuilder) DELETE(h Handler, middlewares ...Middleware) *RouteBuilder {
-	// Copy slice to avoid sharing underlying array
-	mws := make([]Middleware, len(rb.middlewares))
-	copy(mws, rb.middlewares)
-	mws = append(mws, middlewares...)
-	handler := chainMiddlewares(h, mws...)
-	rb.app.mux.Delete(rb.path, rb.app.wrapHandler(handler))
-	return rb
+	return rb.add([]string{http.MethodDelete}, h, middlewares)
+}
+
+// ANY registers a handler for every HTTP method.
+func (rb *RouteBuilder) ANY(h Handler, middlewares ...Middleware) *RouteBuilder {
+	return rb.add([]string{""}, h, middlewares)
+}
+
+// Match registers a handler for each of the given HTTP methods.
+func (rb *RouteBuilder) Match(methods []string, h Handler, middlewares ...Middleware) *RouteBuilder {
+	return rb.add(methods, h, middlewares)
 }
 
 // Group creates a sub-route.
 func (rb *RouteBuilder) Group(subPath string, middlewares ...Middleware) *RouteBuilder {
+	rb.sealed = true
 	return &RouteBuilder{
-		app:         rb.app,
-		path:        rb.path + subPath,
-		middlewares: append(rb.middlewares, middlewares...),
+		app:   rb.app,
+		mux:   rb.mux,
+		path:  rb.path + subPath,
+		scope: rb.scope.child(middlewares...),
+		opts:  rb.opts,
 	}
 }
+
+// add registers h for methods on the builder path.
+func (rb *RouteBuilder) add(methods []string, h Handler, middlewares []Middleware) *RouteBuilder {
+	rb.sealed = true
+	rb.last = rb.app.register(rb.mux, methods, rb.path, h, rb.scope.child(middlewares...), rb.opts)
+	return rb
+}
+
+// snapshot returns a new slice holding base followed by extra, so that the
+// result never shares a backing array with base.
+func snapshot(base []Middleware, extra ...Middleware) []Middleware {
+	mws := make([]Middleware, 0, len(base)+len(extra))
+	mws = append(mws, base...)
+	return append(mws, extra...)
+}
@@ -0,0 +1,37 @@
+package owl
+
+import "net/http"
+
+// RouterBackend is the routing engine behind an App. Mux, the chi-derived
+// radix tree, is the default; AppConfig.Router swaps in another engine, such
+// as an adapter for httprouter or a custom radix tree, while Ctx, Handler,
+// Middleware and Group work as before.
+//
+// Patterns are given in Owl syntax: {name} and {name:regexp} params and a
+// trailing "/*" catch-all, whose value is the "*" param. Backends receive
+// requests whose context carries a fresh routing Context, see
+// RouteContext; they must record the params of the matched route with
+// rctx.URLParams.Add, and should record the pattern with
+// rctx.RoutePatterns, so that Ctx.Param and pattern-based middlewares keep
+// working.
+type RouterBackend interface {
+	http.Handler
+
+	// Handle registers h for every HTTP method on pattern.
+	Handle(pattern string, h http.Handler)
+
+	// Method registers h for the method on pattern.
+	Method(method, pattern string, h http.Handler)
+
+	// Use appends net/http middlewares run for every request, before
+	// routing.
+	Use(middlewares ...func(http.Handler) http.Handler)
+
+	// Middlewares returns the middlewares added with Use.
+	Middlewares() Middlewares
+
+	// NotFoundHandler returns the handler for unmatched requests.
+	NotFoundHandler() http.HandlerFunc
+}
+
+var _ RouterBackend = (*Mux)(nil)
@@ -0,0 +1,111 @@
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// listBackend is a naive RouterBackend trying routes in registration order.
+type listBackend struct {
+	routes      []listRoute
+	middlewares Middlewares
+}
+
+type listRoute struct {
+	method, pattern string
+	handler         http.Handler
+}
+
+func (b *listBackend) Handle(pattern string, h http.Handler) { b.Method("", pattern, h) }
+
+func (b *listBackend) Method(method, pattern string, h http.Handler) {
+	b.routes = append(b.routes, listRoute{method, pattern, h})
+}
+
+func (b *listBackend) Use(middlewares ...func(http.Handler) http.Handler) {
+	b.middlewares = append(b.middlewares, middlewares...)
+}
+
+func (b *listBackend) Middlewares() Middlewares { return b.middlewares }
+
+func (b *listBackend) NotFoundHandler() http.HandlerFunc { return http.NotFound }
+
+func (b *listBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.middlewares.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := RouteContext(r.Context())
+		for _, route := range b.routes {
+			if (route.method == "" || route.method == r.Method) && b.match(rctx, route.pattern, r.URL.Path) {
+				rctx.RoutePatterns = append(rctx.RoutePatterns, route.pattern)
+				route.handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}).ServeHTTP(w, r)
+}
+
+func (b *listBackend) match(rctx *Context, pattern, path string) bool {
+	ps, segs := strings.Split(pattern, "/"), strings.Split(path, "/")
+	var keys, values []string
+	for i, p := range ps {
+		switch {
+		case p == "*":
+			keys, values = append(keys, "*"), append(values, strings.Join(segs[i:], "/"))
+			segs = segs[:i+1]
+		case i >= len(segs):
+			return false
+		case strings.HasPrefix(p, "{"):
+			keys, values = append(keys, strings.Trim(p, "{}")), append(values, segs[i])
+		case p != segs[i]:
+			return false
+		}
+	}
+	if len(ps) != len(segs) {
+		return false
+	}
+	for i := range keys {
+		rctx.URLParams.Add(keys[i], values[i])
+	}
+	return true
+}
+
+func TestCustomRouterBackend(t *testing.T) {
+	backend := &listBackend{}
+	app := New(AppConfig{Router: backend})
+	app.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Backend", "list")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.GET("/users/{id}", func(c *Ctx) error { return c.Text("user " + c.Param("id")) })
+	app.Group("/files").GET("/*name", func(c *Ctx) error { return c.Text(c.Param("name")) })
+
+	admin := New()
+	admin.GET("/stats", func(c *Ctx) error { return c.Text("stats") })
+	app.Mount("/admin", admin)
+
+	if app.Mux() != nil || app.Router() != backend {
+		t.Fatal("expected the custom backend to be used")
+	}
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/users/7", 200, "user 7"},
+		{"/files/css/site.css", 200, "css/site.css"},
+		{"/admin/stats", 200, "stats"},
+		{"/missing", 404, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) || w.Header().Get("X-Backend") != "list" {
+			t.Errorf("%s: got %d %q %v", tt.path, w.Code, w.Body.String(), w.Header())
+		}
+	}
+}
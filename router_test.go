@@ -0,0 +1,377 @@
+package owl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGroupAnyAndMatch(t *testing.T) {
+	app := New()
+	api := app.Group("/api")
+	api.ANY("/webhook", func(c *Ctx) error { return c.Text("hook " + c.Request.Method) })
+	api.Match([]string{"GET", "POST"}, "/legacy", func(c *Ctx) error { return c.Text("legacy") })
+	api.Route("/items").Match([]string{"put", "patch"}, func(c *Ctx) error { return c.Text("item") })
+	api.Route("/all").ANY(func(c *Ctx) error { return c.Text("all") })
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{"DELETE", "/api/webhook", 200, "hook DELETE"},
+		{"POST", "/api/webhook", 200, "hook POST"},
+		{"GET", "/api/legacy", 200, "legacy"},
+		{"POST", "/api/legacy", 200, "legacy"},
+		{"DELETE", "/api/legacy", 405, ""},
+		{"PATCH", "/api/items", 200, "item"},
+		{"GET", "/api/items", 405, ""},
+		{"OPTIONS", "/api/all", 200, "all"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s %s: got %d %q", tt.method, tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGroupHandle(t *testing.T) {
+	app := New()
+	dav := app.Group("/dav", func(next Handler) Handler {
+		return func(c *Ctx) error {
+			c.SetHeader("X-Group", "dav")
+			return next(c)
+		}
+	})
+	dav.Handle("propfind", "/files", func(c *Ctx) error { return c.Status(207).Text("multistatus") })
+	dav.HandleHTTP("PURGE", "/cache", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("purged"))
+	}))
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{"PROPFIND", "/dav/files", 207, "multistatus"},
+		{"PURGE", "/dav/cache", 200, "purged"},
+		{"GET", "/dav/cache", 405, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s %s: got %d %q", tt.method, tt.path, w.Code, w.Body.String())
+		}
+		if tt.code != 405 && w.Header().Get("X-Group") != "dav" {
+			t.Errorf("%s %s: group middleware not applied", tt.method, tt.path)
+		}
+	}
+}
+
+func TestGroupMiddlewareSnapshot(t *testing.T) {
+	tag := func(v string) Middleware {
+		return func(next Handler) Handler {
+			return func(c *Ctx) error {
+				c.Response.Header().Add("X-Trace", v)
+				return next(c)
+			}
+		}
+	}
+	ok := func(c *Ctx) error { return c.Text("ok") }
+
+	app := New()
+	app.Use(tag("app"))
+	app.GET("/root", ok, tag("root"))
+
+	// A group whose middleware slice has spare capacity used to let one
+	// route's middlewares leak into the next.
+	api := app.Group("/api", tag("api"))
+	api.GET("/a", ok, tag("a"))
+	api.GET("/b", ok)
+	api.Route("/c", tag("c")).GET(ok, tag("get")).POST(ok)
+
+	tests := []struct {
+		method, path string
+		trace        string
+	}{
+		{"GET", "/root", "app,root"},
+		{"GET", "/api/a", "app,api,a"},
+		{"GET", "/api/b", "app,api"},
+		{"GET", "/api/c", "app,api,c,get"},
+		{"POST", "/api/c", "app,api,c"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if got := strings.Join(w.Header().Values("X-Trace"), ","); got != tt.trace {
+			t.Errorf("%s %s: got trace %q, want %q", tt.method, tt.path, got, tt.trace)
+		}
+	}
+}
+
+func TestLateMiddleware(t *testing.T) {
+	tag := func(v string) Middleware {
+		return func(next Handler) Handler {
+			return func(c *Ctx) error {
+				c.Response.Header().Add("X-Trace", v)
+				return next(c)
+			}
+		}
+	}
+	ok := func(c *Ctx) error { return c.Text("ok") }
+
+	app := New()
+	app.GET("/root", ok)
+	api := app.Group("/api", tag("api"))
+	api.GET("/a", ok)
+	v1 := api.Group("/v1")
+	v1.GET("/b", ok)
+	users := v1.Route("/users")
+	users.GET(ok)
+
+	// Middlewares added after routes still apply to them, in scope order.
+	users.With(tag("users"))
+	api.Use(tag("late"))
+	app.Use(tag("app"))
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, trace string
+	}{
+		{"/root", "app"},
+		{"/api/a", "app,api,late"},
+		{"/api/v1/b", "app,api,late"},
+		{"/api/v1/users", "app,api,late,users"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if got := strings.Join(w.Header().Values("X-Trace"), ","); got != tt.trace {
+			t.Errorf("GET %s: got trace %q, want %q", tt.path, got, tt.trace)
+		}
+	}
+
+	for name, fn := range map[string]func(){
+		"app":   func() { app.Use(tag("x")) },
+		"group": func() { api.Use(tag("x")) },
+		"route": func() { users.With(tag("x")) },
+		"GET":   func() { app.GET("/new", ok) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic after Build", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestFrozenApp(t *testing.T) {
+	ok := func(c *Ctx) error { return c.Text("ok") }
+	app := New()
+	app.GET("/", ok)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, fn := range map[string]func(){
+		"Route registration": func() { app.POST("/late", ok) },
+		"Host":               func() { app.Host("api.example.com") },
+		"Mount":              func() { app.Mount("/sub", New()) },
+	} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrFrozen) || !strings.Contains(err.Error(), name+" at ") || !strings.Contains(err.Error(), "router_test.go:") {
+					t.Errorf("%s: expected an ErrFrozen panic naming the call site, got %v", name, err)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "ok" {
+		t.Fatalf("expected the frozen App to keep serving, got %q", w.Body.String())
+	}
+}
+
+func TestLazyBuild(t *testing.T) {
+	var calls int
+	count := func(next Handler) Handler {
+		return func(c *Ctx) error {
+			calls++
+			return next(c)
+		}
+	}
+
+	// Without Build, chains are assembled on the next request and follow
+	// later changes.
+	app := New()
+	g := app.Group("/api")
+	g.GET("/a", func(c *Ctx) error { return c.Text("a") })
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/a", nil))
+	g.Use(count)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/a", nil))
+	if calls != 1 || w.Body.String() != "a" {
+		t.Fatalf("got %d calls, body %q", calls, w.Body.String())
+	}
+}
+
+func TestBuildValidation(t *testing.T) {
+	ok := func(c *Ctx) error { return nil }
+
+	app := New(AppConfig{StrictRoutes: true})
+	app.GET("/users/{id}", ok)
+	app.GET("/users/{name}", ok)
+	app.GET("/nil", nil)
+	app.Group("/admin", nil).GET("/", ok)
+	app.SetErrorHandler(nil)
+
+	err := app.Build()
+	if err == nil {
+		t.Fatal("expected Build to fail")
+	}
+	for _, want := range []string{
+		"error handler is nil",
+		"nil handler for GET /nil",
+		"nil middleware in the chain of GET /admin/",
+		"/users/{id}",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	var conflicts *RouteConflictError
+	if !errors.As(err, &conflicts) {
+		t.Error("expected a RouteConflictError in the chain")
+	}
+
+	// A failed Build leaves the App open for fixes.
+	app.Use(Middleware(func(next Handler) Handler { return next }))
+
+	sub := New()
+	sub.GET("/x", nil)
+	parent := New()
+	parent.Mount("/sub", sub)
+	if err := parent.Build(); err == nil || !strings.Contains(err.Error(), "GET /x") {
+		t.Fatalf("expected the mounted App to be validated, got %v", err)
+	}
+}
+
+func TestRouteMetaAndTags(t *testing.T) {
+	app := New()
+	auth := func(next Handler) Handler {
+		return func(c *Ctx) error {
+			if c.Meta("auth") == "required" && c.Header("Authorization") == "" {
+				return NewHTTPError(http.StatusUnauthorized, "login required")
+			}
+			return next(c)
+		}
+	}
+	describe := func(c *Ctx) error {
+		return c.Text(strings.Join(c.Tags(), ","))
+	}
+
+	api := app.Group("/api", auth).Tag("api")
+	api.GET("/open", describe)
+	admin := api.Group("/admin").Meta("auth", "required").Tag("admin")
+	admin.GET("/stats", describe)
+	api.Route("/health").Tag("public").GET(func(c *Ctx) error {
+		if !c.HasTag("public") || c.HasTag("admin") {
+			return NewHTTPError(http.StatusInternalServerError, "bad tags")
+		}
+		return describe(c)
+	})
+
+	tests := []struct {
+		path, auth string
+		code       int
+		body       string
+	}{
+		{"/api/open", "", 200, "api"},
+		{"/api/admin/stats", "", 401, ""},
+		{"/api/admin/stats", "Bearer t", 200, "api,admin"},
+		{"/api/health", "", 200, "api,public"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", tt.path, nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		app.ServeHTTP(w, r)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q", tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWrapHTTP(t *testing.T) {
+	app := New()
+	legacy := app.Group("/legacy", func(next Handler) Handler {
+		return func(c *Ctx) error {
+			c.SetHeader("X-Group", "legacy")
+			return next(c)
+		}
+	})
+	legacy.GET("/func", WrapHTTPFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("func"))
+	}))
+	legacy.POST("/handler", WrapHTTP(http.NotFoundHandler()))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/legacy/func", nil))
+	if w.Body.String() != "func" || w.Header().Get("X-Group") != "legacy" {
+		t.Fatalf("unexpected response %q %v", w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/legacy/handler", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("X-Group") != "legacy" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+}
+
+func TestRouteAttrs(t *testing.T) {
+	var seen []Attr
+	app := New()
+	app.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			seen = RouteContext(r.Context()).RouteAttrs()
+		})
+	})
+	billing := app.Group("/billing").Attr("team", "payments").Attr("criticality", "low")
+	billing.Route("/charge").Attr("criticality", "high").POST(func(c *Ctx) error {
+		if len(c.Attrs()) != 2 {
+			return NewHTTPError(http.StatusInternalServerError, "missing attrs")
+		}
+		return nil
+	})
+	billing.GET("/status", func(c *Ctx) error { return nil })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/billing/charge", nil))
+	want := []Attr{{"team", "payments"}, {"criticality", "high"}}
+	if w.Code != http.StatusOK || len(seen) != 2 || seen[0] != want[0] || seen[1] != want[1] {
+		t.Fatalf("unexpected attributes %v (status %d)", seen, w.Code)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/billing/status", nil))
+	if len(seen) != 2 || seen[1] != (Attr{"criticality", "low"}) {
+		t.Fatalf("route attribute leaked into group: %v", seen)
+	}
+}
@@ -0,0 +1,243 @@
+package owl
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema is a JSON Schema of the 2020-12 dialect used by OpenAPI 3.1,
+// limited to the keywords Owl generates from Go types, see SchemaOf.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// SchemaOf returns the JSON Schema of the values of v's type encoded as
+// JSON, as generated for App.OpenAPI, with the schemas of named struct
+// types under $defs:
+//
+//	b, _ := json.MarshalIndent(owl.SchemaOf(CreateUserRequest{}), "", "  ")
+func SchemaOf(v interface{}) *Schema {
+	gen := newSchemaGen("#/$defs/")
+	s := gen.schema(reflect.TypeOf(v))
+	s.Dialect = "https://json-schema.org/draft/2020-12/schema"
+	if len(gen.defs) > 0 {
+		s.Defs = gen.defs
+	}
+	return s
+}
+
+// schemas caches the schemas bodies are validated against, by type.
+var schemas sync.Map
+
+// bodySchema returns the cached schema of JSON bodies bound into a value of
+// type t, which must not be modified. Fields of structs tagged query or
+// param, bound by H from elsewhere, are left out like in App.OpenAPI.
+func bodySchema(t reflect.Type) *Schema {
+	if s, ok := schemas.Load(t); ok {
+		return s.(*Schema)
+	}
+	gen := newSchemaGen("#/$defs/")
+	var s *Schema
+	if t.Kind() == reflect.Struct && (hasTaggedField(t, "query") || hasTaggedField(t, "param")) {
+		s = gen.structSchema(t, inBody)
+	} else {
+		s = gen.schema(t)
+	}
+	if len(gen.defs) > 0 {
+		s.Defs = gen.defs
+	}
+	v, _ := schemas.LoadOrStore(t, s)
+	return v.(*Schema)
+}
+
+// schemaGen reflects Go types into Schemas. Named struct types are
+// generated once into defs and referenced, so that recursive types work.
+type schemaGen struct {
+	refPrefix string             // e.g. "#/components/schemas/"
+	defs      map[string]*Schema // Named struct schemas, by name
+	names     map[reflect.Type]string
+}
+
+func newSchemaGen(refPrefix string) *schemaGen {
+	return &schemaGen{refPrefix: refPrefix, defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	fileHeaderType    = reflect.TypeOf((*multipart.FileHeader)(nil))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schema returns the schema of values of t encoded as JSON.
+func (g *schemaGen) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr && t != fileHeaderType {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{} // Encoded in its own way
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := &Schema{Type: "integer"}
+		switch t.Kind() {
+		case reflect.Int32:
+			s.Format = "int32"
+		case reflect.Int64:
+			s.Format = "int64"
+		}
+		return s
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, nil)
+		}
+		return g.ref(t)
+	}
+	return &Schema{} // Interfaces and anything JSON can't encode
+}
+
+// ref returns a reference to the schema of the named struct type t,
+// generating it on first use.
+func (g *schemaGen) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		g.names[t] = name
+		g.defs[name] = nil // Reserved while generating, for recursive types
+		g.defs[name] = g.structSchema(t, nil)
+	}
+	return &Schema{Ref: g.refPrefix + name}
+}
+
+// defName names the schema of t after the type, qualified with its package
+// when the name is taken by another type.
+func (g *schemaGen) defName(t reflect.Type) string {
+	name := sanitizeSchemaName(t.Name())
+	if _, taken := g.defs[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	base := sanitizeSchemaName(pkg + "." + t.Name())
+	name = base
+	for i := 2; ; i++ {
+		if _, taken := g.defs[name]; !taken {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// sanitizeSchemaName keeps the characters allowed in component names, so
+// that instances of generic types such as Page[main.User] get a valid one.
+func sanitizeSchemaName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// structSchema returns the object schema of struct type t, with the fields
+// accepted by keep, or all of them when keep is nil. Fields are named
+// after their json tag; the ones without omitempty that aren't pointers
+// are required. The doc and deprecated tags describe fields:
+//
+//	Email string `json:"email" doc:"Where receipts are sent"`
+func (g *schemaGen) structSchema(t reflect.Type, keep func(reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t, keep)
+	return s
+}
+
+func (g *schemaGen) addFields(s *Schema, t reflect.Type, keep func(reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft, keep) // Promoted fields, as encoding/json does
+				continue
+			}
+		}
+		if !f.IsExported() || (keep != nil && !keep(f)) {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schema(f.Type)
+		fs.Description = f.Tag.Get("doc") // Next to $ref too, as 2020-12 allows
+		fs.Deprecated = hasTag(f, "deprecated")
+		s.Properties[name] = fs
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasTag(f reflect.StructField, key string) bool {
+	_, ok := f.Tag.Lookup(key)
+	return ok
+}
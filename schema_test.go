@@ -0,0 +1,123 @@
+package owl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type orderLine struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type createOrderRequest struct {
+	Customer string            `json:"customer"`
+	Lines    []orderLine       `json:"lines"`
+	Notes    *string           `json:"notes"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(createOrderRequest{})
+	if s.Dialect != "https://json-schema.org/draft/2020-12/schema" || s.Ref != "#/$defs/createOrderRequest" {
+		t.Fatalf("unexpected root schema %+v", s)
+	}
+	order := s.Defs["createOrderRequest"]
+	if order == nil || order.Properties["lines"].Items.Ref != "#/$defs/orderLine" || s.Defs["orderLine"] == nil {
+		t.Fatalf("expected the schemas of both structs under $defs, got %+v", s.Defs)
+	}
+	if got := strings.Join(order.Required, ","); got != "customer,lines" {
+		t.Errorf("unexpected required fields %s", got)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+	if s := SchemaOf([]int{}); s.Type != "array" || s.Items.Type != "integer" || s.Defs != nil {
+		t.Errorf("unexpected schema %+v", s)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := SchemaOf(createOrderRequest{})
+	if err := s.Validate([]byte(`{"customer":"ada","lines":[{"sku":"a","quantity":1}],"notes":null}`)); err != nil {
+		t.Fatalf("expected a valid document, got %v", err)
+	}
+
+	err := s.Validate([]byte(`{"lines":[{"sku":"a","quantity":1.5},{"sku":2,"quantity":1}],"labels":{"x":true}}`))
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected a *SchemaError, got %v", err)
+	}
+	want := []string{
+		"$.customer: is required",
+		"$.labels.x: expected a string, got a boolean",
+		"$.lines[0].quantity: expected an integer, got the number 1.5",
+		"$.lines[1].sku: expected a string, got the number 2",
+	}
+	if got := strings.Split(err.Error(), "; "); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected violations:\n%s", strings.Join(got, "\n"))
+	}
+	if err := s.Validate([]byte(`{"customer":`)); err == nil || errors.As(err, &schemaErr) {
+		t.Fatalf("expected a syntax error, got %v", err)
+	}
+
+	code := &Schema{Type: "string", Pattern: "^[A-Z]{2}$"}
+	if err := code.Validate([]byte(`"FR"`)); err != nil {
+		t.Fatalf("expected a match, got %v", err)
+	}
+	if err := code.Validate([]byte(`"fr"`)); err == nil || err.Error() != "$: doesn't match ^[A-Z]{2}$" {
+		t.Fatalf("expected a mismatch, got %v", err)
+	}
+	invalid := &Schema{Type: "string", Pattern: "([A-Z"}
+	if err := invalid.Validate([]byte(`"anything"`)); err == nil {
+		t.Fatal("expected an invalid pattern to reject values")
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	app := New(AppConfig{ValidateJSON: true})
+	bind := func(c *Ctx) error {
+		var req createOrderRequest
+		if err := c.Bind().JSON(&req); err != nil {
+			return err
+		}
+		return c.Text(req.Customer)
+	}
+	app.POST("/orders", bind)
+	app.Group("").Route("/drafts").ValidateJSON(false).POST(bind)
+	app.POST("/shops/{shop}/items", H(func(c *Ctx, req createItemRequest) (item, error) {
+		return item{Shop: req.Shop, Name: req.Name}, nil
+	}))
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("/orders", `{"customer":"ada","lines":[]}`); w.Code != 200 || w.Body.String() != "ada" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	w := serve("/orders", `{"customer":"ada","lines":[{"sku":"a","quantity":"1"}]}`)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "$.lines[0].quantity: expected an integer") {
+		t.Fatalf("expected the path of the mismatch, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("/orders", `{"customer":`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected syntax errors from the decoder, got %d", w.Code)
+	}
+	if w := serve("/drafts", `{"customer":"ada"}`); w.Code != 200 {
+		t.Fatalf("expected the route to override the App, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("/shops/owls/items", `{"name":"perch","price":12}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected path and query fields out of the schema, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("/shops/owls/items", `{"name":"perch"}`); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "$.price: is required") {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,195 @@
+package owl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaError lists the places where a JSON document doesn't match a
+// Schema, see Schema.Validate.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// SchemaViolation is a value of a JSON document not matching its schema.
+type SchemaViolation struct {
+	Path    string // Location of the value, e.g. "$.items[2].price"
+	Message string
+}
+
+// maxSchemaViolations bounds the violations reported for a document.
+const maxSchemaViolations = 20
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Path + ": " + v.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks the JSON document data against s and returns a
+// *SchemaError listing the violations, or a syntax error. A null value is
+// accepted for properties that aren't required, like encoding/json leaves
+// the field untouched. Formats aren't checked.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	var e SchemaError
+	s.validate(s, v, "$", &e)
+	if len(e.Violations) > 0 {
+		return &e
+	}
+	return nil
+}
+
+func (s *Schema) validate(root *Schema, v interface{}, path string, e *SchemaError) {
+	if len(e.Violations) >= maxSchemaViolations {
+		return
+	}
+	if s.Ref != "" {
+		ref := root.resolve(s.Ref)
+		if ref == nil {
+			e.add(path, "unknown schema "+s.Ref)
+			return
+		}
+		s = ref
+	}
+	if v == nil {
+		return // See Validate
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			e.add(path, "expected an object, got "+jsonType(v))
+			return
+		}
+		for _, name := range s.Required {
+			if obj[name] == nil {
+				e.add(path+"."+name, "is required")
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Report in a stable order
+		for _, key := range keys {
+			switch prop := s.property(key); {
+			case prop != nil:
+				prop.validate(root, obj[key], path+"."+key, e)
+			case s.AdditionalProperties != nil:
+				s.AdditionalProperties.validate(root, obj[key], path+"."+key, e)
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			e.add(path, "expected an array, got "+jsonType(v))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(root, item, path+"["+strconv.Itoa(i)+"]", e)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			e.add(path, "expected a string, got "+jsonType(v))
+			return
+		}
+		if s.Pattern != "" {
+			if re := compilePattern(s.Pattern); re == nil {
+				e.add(path, "has an invalid pattern "+s.Pattern+" in the schema")
+			} else if !re.MatchString(str) {
+				e.add(path, "doesn't match "+s.Pattern)
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			e.add(path, "expected an integer, got "+jsonType(v))
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			e.add(path, "expected a number, got "+jsonType(v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			e.add(path, "expected a boolean, got "+jsonType(v))
+		}
+	}
+}
+
+// property returns the schema of the property key, matched without regard
+// to case like encoding/json does, or nil.
+func (s *Schema) property(key string) *Schema {
+	if prop, ok := s.Properties[key]; ok {
+		return prop
+	}
+	for name, prop := range s.Properties {
+		if strings.EqualFold(name, key) {
+			return prop
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema ref points to in the $defs of s, or nil.
+func (s *Schema) resolve(ref string) *Schema {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil
+	}
+	return s.Defs[name]
+}
+
+func (e *SchemaError) add(path, message string) {
+	if len(e.Violations) < maxSchemaViolations {
+		e.Violations = append(e.Violations, SchemaViolation{Path: path, Message: message})
+	}
+}
+
+// jsonType names the type of a decoded JSON value in violations.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "the number " + v.String()
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// patterns caches the compiled patterns of schemas.
+var patterns sync.Map
+
+// compilePattern returns the compiled pattern, or nil when it is invalid,
+// so that values are never checked against a pattern that can't hold.
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, _ := regexp.Compile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
@@ -0,0 +1,303 @@
+package owl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider for unknown secrets.
+var ErrSecretNotFound = errors.New("owl: secret not found")
+
+// SecretProvider resolves secret material such as cookie keys or JWT
+// secrets by name, so that it can live in a secret manager rather than in
+// plain environment variables. It is set with AppConfig.Secrets and read
+// with App.Secret.
+//
+// EnvSecrets, FileSecrets and VaultSecrets are built in; other managers,
+// such as a cloud KMS, are plugged in with SecretFunc or DecryptSecrets.
+type SecretProvider interface {
+	// Secret returns the value of the secret name, or ErrSecretNotFound.
+	Secret(ctx context.Context, name string) ([]byte, error)
+}
+
+// SecretFunc adapts a function to the SecretProvider interface.
+type SecretFunc func(ctx context.Context, name string) ([]byte, error)
+
+// Secret implements SecretProvider.
+func (f SecretFunc) Secret(ctx context.Context, name string) ([]byte, error) {
+	return f(ctx, name)
+}
+
+// EnvSecrets reads secrets from environment variables named prefix followed
+// by the upper-cased secret name, with characters other than letters and
+// digits replaced by "_": with prefix "APP_", "cookie-key" is read from
+// APP_COOKIE_KEY. Values starting with "base64:" are decoded.
+func EnvSecrets(prefix string) SecretProvider {
+	return SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		v, ok := os.LookupEnv(prefix + envName(name))
+		if !ok {
+			return nil, ErrSecretNotFound
+		}
+		return decodeSecret(v)
+	})
+}
+
+// FileSecrets reads secrets from files named after the secret in dir, as
+// mounted by Kubernetes or Docker secrets. A trailing newline is removed
+// and values starting with "base64:" are decoded.
+func FileSecrets(dir string) SecretProvider {
+	return SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return nil, fmt.Errorf("owl: invalid secret name '%s'", name)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSecretNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return decodeSecret(strings.TrimRight(string(b), "\r\n"))
+	})
+}
+
+// VaultConfig configures VaultSecrets.
+type VaultConfig struct {
+	Addr  string // Vault address, e.g. "https://vault.internal:8200"
+	Token string // Vault token, sent as X-Vault-Token
+	Mount string // KV version 2 mount (default: "secret")
+	Path  string // Path of the secret below the mount, e.g. "myapp"
+
+	// Client sends the requests (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// VaultSecrets reads secrets from the keys of a HashiCorp Vault KV version 2
+// secret, through Vault's HTTP API.
+func VaultSecrets(cfg VaultConfig) SecretProvider {
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	url := strings.TrimSuffix(cfg.Addr, "/") + "/v1/" + strings.Trim(cfg.Mount, "/") + "/data/" + strings.Trim(cfg.Path, "/")
+
+	return SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Vault-Token", cfg.Token)
+		res, err := cfg.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusNotFound {
+			return nil, ErrSecretNotFound
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("owl: vault answered %s for '%s'", res.Status, cfg.Path)
+		}
+
+		var body struct {
+			Data struct {
+				Data map[string]string `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			return nil, fmt.Errorf("owl: invalid vault response: %w", err)
+		}
+		v, ok := body.Data.Data[name]
+		if !ok {
+			return nil, ErrSecretNotFound
+		}
+		return decodeSecret(v)
+	})
+}
+
+// DecryptSecrets decrypts the secrets of p with decrypt, for envelope
+// encryption with a KMS: p holds ciphertexts, for instance in environment
+// variables, and decrypt calls the KMS.
+func DecryptSecrets(p SecretProvider, decrypt func(ctx context.Context, ciphertext []byte) ([]byte, error)) SecretProvider {
+	return SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		ciphertext, err := p.Secret(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return decrypt(ctx, ciphertext)
+	})
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func decodeSecret(v string) ([]byte, error) {
+	if enc, ok := strings.CutPrefix(v, "base64:"); ok {
+		b, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, fmt.Errorf("owl: invalid base64 secret: %w", err)
+		}
+		return b, nil
+	}
+	return []byte(v), nil
+}
+
+// secretCache caches the secrets read by an App and notifies rotation
+// callbacks when RefreshSecrets finds a new value.
+type secretCache struct {
+	provider SecretProvider
+	interval time.Duration
+
+	mu        sync.Mutex
+	values    map[string][]byte
+	callbacks map[string][]func([]byte)
+	stop      chan struct{} // Closed to end the refresh loop, guarded by mu
+}
+
+// Secret returns the secret name from the App's SecretProvider. Values are
+// cached until RefreshSecrets finds a new one.
+func (a *App) Secret(ctx context.Context, name string) ([]byte, error) {
+	if a.secrets == nil {
+		return nil, errors.New("owl: no SecretProvider configured, see AppConfig.Secrets")
+	}
+	s := a.secrets
+	s.mu.Lock()
+	v, ok := s.values[name]
+	s.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := s.provider.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.values[name] = v
+	s.mu.Unlock()
+	return v, nil
+}
+
+// OnSecretRotate registers fn to be called with the new value of the
+// secret name whenever RefreshSecrets finds it changed, so that keys can be
+// swapped without a restart:
+//
+//	app.OnSecretRotate("cookie-key", func(key []byte) {
+//		cookies.SetKey(key)
+//	})
+func (a *App) OnSecretRotate(name string, fn func(value []byte)) {
+	if a.secrets == nil {
+		panic("owl: OnSecretRotate needs AppConfig.Secrets")
+	}
+	a.secrets.mu.Lock()
+	defer a.secrets.mu.Unlock()
+	a.secrets.callbacks[name] = append(a.secrets.callbacks[name], fn)
+}
+
+// RefreshSecrets reads the secrets used so far, or watched with
+// OnSecretRotate, again and calls the callbacks of those that changed.
+// Start calls it every AppConfig.SecretRefresh. Secrets that can't be
+// read keep their value; the errors are returned together.
+func (a *App) RefreshSecrets(ctx context.Context) error {
+	if a.secrets == nil {
+		return nil
+	}
+	s := a.secrets
+	s.mu.Lock()
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	for name := range s.callbacks {
+		if _, ok := s.values[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		v, err := s.provider.Secret(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("owl: refreshing secret '%s': %w", name, err))
+			continue
+		}
+		s.mu.Lock()
+		old, ok := s.values[name]
+		changed := !ok || !bytes.Equal(old, v)
+		s.values[name] = v
+		callbacks := s.callbacks[name]
+		s.mu.Unlock()
+		if changed {
+			for _, fn := range callbacks {
+				fn(v)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshSecrets calls RefreshSecrets every AppConfig.SecretRefresh until
+// the App shuts down.
+func (a *App) refreshSecrets() {
+	s := a.secrets
+	if s == nil || s.interval <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		t := time.NewTicker(s.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if err := a.RefreshSecrets(context.Background()); err != nil {
+					a.logf(LevelError, "refreshing secrets", "error", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// stopSecrets ends the refresh loop started by Start.
+func (a *App) stopSecrets() {
+	s := a.secrets
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
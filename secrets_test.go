@@ -0,0 +1,126 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvAndFileSecrets(t *testing.T) {
+	t.Setenv("APP_COOKIE_KEY", "base64:c2VjcmV0")
+	ctx := context.Background()
+
+	if v, err := EnvSecrets("APP_").Secret(ctx, "cookie-key"); err != nil || string(v) != "secret" {
+		t.Fatalf("env: got %q, %v", v, err)
+	}
+	if _, err := EnvSecrets("APP_").Secret(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("env: expected ErrSecretNotFound, got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jwt"), []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files := FileSecrets(dir)
+	if v, err := files.Secret(ctx, "jwt"); err != nil || string(v) != "s3cr3t" {
+		t.Fatalf("file: got %q, %v", v, err)
+	}
+	if _, err := files.Secret(ctx, "../jwt"); err == nil {
+		t.Fatal("file: expected an error for a path")
+	}
+	if _, err := files.Secret(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("file: expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestVaultSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/myapp" || r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"jwt":"from-vault"}}}`))
+	}))
+	defer srv.Close()
+
+	vault := VaultSecrets(VaultConfig{Addr: srv.URL, Token: "tok", Path: "myapp"})
+	if v, err := vault.Secret(context.Background(), "jwt"); err != nil || string(v) != "from-vault" {
+		t.Fatalf("got %q, %v", v, err)
+	}
+	if _, err := vault.Secret(context.Background(), "other"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestSecretRotation(t *testing.T) {
+	current := "v1"
+	reads := 0
+	app := New(AppConfig{Secrets: SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		reads++
+		return []byte(name + ":" + current), nil
+	})})
+	ctx := context.Background()
+
+	var rotated []string
+	app.OnSecretRotate("key", func(v []byte) { rotated = append(rotated, string(v)) })
+
+	for i := 0; i < 2; i++ {
+		if v, err := app.Secret(ctx, "key"); err != nil || string(v) != "key:v1" {
+			t.Fatalf("got %q, %v", v, err)
+		}
+	}
+	if reads != 1 {
+		t.Fatalf("expected the secret to be cached, got %d reads", reads)
+	}
+
+	if err := app.RefreshSecrets(ctx); err != nil || len(rotated) != 0 {
+		t.Fatalf("unchanged secret: rotated %v, %v", rotated, err)
+	}
+	current = "v2"
+	if err := app.RefreshSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 || rotated[0] != "key:v2" {
+		t.Fatalf("expected one rotation to key:v2, got %v", rotated)
+	}
+	if v, _ := app.Secret(ctx, "key"); string(v) != "key:v2" {
+		t.Fatalf("expected the new value to be cached, got %q", v)
+	}
+
+	if _, err := New().Secret(ctx, "key"); err == nil {
+		t.Fatal("expected an error without a SecretProvider")
+	}
+}
+
+func TestSecretRefreshLoop(t *testing.T) {
+	var reads atomic.Int32
+	app := New(AppConfig{SecretRefresh: time.Millisecond, Secrets: SecretFunc(func(ctx context.Context, name string) ([]byte, error) {
+		reads.Add(1)
+		return []byte("v"), nil
+	})})
+	app.OnSecretRotate("key", func([]byte) {})
+
+	// Shutdown may run on another goroutine than Start, e.g. on a signal.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); app.refreshSecrets() }()
+	go func() { defer wg.Done(); app.stopSecrets() }()
+	wg.Wait()
+	app.stopSecrets()
+
+	app.refreshSecrets()
+	for deadline := time.Now().Add(time.Second); reads.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	app.stopSecrets()
+	if reads.Load() == 0 {
+		t.Fatal("expected the secrets to be refreshed")
+	}
+}
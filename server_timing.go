@@ -0,0 +1,70 @@
+package owl
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTimings collects the metrics of a request for its Server-Timing
+// header, see middleware.ServerTiming. It is safe for concurrent use.
+type ServerTimings struct {
+	mu      sync.Mutex
+	metrics []serverTiming
+}
+
+type serverTiming struct {
+	name string
+	dur  time.Duration
+	desc string
+}
+
+// ServerTimingsFromContext returns the ServerTimings of a request set by
+// middleware.ServerTiming, or nil.
+func ServerTimingsFromContext(ctx context.Context) *ServerTimings {
+	t, _ := ctx.Value(ServerTimingCtxKey).(*ServerTimings)
+	return t
+}
+
+// Add records that name, such as "db" or "cache", took d, with an optional
+// human-readable description.
+func (t *ServerTimings) Add(name string, d time.Duration, desc ...string) {
+	m := serverTiming{name: name, dur: d}
+	if len(desc) > 0 {
+		m.desc = desc[0]
+	}
+	t.mu.Lock()
+	t.metrics = append(t.metrics, m)
+	t.mu.Unlock()
+}
+
+// String formats the metrics as the value of a Server-Timing header, with
+// durations in milliseconds.
+func (t *ServerTimings) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := make([]string, len(t.metrics))
+	for i, m := range t.metrics {
+		parts[i] = m.name + ";dur=" + strconv.FormatFloat(float64(m.dur.Microseconds())/1000, 'f', -1, 64)
+		if m.desc != "" {
+			parts[i] += ";desc=" + strconv.Quote(m.desc)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Timing records that name took d in the Server-Timing header of the
+// response, for browser devtools and APMs to break the request time down.
+// It does nothing unless middleware.ServerTiming is used, and metrics
+// recorded once the response headers are sent are dropped.
+//
+//	start := time.Now()
+//	users, err := db.ListUsers(ctx)
+//	c.Timing("db", time.Since(start), "List users")
+func (c *Ctx) Timing(name string, d time.Duration, desc ...string) {
+	if t := ServerTimingsFromContext(c.Request.Context()); t != nil {
+		t.Add(name, d, desc...)
+	}
+}
@@ -0,0 +1,132 @@
+package owl
+
+import "sync"
+
+// Session holds the values of a user session, loaded and saved by the
+// middleware.Session middleware and read with Ctx.Session:
+//
+//	s := c.Session()
+//	s.Set("cart", cartID)
+//	cartID, _ := s.Get("cart").(string)
+//
+// Values are saved before the response headers are sent, so they must be
+// set before writing the body. A Session is safe for concurrent use.
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	values    map[string]interface{}
+	modified  bool
+	destroyed bool
+	renewed   bool
+}
+
+// NewSession returns a Session with id and values, as loaded from a
+// session store. id is empty for new sessions.
+func NewSession(id string, values map[string]interface{}) *Session {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return &Session{id: id, values: values}
+}
+
+// ID returns the identifier of the session in its store, empty for a new
+// session that wasn't saved yet.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// Get returns the value stored under key, or nil.
+func (s *Session) Get(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// GetString returns the string stored under key, or "".
+func (s *Session) GetString(key string) string {
+	v, _ := s.Get(key).(string)
+	return v
+}
+
+// Set stores value under key.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete removes key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// Values returns a copy of the values of the session.
+func (s *Session) Values() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make(map[string]interface{}, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Destroy removes every value and deletes the session from its store,
+// e.g. on logout.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]interface{}{}
+	s.destroyed = true
+	s.modified = true
+}
+
+// RenewID saves the session under a new identifier and deletes the
+// former one. Call it when the privileges of the user change, e.g. on
+// login, to prevent session fixation.
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewed = true
+	s.modified = true
+}
+
+// Modified reports whether the session changed since it was loaded.
+func (s *Session) Modified() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modified
+}
+
+// Destroyed reports whether Destroy was called.
+func (s *Session) Destroyed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.destroyed
+}
+
+// Renewed reports whether RenewID was called.
+func (s *Session) Renewed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renewed
+}
+
+// Saved records that the session was saved under id, resetting Modified,
+// Destroyed and Renewed. It is called by the session middleware.
+func (s *Session) Saved(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id = id
+	s.modified = false
+	s.destroyed = false
+	s.renewed = false
+}
@@ -0,0 +1,223 @@
+package owl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SimRequest is a single request replayed by Simulate.
+type SimRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"` // Path with optional query string
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Scenario describes a Simulate run.
+type Scenario struct {
+	Requests    []SimRequest
+	Rate        float64 // Requests per second (default: 0, as fast as possible)
+	Repeat      int     // Passes over Requests (default: 1)
+	Concurrency int     // Parallel workers (default: 1)
+}
+
+// SimReport summarizes a Simulate run. A request counts as an error when
+// the handler answers with a 5xx status.
+type SimReport struct {
+	Total       int
+	Errors      int
+	StatusCodes map[int]int
+	Elapsed     time.Duration
+	Min         time.Duration
+	Mean        time.Duration
+	P50         time.Duration
+	P90         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+}
+
+// Simulate replays the scenario against the App in-process, without a
+// network listener, and reports latency percentiles. It is meant for
+// pre-deploy smoke checks:
+//
+//	reqs, _ := owl.ParseSimRequests(file)
+//	report, err := app.Simulate(ctx, owl.Scenario{Requests: reqs, Rate: 200})
+//	if report.P99 > 50*time.Millisecond { ... }
+//
+// Cancelling ctx stops the run early; the report covers the requests that
+// completed.
+func (a *App) Simulate(ctx context.Context, sc Scenario) (*SimReport, error) {
+	if len(sc.Requests) == 0 {
+		return nil, errors.New("owl: scenario has no requests")
+	}
+	if sc.Repeat <= 0 {
+		sc.Repeat = 1
+	}
+	if sc.Concurrency <= 0 {
+		sc.Concurrency = 1
+	}
+	for i, sr := range sc.Requests {
+		if _, err := newSimRequest(ctx, sr); err != nil {
+			return nil, fmt.Errorf("owl: invalid request %d (%s %s): %w", i+1, sr.Method, sr.Path, err)
+		}
+	}
+
+	jobs := make(chan SimRequest)
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		// Rates beyond a request per nanosecond are as fast as possible.
+		if interval := time.Duration(float64(time.Second) / sc.Rate); sc.Rate > 0 && interval > 0 {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			tick = t.C
+		}
+		for i := 0; i < sc.Repeat; i++ {
+			for _, sr := range sc.Requests {
+				if tick != nil {
+					select {
+					case <-tick:
+					case <-ctx.Done():
+						return
+					}
+				}
+				select {
+				case jobs <- sr:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies []time.Duration
+		report    = &SimReport{StatusCodes: map[int]int{}}
+	)
+	start := time.Now()
+	for i := 0; i < sc.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sr := range jobs {
+				r, _ := newSimRequest(ctx, sr)
+				w := httptest.NewRecorder()
+
+				t0 := time.Now()
+				a.ServeHTTP(w, r)
+				d := time.Since(t0)
+
+				mu.Lock()
+				latencies = append(latencies, d)
+				report.StatusCodes[w.Code]++
+				if w.Code >= 500 {
+					report.Errors++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+	report.Total = len(latencies)
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		var sum time.Duration
+		for _, d := range latencies {
+			sum += d
+		}
+		report.Min = latencies[0]
+		report.Max = latencies[len(latencies)-1]
+		report.Mean = sum / time.Duration(len(latencies))
+		report.P50 = percentile(latencies, 50)
+		report.P90 = percentile(latencies, 90)
+		report.P99 = percentile(latencies, 99)
+	}
+	return report, ctx.Err()
+}
+
+// newSimRequest builds the request replayed for sr, like
+// httptest.NewRequest but returning an error instead of panicking on an
+// invalid method or path.
+func newSimRequest(ctx context.Context, sr SimRequest) (*http.Request, error) {
+	if !strings.HasPrefix(sr.Path, "/") {
+		return nil, errors.New("path must start with /")
+	}
+	r, err := http.NewRequestWithContext(ctx, sr.Method, "http://example.com"+sr.Path, strings.NewReader(sr.Body))
+	if err != nil {
+		return nil, err
+	}
+	r.RequestURI = r.URL.RequestURI()
+	r.RemoteAddr = "192.0.2.1:1234"
+	for k, vs := range sr.Header {
+		r.Header[k] = vs
+	}
+	return r, nil
+}
+
+// percentile returns the p-th percentile of sorted durations using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i < 1 {
+		i = 1
+	}
+	return sorted[i-1]
+}
+
+// accessLogRequest matches the quoted request line of Common and Combined
+// Log Format entries, e.g. "GET /users?id=1 HTTP/1.1".
+var accessLogRequest = regexp.MustCompile(`"([A-Z]+) (\S+) HTTP/[0-9.]+"`)
+
+// ParseSimRequests reads requests for a Scenario, one per line. Each line is
+// either a JSON encoded SimRequest (a fixture), an access log entry in
+// Common/Combined Log Format, or a bare "METHOD /path". Blank lines and
+// lines starting with # are skipped.
+func ParseSimRequests(r io.Reader) ([]SimRequest, error) {
+	var reqs []SimRequest
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		var sr SimRequest
+		switch {
+		case line[0] == '{':
+			if err := json.Unmarshal(line, &sr); err != nil {
+				return nil, fmt.Errorf("owl: invalid fixture on line %d: %w", n, err)
+			}
+		case accessLogRequest.Match(line):
+			m := accessLogRequest.FindSubmatch(line)
+			sr.Method, sr.Path = string(m[1]), string(m[2])
+		default:
+			f := strings.Fields(string(line))
+			if len(f) != 2 || !strings.HasPrefix(f[1], "/") {
+				return nil, fmt.Errorf("owl: unrecognized request on line %d", n)
+			}
+			sr.Method, sr.Path = strings.ToUpper(f[0]), f[1]
+		}
+		if sr.Method == "" {
+			sr.Method = http.MethodGet
+		}
+		reqs = append(reqs, sr)
+	}
+	return reqs, sc.Err()
+}
@@ -0,0 +1,71 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSimRequests(t *testing.T) {
+	input := `# fixtures
+{"method":"POST","path":"/users","body":"{}"}
+127.0.0.1 - - [10/Oct/2026:13:55:36 +0000] "GET /users?page=2 HTTP/1.1" 200 2326
+delete /users/1
+`
+	reqs, err := ParseSimRequests(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SimRequest{
+		{Method: "POST", Path: "/users", Body: "{}"},
+		{Method: "GET", Path: "/users?page=2"},
+		{Method: "DELETE", Path: "/users/1"},
+	}
+	if len(reqs) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(reqs), len(want))
+	}
+	for i := range want {
+		if reqs[i].Method != want[i].Method || reqs[i].Path != want[i].Path || reqs[i].Body != want[i].Body {
+			t.Errorf("request %d: got %+v, want %+v", i, reqs[i], want[i])
+		}
+	}
+
+	if _, err := ParseSimRequests(strings.NewReader("nonsense")); err == nil {
+		t.Fatal("expected error for unrecognized line")
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	app := New()
+	app.GET("/ok", func(c *Ctx) error { return c.Text("ok") })
+	app.GET("/fail", func(c *Ctx) error { return errors.New("boom") })
+
+	report, err := app.Simulate(context.Background(), Scenario{
+		Requests:    []SimRequest{{Method: "GET", Path: "/ok"}, {Method: "GET", Path: "/fail"}},
+		Repeat:      5,
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 10 || report.Errors != 5 || report.StatusCodes[200] != 5 || report.StatusCodes[500] != 5 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Min > report.P50 || report.P50 > report.P99 || report.P99 > report.Max {
+		t.Fatalf("percentiles out of order: %+v", report)
+	}
+
+	if _, err := app.Simulate(context.Background(), Scenario{}); err == nil {
+		t.Fatal("expected error for empty scenario")
+	}
+	for _, sr := range []SimRequest{{Method: "GE T", Path: "/ok"}, {Method: "GET", Path: "ok"}, {Method: "GET", Path: "/%zz"}} {
+		if _, err := app.Simulate(context.Background(), Scenario{Requests: []SimRequest{sr}}); err == nil {
+			t.Fatalf("expected an error for the invalid request %+v", sr)
+		}
+	}
+	report, err = app.Simulate(context.Background(), Scenario{Requests: []SimRequest{{Method: "GET", Path: "/ok"}}, Rate: 2e9})
+	if err != nil || report.Total != 1 {
+		t.Fatalf("expected huge rates to run as fast as possible, got %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,244 @@
+package owl
+
+import (
+	"net/http"
+	"time"
+)
+
+// StateHandler is a handler receiving the shared state of an AppWith.
+type StateHandler[T any] func(c *Ctx, s T) error
+
+// AppWith is an App whose handlers receive a shared state of type T, such
+// as database pools and API clients, passed explicitly and type-safely
+// rather than through globals or context values:
+//
+//	type Env struct {
+//		DB    *sql.DB
+//		Users *users.Client
+//	}
+//
+//	app := owl.NewWith(&Env{DB: db, Users: client})
+//	app.GET("/users/{id}", func(c *owl.Ctx, env *Env) error {
+//		u, err := env.Users.Get(c.Request.Context(), c.Param("id"))
+//		...
+//	})
+//
+// The embedded App gives access to everything else, e.g. app.Start. Plain
+// Handlers are registered through app.App, and Owl-style middlewares read
+// the state with StateOf.
+type AppWith[T any] struct {
+	*App
+	state T
+}
+
+// NewWith creates an AppWith passing state to its handlers.
+func NewWith[T any](state T, config ...AppConfig) *AppWith[T] {
+	app := New(config...)
+	app.state = state
+	return &AppWith[T]{App: app, state: state}
+}
+
+// StateOf returns the state of the AppWith serving c, or the zero value of
+// T when the App wasn't created with NewWith or holds another type. It is
+// meant for middlewares and helpers that only have a Ctx.
+func StateOf[T any](c *Ctx) T {
+	var s T
+	if c.app != nil {
+		s, _ = c.app.state.(T)
+	}
+	return s
+}
+
+// State returns the state passed to NewWith.
+func (a *AppWith[T]) State() T {
+	return a.state
+}
+
+// Handler adapts h to a Handler, e.g. for RouteBuilder or Mount.
+func (a *AppWith[T]) Handler(h StateHandler[T]) Handler {
+	state := a.state
+	return func(c *Ctx) error {
+		return h(c, state)
+	}
+}
+
+// Use adds middlewares, see App.Use.
+func (a *AppWith[T]) Use(middlewares ...interface{}) *AppWith[T] {
+	a.App.Use(middlewares...)
+	return a
+}
+
+// Group creates a route group with prefix and middlewares.
+func (a *AppWith[T]) Group(prefix string, middlewares ...Middleware) *GroupWith[T] {
+	return &GroupWith[T]{group: a.App.Group(prefix, middlewares...), state: a.state}
+}
+
+// GET registers a GET handler.
+func (a *AppWith[T]) GET(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.GET(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// POST registers a POST handler.
+func (a *AppWith[T]) POST(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.POST(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// PUT registers a PUT handler.
+func (a *AppWith[T]) PUT(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.PUT(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// PATCH registers a PATCH handler.
+func (a *AppWith[T]) PATCH(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.PATCH(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// DELETE registers a DELETE handler.
+func (a *AppWith[T]) DELETE(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.DELETE(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// ANY registers a handler for every HTTP method.
+func (a *AppWith[T]) ANY(path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.ANY(path, a.Handler(h), middlewares...)
+	return a
+}
+
+// Match registers a handler for each of the given HTTP methods.
+func (a *AppWith[T]) Match(methods []string, path string, h StateHandler[T], middlewares ...Middleware) *AppWith[T] {
+	a.App.Match(methods, path, a.Handler(h), middlewares...)
+	return a
+}
+
+// GroupWith is a Group of an AppWith, whose handlers receive the state.
+// Route options are set like on a Group; Untyped returns the Group itself.
+type GroupWith[T any] struct {
+	group *Group
+	state T
+}
+
+// Untyped returns the underlying Group, to register plain Handlers.
+func (g *GroupWith[T]) Untyped() *Group {
+	return g.group
+}
+
+// Handler adapts h to a Handler.
+func (g *GroupWith[T]) Handler(h StateHandler[T]) Handler {
+	state := g.state
+	return func(c *Ctx) error {
+		return h(c, state)
+	}
+}
+
+// Use adds middlewares to this group, see Group.Use.
+func (g *GroupWith[T]) Use(middlewares ...Middleware) *GroupWith[T] {
+	g.group.Use(middlewares...)
+	return g
+}
+
+// BodyLimit overrides the body limit of the group, see Group.BodyLimit.
+func (g *GroupWith[T]) BodyLimit(n int64) *GroupWith[T] {
+	g.group.BodyLimit(n)
+	return g
+}
+
+// StrictJSON overrides StrictJSON for the group, see Group.StrictJSON.
+func (g *GroupWith[T]) StrictJSON(strict bool) *GroupWith[T] {
+	g.group.StrictJSON(strict)
+	return g
+}
+
+// ValidateJSON overrides ValidateJSON for the group, see Group.ValidateJSON.
+func (g *GroupWith[T]) ValidateJSON(validate bool) *GroupWith[T] {
+	g.group.ValidateJSON(validate)
+	return g
+}
+
+// Timeout sets the handler deadline of the group, see Group.Timeout.
+func (g *GroupWith[T]) Timeout(d time.Duration) *GroupWith[T] {
+	g.group.Timeout(d)
+	return g
+}
+
+// Meta attaches metadata to the routes of the group, see Group.Meta.
+func (g *GroupWith[T]) Meta(key string, value interface{}) *GroupWith[T] {
+	g.group.Meta(key, value)
+	return g
+}
+
+// Attr sets an observability attribute, see Group.Attr.
+func (g *GroupWith[T]) Attr(key, value string) *GroupWith[T] {
+	g.group.Attr(key, value)
+	return g
+}
+
+// Tag tags the routes of the group, see Group.Tag.
+func (g *GroupWith[T]) Tag(tags ...string) *GroupWith[T] {
+	g.group.Tag(tags...)
+	return g
+}
+
+// Group creates a sub-group.
+func (g *GroupWith[T]) Group(prefix string, middlewares ...Middleware) *GroupWith[T] {
+	return &GroupWith[T]{group: g.group.Group(prefix, middlewares...), state: g.state}
+}
+
+// GET registers a GET handler.
+func (g *GroupWith[T]) GET(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.GET(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// POST registers a POST handler.
+func (g *GroupWith[T]) POST(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.POST(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// PUT registers a PUT handler.
+func (g *GroupWith[T]) PUT(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.PUT(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// PATCH registers a PATCH handler.
+func (g *GroupWith[T]) PATCH(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.PATCH(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// DELETE registers a DELETE handler.
+func (g *GroupWith[T]) DELETE(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.DELETE(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// ANY registers a handler for every HTTP method.
+func (g *GroupWith[T]) ANY(path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.ANY(path, g.Handler(h), middlewares...)
+	return g
+}
+
+// Match registers a handler for each of the given HTTP methods.
+func (g *GroupWith[T]) Match(methods []string, path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.Match(methods, path, g.Handler(h), middlewares...)
+	return g
+}
+
+// Handle registers a handler for an arbitrary HTTP method, see
+// Group.Handle.
+func (g *GroupWith[T]) Handle(method, path string, h StateHandler[T], middlewares ...Middleware) *GroupWith[T] {
+	g.group.Handle(method, path, g.Handler(h), middlewares...)
+	return g
+}
+
+// HandleHTTP registers a net/http handler, see Group.HandleHTTP.
+func (g *GroupWith[T]) HandleHTTP(method, path string, h http.Handler, middlewares ...Middleware) *GroupWith[T] {
+	g.group.HandleHTTP(method, path, h, middlewares...)
+	return g
+}
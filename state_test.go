@@ -0,0 +1,51 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type testEnv struct {
+	greeting string
+}
+
+func TestNewWith(t *testing.T) {
+	env := &testEnv{greeting: "hello"}
+	app := NewWith(env)
+
+	var fromMiddleware *testEnv
+	app.Use(Middleware(func(next Handler) Handler {
+		return func(c *Ctx) error {
+			fromMiddleware = StateOf[*testEnv](c)
+			return next(c)
+		}
+	}))
+	app.GET("/hello/{name}", func(c *Ctx, e *testEnv) error {
+		return c.Text(e.greeting + " " + c.Param("name"))
+	})
+	api := app.Group("/api").Tag("api")
+	api.POST("/greeting", func(c *Ctx, e *testEnv) error {
+		e.greeting = c.Query("g")
+		return c.Text("ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/hello/owl", nil))
+	if w.Body.String() != "hello owl" {
+		t.Fatalf("expected the state to be passed to handlers, got %q", w.Body.String())
+	}
+	if fromMiddleware != env {
+		t.Fatal("expected StateOf to return the state in middlewares")
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/greeting?g=hi", nil))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/hello/owl", nil))
+	if w.Body.String() != "hi owl" {
+		t.Fatalf("expected groups to share the state, got %q", w.Body.String())
+	}
+
+	if s := StateOf[string](&Ctx{app: app.App}); s != "" {
+		t.Fatalf("expected the zero value for another type, got %q", s)
+	}
+}
@@ -0,0 +1,199 @@
+package owl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig configures App.Static.
+type StaticConfig struct {
+	Index       string        // Index file served for directories (default: "index.html")
+	SPAFallback bool          // Serve the root Index for unknown paths (history API routing)
+	MaxAge      time.Duration // Cache-Control max-age for files (default: 0, no header)
+	Browse      bool          // List directories without an Index file
+
+	// Precompressed serves the name.br or name.gz sibling of a file, such
+	// as app.js.br, when the client accepts that encoding, so that build
+	// pipelines can compress assets ahead of time.
+	Precompressed bool
+}
+
+// Static serves files from fsys below prefix, for GET and HEAD requests.
+// fsys can be an embed.FS, os.DirFS or any other fs.FS:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	app.Static("/", sub, owl.StaticConfig{SPAFallback: true, MaxAge: 24 * time.Hour})
+//
+// With SPAFallback, requests for missing paths without a file extension are
+// answered with the root Index so client-side routers can take over; the
+// Index is always sent with Cache-Control: no-cache so deployments are
+// picked up. Range and conditional requests are supported.
+func (a *App) Static(prefix string, fsys fs.FS, config ...StaticConfig) *App {
+	cfg := StaticConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+
+	h := &staticHandler{fsys: fsys, cfg: cfg}
+	pattern := strings.TrimSuffix(prefix, "/") + "/*"
+	a.mux.Method(http.MethodGet, pattern, h)
+	a.mux.Method(http.MethodHead, pattern, h)
+	return a
+}
+
+type staticHandler struct {
+	fsys fs.FS
+	cfg  StaticConfig
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + URLParam(r, "*"))[1:]
+	if name == "" {
+		name = "."
+	}
+
+	fi, err := fs.Stat(h.fsys, name)
+	switch {
+	case err == nil && fi.IsDir():
+		index := path.Join(name, h.cfg.Index)
+		if ifi, err := fs.Stat(h.fsys, index); err == nil && !ifi.IsDir() {
+			h.serveFile(w, r, index, ifi, true)
+			return
+		}
+		if h.cfg.Browse {
+			if !strings.HasSuffix(r.URL.Path, "/") {
+				http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+				return
+			}
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = "/" + strings.TrimPrefix(name+"/", "./")
+			http.FileServer(http.FS(h.fsys)).ServeHTTP(w, r2)
+			return
+		}
+	case err == nil:
+		h.serveFile(w, r, name, fi, name == h.cfg.Index)
+		return
+	case !errors.Is(err, fs.ErrNotExist):
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if h.cfg.SPAFallback && path.Ext(name) == "" {
+		if ifi, err := fs.Stat(h.fsys, h.cfg.Index); err == nil && !ifi.IsDir() {
+			h.serveFile(w, r, h.cfg.Index, ifi, true)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (h *staticHandler) serveFile(w http.ResponseWriter, r *http.Request, name string, fi fs.FileInfo, index bool) {
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	switch {
+	case index:
+		w.Header().Set("Cache-Control", "no-cache")
+	case h.cfg.MaxAge > 0:
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.cfg.MaxAge.Seconds())))
+	}
+
+	if h.cfg.Precompressed {
+		if cf, cfi := h.openPrecompressed(w, r, name); cf != nil {
+			defer cf.Close()
+			f, fi = cf, cfi
+		}
+	}
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, fi.Name(), fi.ModTime(), rs)
+		return
+	}
+	// fs.File implementations without Seek (rare) are read into a pooled
+	// buffer.
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(f); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), bytes.NewReader(buf.Bytes()))
+}
+
+// precompressedEncodings are the encodings of the siblings served with
+// StaticConfig.Precompressed, by preference.
+var precompressedEncodings = []struct{ encoding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// openPrecompressed opens the precompressed sibling of name the client
+// accepts, if any, and sets the headers describing it.
+func (h *staticHandler) openPrecompressed(w http.ResponseWriter, r *http.Request, name string) (fs.File, fs.FileInfo) {
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		return nil, nil
+	}
+	varies := false
+	for _, pc := range precompressedEncodings {
+		fi, err := fs.Stat(h.fsys, name+pc.ext)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if !varies {
+			w.Header().Add("Vary", "Accept-Encoding")
+			varies = true
+		}
+		if !acceptsEncoding(r.Header.Get("Accept-Encoding"), pc.encoding) {
+			continue
+		}
+		f, err := h.fsys.Open(name + pc.ext)
+		if err != nil {
+			continue
+		}
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Encoding", pc.encoding)
+		return f, fi
+	}
+	return nil, nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header value accepts
+// encoding, explicitly or through "*".
+func acceptsEncoding(header, encoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		accepted := true
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				accepted = false
+			}
+		}
+		switch {
+		case strings.EqualFold(token, encoding):
+			return accepted
+		case token == "*":
+			wildcard = accepted
+		}
+	}
+	return wildcard
+}
@@ -0,0 +1,95 @@
+package owl
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestStatic(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte("<h1>app</h1>")},
+		"js/app.js":      {Data: []byte("console.log(1)")},
+		"docs/guide.txt": {Data: []byte("guide")},
+	}
+
+	app := New()
+	app.GET("/api/ping", func(c *Ctx) error { return c.Text("pong") })
+	app.Static("/", fsys, StaticConfig{SPAFallback: true, MaxAge: time.Hour, Browse: true})
+
+	tests := []struct {
+		path, body, cache string
+		code              int
+	}{
+		{"/", "<h1>app</h1>", "no-cache", 200},
+		{"/js/app.js", "console.log(1)", "public, max-age=3600", 200},
+		{"/dashboard/settings", "<h1>app</h1>", "no-cache", 200},
+		{"/js/missing.js", "", "", 404},
+		{"/api/ping", "pong", "", 200},
+		{"/docs/", "guide.txt", "", 200},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.body) || w.Header().Get("Cache-Control") != tt.cache {
+			t.Errorf("%s: got %d %q cache=%q", tt.path, w.Code, w.Body.String(), w.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestStaticNoBrowse(t *testing.T) {
+	app := New()
+	app.Static("/assets", fstest.MapFS{"css/site.css": {Data: []byte("body{}")}})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/assets/css/", nil))
+	if w.Code != 404 {
+		t.Fatalf("directory listing should be disabled, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/assets/css/site.css", nil))
+	if w.Body.String() != "body{}" || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+		t.Fatalf("unexpected file response %d %q %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":      {Data: []byte("console.log(1)")},
+		"app.js.br":   {Data: []byte("brotli")},
+		"app.js.gz":   {Data: []byte("gzip")},
+		"style.css":   {Data: []byte("body{}")},
+		"logo.svg":    {Data: []byte("<svg/>")},
+		"logo.svg.gz": {Data: []byte("gzipped svg")},
+	}
+	app := New()
+	app.Static("/", fsys, StaticConfig{Precompressed: true})
+
+	tests := []struct {
+		path, accept, body, encoding, vary string
+	}{
+		{"/app.js", "gzip, deflate, br", "brotli", "br", "Accept-Encoding"},
+		{"/app.js", "gzip", "gzip", "gzip", "Accept-Encoding"},
+		{"/app.js", "br;q=0, gzip;q=0.5", "gzip", "gzip", "Accept-Encoding"},
+		{"/app.js", "", "console.log(1)", "", "Accept-Encoding"},
+		{"/app.js", "*", "brotli", "br", "Accept-Encoding"},
+		{"/logo.svg", "br, gzip", "gzipped svg", "gzip", "Accept-Encoding"},
+		{"/style.css", "br, gzip", "body{}", "", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		r.Header.Set("Accept-Encoding", tt.accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		h := w.Header()
+		if w.Code != 200 || w.Body.String() != tt.body || h.Get("Content-Encoding") != tt.encoding || h.Get("Vary") != tt.vary {
+			t.Errorf("%s (%s): got %d %q, headers %v", tt.path, tt.accept, w.Code, w.Body.String(), h)
+		}
+		if tt.encoding != "" && !strings.HasPrefix(h.Get("Content-Type"), "text/javascript") && !strings.HasPrefix(h.Get("Content-Type"), "image/svg+xml") {
+			t.Errorf("%s: expected the Content-Type of the original file, got %q", tt.path, h.Get("Content-Type"))
+		}
+	}
+}
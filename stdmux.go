@@ -0,0 +1,93 @@
+package owl
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HandlePattern registers h for a Go 1.22 net/http.ServeMux style pattern
+// such as "GET /users/{id}", "/static/" or "POST /files/{path...}", which
+// eases moving routes over from an existing stdlib server.
+//
+// The pattern is translated to Owl's routing syntax:
+//   - an optional leading method restricts the route to that method
+//   - "{name...}" becomes a catch-all whose value is available as both
+//     c.Param("name") and c.Param("*")
+//   - a trailing "/" matches the whole subtree, as with ServeMux
+//   - a trailing "{$}" matches only the path itself
+//
+// A host-qualified pattern such as "GET api.example.com/status" is
+// registered on the matching Host tree. Unlike ServeMux, a GET pattern does
+// not also match HEAD requests.
+func (a *App) HandlePattern(pattern string, h Handler, middlewares ...Middleware) *App {
+	method, host, path, wildcard := parseStdPattern(pattern)
+	if wildcard != "" {
+		h = aliasWildcard(wildcard, h)
+	}
+	mux := a.mux
+	if host != "" {
+		mux = a.hostMux(host)
+	}
+	a.last = a.register(mux, []string{method}, path, h, a.scope.child(middlewares...), a.routeDefaults())
+	return a
+}
+
+// MountOn registers the App on a net/http.ServeMux below prefix. The prefix
+// is stripped before routing, so the App's routes are declared relative to
+// it:
+//
+//	mux := http.NewServeMux()
+//	app.GET("/users", listUsers) // served at /api/users
+//	app.MountOn(mux, "/api")
+func (a *App) MountOn(mux *http.ServeMux, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		mux.Handle("/", a)
+		return
+	}
+	mux.Handle(prefix+"/", http.StripPrefix(prefix, a))
+}
+
+// parseStdPattern splits a ServeMux pattern into its method, host and an Owl
+// routing path. wildcard is the name of a trailing "{name...}" segment.
+func parseStdPattern(pattern string) (method, host, path, wildcard string) {
+	path = strings.TrimSpace(pattern)
+	if i := strings.IndexAny(path, " \t"); i >= 0 {
+		method = strings.ToUpper(path[:i])
+		path = strings.TrimLeft(path[i+1:], " \t")
+	}
+	if i := strings.IndexByte(path, '/'); i > 0 {
+		host, path = path[:i], path[i:]
+	}
+	if path == "" || path[0] != '/' {
+		panic(fmt.Sprintf("owl: routing pattern must contain a path in '%s'", pattern))
+	}
+
+	switch {
+	case strings.HasSuffix(path, "{$}"):
+		path = strings.TrimSuffix(path, "{$}")
+	case strings.HasSuffix(path, "...}"):
+		i := strings.LastIndex(path, "/{")
+		if i < 0 {
+			panic(fmt.Sprintf("owl: invalid wildcard in pattern '%s'", pattern))
+		}
+		wildcard = path[i+2 : len(path)-len("...}")]
+		path = path[:i+1] + "*"
+	case strings.HasSuffix(path, "/"):
+		path += "*"
+	}
+	return method, host, path, wildcard
+}
+
+// aliasWildcard exposes the catch-all value under the stdlib wildcard name.
+func aliasWildcard(name string, next Handler) Handler {
+	return func(c *Ctx) error {
+		if rctx := c.routeContext(); rctx != nil {
+			v := rctx.URLParam("*")
+			rctx.URLParams.Add(name, v)
+			c.Request.SetPathValue(name, v)
+		}
+		return next(c)
+	}
+}
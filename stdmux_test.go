@@ -0,0 +1,77 @@
+package owl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseStdPattern(t *testing.T) {
+	tests := []struct {
+		pattern, method, host, path, wildcard string
+	}{
+		{"GET /users/{id}", "GET", "", "/users/{id}", ""},
+		{"/static/", "", "", "/static/*", ""},
+		{"POST /files/{path...}", "POST", "", "/files/*", "path"},
+		{"get /exact/{$}", "GET", "", "/exact/", ""},
+		{"/", "", "", "/*", ""},
+		{"GET api.example.com/status", "GET", "api.example.com", "/status", ""},
+	}
+	for _, tt := range tests {
+		m, h, p, w := parseStdPattern(tt.pattern)
+		if m != tt.method || h != tt.host || p != tt.path || w != tt.wildcard {
+			t.Errorf("%q: got (%q, %q, %q, %q), want (%q, %q, %q, %q)", tt.pattern, m, h, p, w, tt.method, tt.host, tt.path, tt.wildcard)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for pattern without path")
+		}
+	}()
+	parseStdPattern("GET example.com")
+}
+
+func TestHandlePattern(t *testing.T) {
+	app := New()
+	app.HandlePattern("GET /users/{id}", func(c *Ctx) error {
+		return c.Text("user " + c.Param("id"))
+	})
+	app.HandlePattern("GET /files/{path...}", func(c *Ctx) error {
+		return c.Text(c.Param("path") + "|" + c.Request.PathValue("path"))
+	})
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{"GET", "/users/7", 200, "user 7"},
+		{"POST", "/users/7", 405, ""},
+		{"GET", "/files/a/b.txt", 200, "a/b.txt|a/b.txt"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s %s: got %d %q", tt.method, tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestMountOn(t *testing.T) {
+	app := New()
+	app.GET("/users", func(c *Ctx) error { return c.Text("users") })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/legacy", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("legacy")) })
+	app.MountOn(mux, "/api/")
+
+	for path, body := range map[string]string{"/api/users": "users", "/legacy": "legacy"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != body {
+			t.Errorf("%s: got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
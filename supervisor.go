@@ -0,0 +1,480 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SupervisorConfig holds configuration for a Supervisor.
+type SupervisorConfig struct {
+	// ShutdownTimeout is the deadline shared by every App to finish
+	// in-flight requests once shutdown starts (default: 30s).
+	ShutdownTimeout time.Duration
+
+	// Signals trigger the shutdown (default: SIGINT and SIGTERM).
+	Signals []os.Signal
+
+	// DrainDelay keeps serving the Apps in drain mode for this long before
+	// shutting them down, see App.Drain, so that load balancers notice the
+	// failing readiness checks first, e.g. during Kubernetes rollouts
+	// (default: 0, no delay).
+	DrainDelay time.Duration
+
+	// RestartSignal, e.g. syscall.SIGUSR2, restarts the process without
+	// dropping connections, to apply a new binary or configuration: a new
+	// instance of the executable is started with the listeners of the
+	// Supervisor, and shuts this one down once it serves (default: nil,
+	// disabled). Listeners given to AddListener and custom Transports are
+	// not handed over. Unix only.
+	RestartSignal os.Signal
+}
+
+// Supervisor runs several Apps in one process, e.g. a public API, an admin
+// App and a metrics listener. It starts them together, reports their
+// health as a whole, and shuts them all down on the first signal or
+// failure, with a shared deadline:
+//
+//	sup := owl.NewSupervisor()
+//	sup.Add(":8080", api)
+//	sup.Add(":9090", admin)
+//	admin.GET("/health", sup.HealthHandler())
+//	if err := sup.Run(context.Background()); err != nil {
+//		log.Fatal(err)
+//	}
+type Supervisor struct {
+	timeout    time.Duration
+	signals    []os.Signal
+	drainDelay time.Duration
+	restartSig os.Signal
+
+	mu      sync.Mutex
+	members []*supervised
+	running bool
+}
+
+// supervised is an App run by a Supervisor.
+type supervised struct {
+	app    *App
+	addr   string
+	listen string // addr as given to Add, before ":0" is resolved
+	state  string // "stopped", "up", "stopping" or "failed"
+
+	tls               bool // See AddTLS
+	certFile, keyFile string
+	ln                net.Listener // See AddListener
+	hsts              string       // Strict-Transport-Security of responses, see AddTLSRedirect
+}
+
+// NewSupervisor creates a Supervisor with optional configuration.
+func NewSupervisor(config ...SupervisorConfig) *Supervisor {
+	s := &Supervisor{
+		timeout: 30 * time.Second,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	if len(config) > 0 {
+		if config[0].ShutdownTimeout > 0 {
+			s.timeout = config[0].ShutdownTimeout
+		}
+		if len(config[0].Signals) > 0 {
+			s.signals = config[0].Signals
+		}
+		s.drainDelay = config[0].DrainDelay
+		s.restartSig = config[0].RestartSignal
+	}
+	return s
+}
+
+// Add registers app to serve on addr. It panics once Run was called.
+func (s *Supervisor) Add(addr string, app *App) *Supervisor {
+	if app == nil {
+		panic("owl: attempting to supervise a nil App on '" + addr + "'")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		panic("owl: Supervisor.Add must happen before Run")
+	}
+	s.members = append(s.members, &supervised{app: app, addr: addr, listen: addr, state: "stopped"})
+	return s
+}
+
+// AddTLS registers app to serve HTTPS on addr with the certificate and key
+// of the PEM files certFile and keyFile, see App.StartTLS. It panics once
+// Run was called or when app has a custom Transport.
+func (s *Supervisor) AddTLS(addr string, app *App, certFile, keyFile string) *Supervisor {
+	if app != nil && app.transport != nil {
+		panic("owl: custom Transports don't support TLS on '" + addr + "'")
+	}
+	s.Add(addr, app)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.members[len(s.members)-1]
+	m.tls, m.certFile, m.keyFile = true, certFile, keyFile
+	return s
+}
+
+// RedirectConfig configures the HTTP listener of Supervisor.AddTLSRedirect.
+type RedirectConfig struct {
+	// HTTPAddr is redirected to HTTPS (default: ":80").
+	HTTPAddr string
+
+	// HSTSMaxAge, when set, adds a Strict-Transport-Security header to the
+	// HTTPS responses so that browsers skip the redirect next time.
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains and HSTSPreload add the includeSubDomains and
+	// preload directives to the Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+}
+
+// AddTLSRedirect registers app to serve HTTPS on addr, see AddTLS, along
+// with an HTTP listener answering every request with a permanent redirect
+// to the same host and path over HTTPS. Both are started and shut down
+// together with the other Apps:
+//
+//	sup.AddTLSRedirect(":443", app, "cert.pem", "key.pem", owl.RedirectConfig{
+//		HSTSMaxAge: 365 * 24 * time.Hour,
+//	})
+func (s *Supervisor) AddTLSRedirect(addr string, app *App, certFile, keyFile string, config ...RedirectConfig) *Supervisor {
+	var cfg RedirectConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.HTTPAddr == "" {
+		cfg.HTTPAddr = ":80"
+	}
+
+	s.AddTLS(addr, app, certFile, keyFile)
+	s.mu.Lock()
+	m := s.members[len(s.members)-1]
+	if cfg.HSTSMaxAge > 0 {
+		m.hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			m.hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			m.hsts += "; preload"
+		}
+	}
+	s.mu.Unlock()
+
+	redirect := New(AppConfig{Name: app.name + " redirect", Version: app.version, Logger: app.logger})
+	redirect.mux.Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		// The port is read at request time since ":0" is only
+		// resolved by Run.
+		s.mu.Lock()
+		_, port, _ := net.SplitHostPort(m.addr)
+		s.mu.Unlock()
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, target.String(), code)
+	}))
+	return s.Add(cfg.HTTPAddr, redirect)
+}
+
+// AddListener registers app to serve on ln, such as a unix socket or a
+// systemd activated socket, see ListenUnix and SystemdListeners. The
+// Supervisor closes ln when it stops. It panics once Run was called or
+// when app has a custom Transport.
+func (s *Supervisor) AddListener(ln net.Listener, app *App) *Supervisor {
+	addr := ln.Addr().String()
+	if app != nil && app.transport != nil {
+		panic("owl: custom Transports can't serve the listener '" + addr + "'")
+	}
+	s.Add(addr, app)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[len(s.members)-1].ln = ln
+	return s
+}
+
+// Run builds every App, opens their listeners and serves until ctx is
+// done, one of the configured signals is received or an App fails. It then
+// shuts every App down within ShutdownTimeout and returns the errors of
+// the failed Apps and of the shutdown, or nil after a clean stop.
+//
+// Nothing is served when an App fails to build or to listen.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errors.New("owl: Supervisor is already running")
+	}
+	s.running = true
+	members := s.members
+	s.mu.Unlock()
+
+	var errs []error
+	for _, m := range members {
+		if err := m.app.Build(); err != nil {
+			errs = append(errs, fmt.Errorf("%s on %s: %w", m.app.name, m.addr, err))
+		}
+	}
+	listeners := make([]net.Listener, len(members))
+	for i, m := range members {
+		listeners[i] = m.ln
+	}
+	closeListeners := func() {
+		for _, l := range listeners {
+			if l != nil {
+				l.Close()
+			}
+		}
+	}
+	inherited, err := inheritedListeners()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		closeListeners()
+		return errors.Join(errs...)
+	}
+
+	for i, m := range members {
+		if m.app.transport != nil || m.ln != nil {
+			continue // Given to AddListener, or opened by the transport
+		}
+		ln, ok := inherited[m.listen]
+		if ok {
+			delete(inherited, m.listen)
+		} else if ln, err = net.Listen("tcp", m.addr); err != nil {
+			closeListeners()
+			for _, l := range inherited {
+				l.Close()
+			}
+			return fmt.Errorf("%s on %s: %w", m.app.name, m.addr, err)
+		}
+		listeners[i] = ln
+		s.mu.Lock()
+		m.addr = ln.Addr().String() // Resolves ":0" for Health
+		s.mu.Unlock()
+	}
+
+	for _, l := range inherited {
+		l.Close() // Its App is no longer supervised
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, s.signals...)
+	defer stop()
+
+	failed := make(chan error, len(members))
+	var wg sync.WaitGroup
+	for i, m := range members {
+		m.app.refreshSecrets()
+		s.setState(m, "up")
+		m.app.started(m.addr)
+
+		var srv *http.Server
+		if ln := listeners[i]; ln != nil {
+			// The server is created before serving so that a shutdown
+			// racing with the start still finds it.
+			srv = m.app.Listen(ln.Addr().String())
+			if m.hsts != "" {
+				srv.Handler = withHSTS(srv.Handler, m.hsts)
+			}
+		}
+
+		wg.Add(1)
+		go func(m *supervised, srv *http.Server, ln net.Listener) {
+			defer wg.Done()
+			var err error
+			switch {
+			case srv == nil:
+				err = m.app.transport.ListenAndServe(m.addr, m.app)
+			case m.tls:
+				err = srv.ServeTLS(ln, m.certFile, m.keyFile)
+			default:
+				err = srv.Serve(ln)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.setState(m, "failed")
+				failed <- fmt.Errorf("%s on %s: %w", m.app.name, m.addr, err)
+			}
+		}(m, srv, listeners[i])
+	}
+	s.stopParent()
+
+	if s.restartSig != nil && len(members) > 0 {
+		restart := make(chan os.Signal, 1)
+		signal.Notify(restart, s.restartSig)
+		defer signal.Stop(restart)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-restart:
+					if err := s.restart(members, listeners); err != nil {
+						members[0].app.logf(LevelError, "restart failed", "error", err)
+					} else {
+						members[0].app.logf(LevelInfo, "restarting", "pid", os.Getpid())
+					}
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+	case err := <-failed:
+		errs = append(errs, err)
+	}
+
+	if s.drainDelay > 0 {
+		for _, m := range members {
+			m.app.Drain()
+			m.app.logf(LevelInfo, "server draining", "name", m.app.name, "addr", m.addr, "delay", s.drainDelay)
+		}
+		time.Sleep(s.drainDelay)
+	}
+
+	// An App added on several addresses is shut down once, with all of
+	// its servers.
+	byApp := map[*App][]*supervised{}
+	var apps []*App
+	for _, m := range members {
+		if byApp[m.app] == nil {
+			apps = append(apps, m.app)
+		}
+		byApp[m.app] = append(byApp[m.app], m)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	var mu sync.Mutex
+	var shutdown sync.WaitGroup
+	for _, app := range apps {
+		shutdown.Add(1)
+		go func(ms []*supervised) {
+			defer shutdown.Done()
+			for _, m := range ms {
+				if s.state(m) == "up" {
+					s.setState(m, "stopping")
+					m.app.logf(LevelInfo, "server shutting down", "name", m.app.name, "addr", m.addr)
+				}
+			}
+			m := ms[0]
+			stopProgress := s.logProgress(m)
+			err := m.app.ShutdownWithContext(shutdownCtx)
+			stopProgress()
+			if err != nil {
+				// Requests outliving the deadline are cut short.
+				m.app.closeServer()
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s on %s: shutdown: %w", m.app.name, m.addr, err))
+				mu.Unlock()
+			}
+			for _, m := range ms {
+				if s.state(m) == "stopping" {
+					s.setState(m, "stopped")
+				}
+			}
+		}(byApp[app])
+	}
+	shutdown.Wait()
+	wg.Wait()
+
+	close(failed)
+	for err := range failed {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// progressInterval is the interval of the shutdown progress logs.
+var progressInterval = 5 * time.Second
+
+// logProgress logs the requests m still serves at every progressInterval,
+// until the returned function is called.
+func (s *Supervisor) logProgress(m *supervised) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.app.logf(LevelInfo, "waiting for in-flight requests", "name", m.app.name, "addr", m.addr, "in_flight", m.app.InFlight())
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// withHSTS sets the Strict-Transport-Security header of the responses of h.
+func withHSTS(h http.Handler, hsts string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", hsts)
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (s *Supervisor) state(m *supervised) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return m.state
+}
+
+func (s *Supervisor) setState(m *supervised, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.state = state
+}
+
+// SupervisedApp describes the state of an App run by a Supervisor.
+type SupervisedApp struct {
+	Name  string `json:"name"`
+	Addr  string `json:"addr"`
+	State string `json:"state"` // "stopped", "up", "stopping" or "failed"
+}
+
+// Health returns the state of every App and whether they are all up.
+func (s *Supervisor) Health() ([]SupervisedApp, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	apps := make([]SupervisedApp, len(s.members))
+	healthy := len(s.members) > 0
+	for i, m := range s.members {
+		apps[i] = SupervisedApp{Name: m.app.name, Addr: m.addr, State: m.state}
+		healthy = healthy && m.state == "up"
+	}
+	return apps, healthy
+}
+
+// HealthHandler answers the aggregated health of the supervised Apps as
+// JSON, with status 503 when one of them isn't up, for load balancer or
+// orchestrator probes.
+func (s *Supervisor) HealthHandler() Handler {
+	return func(c *Ctx) error {
+		apps, healthy := s.Health()
+		status := "up"
+		if !healthy {
+			status = "down"
+			c.Status(http.StatusServiceUnavailable)
+		}
+		return c.JSON(map[string]interface{}{"status": status, "apps": apps})
+	}
+}
@@ -0,0 +1,271 @@
+package owl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSupervisor(t *testing.T) {
+	api := New(AppConfig{Name: "api"})
+	api.GET("/", func(c *Ctx) error { return c.Text("api") })
+	admin := New(AppConfig{Name: "admin"})
+
+	sup := NewSupervisor(SupervisorConfig{ShutdownTimeout: time.Second})
+	sup.Add("127.0.0.1:0", api).Add("127.0.0.1:0", admin)
+	admin.GET("/health", sup.HealthHandler())
+
+	if _, healthy := sup.Health(); healthy {
+		t.Fatal("expected stopped Apps not to be healthy")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Apps didn't start: %+v", apps)
+		}
+	}
+
+	resp, err := http.Get("http://" + apps[0].Addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	resp, err = http.Get("http://" + apps[1].Addr + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health struct {
+		Status string          `json:"status"`
+		Apps   []SupervisedApp `json:"apps"`
+	}
+	json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || health.Status != "up" || len(health.Apps) != 2 {
+		t.Fatalf("unexpected health: %d %+v", resp.StatusCode, health)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean stop, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run didn't return after cancel")
+	}
+	if _, err := http.Get("http://" + apps[0].Addr + "/"); err == nil {
+		t.Fatal("expected the Apps to be shut down")
+	}
+
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once stopped, got %d", w.Code)
+	}
+}
+
+func TestSupervisorSameAppTwice(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+	sup := NewSupervisor(SupervisorConfig{ShutdownTimeout: time.Second})
+	sup.Add("127.0.0.1:0", app).Add("127.0.0.1:0", app)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Apps didn't start: %+v", apps)
+		}
+	}
+	client := &http.Client{}
+	for _, a := range apps {
+		resp, err := client.Get("http://" + a.Addr + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	client.CloseIdleConnections()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean stop, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run didn't return after cancel")
+	}
+	for _, a := range apps {
+		if _, err := http.Get("http://" + a.Addr + "/"); err == nil {
+			t.Fatalf("expected %s to be shut down", a.Addr)
+		}
+	}
+}
+
+func TestSupervisorBuildErrors(t *testing.T) {
+	bad := New()
+	bad.GET("/", nil)
+	sup := NewSupervisor().Add("127.0.0.1:0", New()).Add("127.0.0.1:0", bad)
+	err := sup.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Owl on 127.0.0.1:0") {
+		t.Fatalf("expected the build error of the failing App, got %v", err)
+	}
+	if apps, _ := sup.Health(); apps[0].State != "stopped" {
+		t.Fatalf("expected nothing to be served, got %+v", apps)
+	}
+}
+
+func TestSupervisorTLS(t *testing.T) {
+	app := New(AppConfig{TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12}})
+	app.GET("/", func(c *Ctx) error { return c.Text("secure") })
+	if srv := app.Listen(":0"); srv.TLSConfig == nil || srv.TLSConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected the TLSConfig to be applied, got %+v", srv.TLSConfig)
+	}
+
+	sup := NewSupervisor(SupervisorConfig{ShutdownTimeout: time.Second})
+	sup.AddTLS("127.0.0.1:0", app, "testdata/cert.pem", "testdata/key.pem")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("App didn't start: %+v", apps)
+		}
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS11},
+	}}
+	if _, err := client.Get("https://" + apps[0].Addr + "/"); err == nil {
+		t.Fatal("expected TLS 1.1 to be refused")
+	}
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = 0
+	resp, err := client.Get("https://" + apps[0].Addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 || resp.TLS == nil {
+		t.Fatalf("expected an HTTPS response, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("expected a clean stop, got %v", err)
+	}
+}
+
+func TestSupervisorTLSRedirect(t *testing.T) {
+	app := New(AppConfig{Name: "web"})
+	app.GET("/", func(c *Ctx) error { return c.Text("secure") })
+
+	sup := NewSupervisor(SupervisorConfig{ShutdownTimeout: time.Second})
+	sup.AddTLSRedirect("127.0.0.1:0", app, "testdata/cert.pem", "testdata/key.pem", RedirectConfig{
+		HTTPAddr:              "127.0.0.1:0",
+		HSTSMaxAge:            24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sup.Run(ctx) }()
+
+	var apps []SupervisedApp
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var healthy bool
+		if apps, healthy = sup.Health(); healthy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Apps didn't start: %+v", apps)
+		}
+	}
+	if len(apps) != 2 || apps[1].Name != "web redirect" {
+		t.Fatalf("expected the redirect to be supervised, got %+v", apps)
+	}
+
+	client := &http.Client{
+		Transport:     &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Get("http://" + apps[1].Addr + "/docs?page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	_, port, _ := strings.Cut(apps[0].Addr, ":")
+	if want := "https://127.0.0.1:" + port + "/docs?page=2"; resp.StatusCode != 301 || resp.Header.Get("Location") != want {
+		t.Fatalf("expected a 301 to %s, got %d %s", want, resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, err = client.Post("http://"+apps[1].Addr+"/", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		t.Fatalf("expected a 308 for POST, got %d", resp.StatusCode)
+	}
+
+	resp, err = client.Get("https://" + apps[0].Addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if hsts := resp.Header.Get("Strict-Transport-Security"); hsts != "max-age=86400; includeSubDomains" {
+		t.Fatalf("unexpected HSTS header %q", hsts)
+	}
+
+	client.CloseIdleConnections()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("expected a clean stop, got %v", err)
+	}
+}
+
+func TestAppRun(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error { return c.Text("ok") })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := app.Run(context.Background(), ln.Addr().String()); err == nil || !strings.Contains(err.Error(), "address already in use") {
+		t.Fatalf("expected the listen error to be returned, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := New().Run(ctx, "127.0.0.1:0", SupervisorConfig{ShutdownTimeout: time.Second}); err != nil {
+		t.Fatalf("expected a clean stop once ctx is done, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package owl
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serveTimeout runs h with a deadline of opts.timeout, much like
+// http.TimeoutHandler: the handler writes into a buffer, copied to w when it
+// returns in time. Otherwise further writes are refused, then the request
+// context is canceled and the error handler answers with 504.
+func (a *App) serveTimeout(w http.ResponseWriter, r *http.Request, h Handler, opts routeOptions) {
+	tw := &timeoutWriter{header: make(http.Header)}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	deadline := time.Now().Add(opts.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	// Writes are refused before the context is canceled, so that a handler
+	// waiting on it can't answer in place of the 504.
+	timer := time.AfterFunc(opts.timeout, func() {
+		tw.mu.Lock()
+		if !tw.finished {
+			tw.timedOut, tw.expired = true, true
+		}
+		tw.mu.Unlock()
+		cancel()
+	})
+	defer timer.Stop()
+	r = r.WithContext(&deadlineContext{Context: ctx, deadline: deadline, tw: tw})
+
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		a.serve(tw, r, h, opts)
+		tw.mu.Lock()
+		tw.finished = !tw.timedOut
+		tw.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	tw.mu.Lock()
+	if tw.finished {
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for k, vv := range tw.header {
+			dst[k] = vv
+		}
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+		w.WriteHeader(tw.code)
+		w.Write(tw.buf.Bytes())
+		return
+	}
+	tw.timedOut = true // The client is gone when the deadline isn't the cause
+	expired := tw.expired
+	tw.mu.Unlock()
+	if expired {
+		c := newCtx(w, r)
+		c.route = opts
+		c.app = a
+		a.errorHandler(c, NewHTTPError(http.StatusGatewayTimeout, "request timed out"))
+	}
+}
+
+// deadlineContext is the request context of handlers with a timeout. It
+// reports the deadline and, once it expired, context.DeadlineExceeded,
+// like a context of context.WithDeadline.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+	tw       *timeoutWriter
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Err() error {
+	err := c.Context.Err()
+	if err == context.Canceled {
+		c.tw.mu.Lock()
+		defer c.tw.mu.Unlock()
+		if c.tw.expired {
+			return context.DeadlineExceeded
+		}
+	}
+	return err
+}
+
+// timeoutWriter buffers a response until the handler returns or times out.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	timedOut bool // Writes are refused
+	expired  bool // The deadline passed before the handler returned
+	finished bool // The handler returned in time
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
@@ -0,0 +1,68 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+
+	app := New()
+	api := app.Group("/api").Timeout(20 * time.Millisecond)
+	api.GET("/fast", func(c *Ctx) error {
+		c.SetHeader("X-Fast", "1")
+		return c.Status(http.StatusCreated).Text("fast")
+	})
+	api.GET("/slow", func(c *Ctx) error {
+		<-c.Request.Context().Done()
+		if err := c.Request.Context().Err(); err != context.DeadlineExceeded {
+			lateWrite <- err
+			return nil
+		}
+		_, err := c.Response.Write([]byte("too late"))
+		lateWrite <- err
+		return nil
+	})
+	api.Route("/patient").Timeout(time.Second).GET(func(c *Ctx) error {
+		time.Sleep(40 * time.Millisecond)
+		return c.Text("done")
+	})
+	api.GET("/panic", func(c *Ctx) error { panic("boom") })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/fast", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "fast" || w.Header().Get("X-Fast") != "1" {
+		t.Fatalf("unexpected fast response %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/slow", nil))
+	if w.Code != http.StatusGatewayTimeout || !strings.Contains(w.Body.String(), `"code":504`) {
+		t.Fatalf("expected 504 JSON, got %d %q", w.Code, w.Body.String())
+	}
+	if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Fatalf("expected late write to fail, got %v", err)
+	}
+	if strings.Contains(w.Body.String(), "too late") {
+		t.Fatal("late write reached the client")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/patient", nil))
+	if w.Body.String() != "done" {
+		t.Fatalf("route timeout should override group timeout, got %d %q", w.Code, w.Body.String())
+	}
+
+	defer func() {
+		if recover() != "boom" {
+			t.Fatal("expected handler panic to propagate")
+		}
+	}()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/panic", nil))
+}
@@ -0,0 +1,22 @@
+package owl
+
+import (
+	"context"
+	"net/http"
+)
+
+// Transport is the network layer an App is served by. Routing, Ctx and
+// binding always operate on net/http types; a Transport only decides how
+// connections are accepted and turned into http.Requests. The default is
+// net/http's own server.
+//
+// Alternative transports (for example the fasthttp-backed one in the
+// owlfasthttp module) are selected with AppConfig.Transport.
+type Transport interface {
+	// ListenAndServe serves h on addr and blocks until the transport is
+	// shut down or fails.
+	ListenAndServe(addr string, h http.Handler) error
+
+	// Shutdown gracefully stops the transport, giving up when ctx is done.
+	Shutdown(ctx context.Context) error
+}
@@ -75,6 +75,28 @@ func RegisterMethod(method string) {
 	mALL |= mt
 }
 
+// paramTypes maps the named constraints usable in route params, such as
+// {id:int}, to the regular expressions they stand for.
+var paramTypes = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// RegisterParamType adds a named route param constraint, so that
+// {key:name} in a routing pattern matches the regular expression expr.
+// Built-in types are int, uint, alpha, alnum, slug and uuid. It must be
+// called before routes using the type are registered.
+func RegisterParamType(name, expr string) {
+	if name == "" || expr == "" {
+		panic("chi: param type name and expression must not be empty")
+	}
+	paramTypes[name] = expr
+}
+
 type nodeTyp uint8
 
 const (
@@ -730,6 +752,9 @@ func patNextSegment(pattern string) (nodeTyp, string, string, byte, int, int) {
 		key, rexpat, isRegexp := strings.Cut(key, ":")
 		if isRegexp {
 			nt = ntRegexp
+			if expr, ok := paramTypes[rexpat]; ok {
+				rexpat = expr
+			}
 		}
 
 		if len(rexpat) > 0 {
@@ -521,3 +521,33 @@ func TestWalker(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestTreeParamTypes(t *testing.T) {
+	hInt := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	hUUID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tr := &node{}
+	tr.InsertRoute(mGET, "/users/{id:int}", hInt)
+	tr.InsertRoute(mGET, "/orders/{id:uuid}", hUUID)
+
+	tests := []struct {
+		path string
+		h    http.Handler
+	}{
+		{"/users/42", hInt},
+		{"/users/-3", hInt},
+		{"/users/abc", nil},
+		{"/orders/0b6c2c8a-1e0a-4a8f-9d0c-2f1e3d4c5b6a", hUUID},
+		{"/orders/42", nil},
+	}
+	for _, tt := range tests {
+		_, handlers, _ := tr.FindRoute(NewRouteContext(), mGET, tt.path)
+		var got http.Handler
+		if handlers != nil && handlers[mGET] != nil {
+			got = handlers[mGET].handler
+		}
+		if fmt.Sprintf("%v", got) != fmt.Sprintf("%v", tt.h) {
+			t.Errorf("%s: unexpected handler match", tt.path)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package owl
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+)
+
+// Validator is implemented by request types checking their own values
+// once bound, see H.
+type Validator interface {
+	Validate() error
+}
+
+// H adapts a typed function to a Handler. The request is bound into a new
+// Req, a struct or a pointer to one:
+//
+//   - fields tagged param from the path parameters;
+//   - for POST, PUT and PATCH, fields tagged query from the query, and
+//     the others from the body with Binder.Auto;
+//   - for other methods, every field from the query with Binder.Query.
+//
+// A Req implementing Validator is validated next; errors other than an
+// *HTTPError are answered with 422 Unprocessable Entity. The response is
+// written like the ones of H2, with its status:
+//
+//	type CreateUserRequest struct {
+//		OrgID string `param:"org"`
+//		Name  string `json:"name"`
+//	}
+//
+//	func (r CreateUserRequest) Validate() error {
+//		if r.Name == "" {
+//			return errors.New("name is required")
+//		}
+//		return nil
+//	}
+//
+//	app.POST("/orgs/{org}/users", owl.H(func(c *owl.Ctx, req CreateUserRequest) (User, error) {
+//		return users.Create(c.Request.Context(), req.OrgID, req.Name)
+//	}))
+//
+// Routes registered with the Handler are documented with the Req and Resp
+// types in App.OpenAPI, and with 201 Created as the status of POST routes
+// or 200 OK otherwise; Doc can add to that.
+func H[Req, Resp any](fn func(c *Ctx, req Req) (Resp, error)) Handler {
+	return func(c *Ctx) error {
+		if c == typedProbe {
+			var req Req
+			var resp Resp
+			return typedTypes{request: req, response: resp}
+		}
+
+		var req Req
+		dst := interface{}(&req)
+		if rv := reflect.ValueOf(&req).Elem(); rv.Kind() == reflect.Ptr {
+			rv.Set(reflect.New(rv.Type().Elem()))
+			dst = req
+		}
+		if err := bindRequest(c, dst); err != nil {
+			return err
+		}
+		if v, ok := dst.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+			}
+		}
+
+		resp, err := fn(c, req)
+		if err != nil {
+			return err
+		}
+		v := interface{}(resp)
+		if rv := reflect.ValueOf(v); rv.IsValid() && isNilable(rv.Kind()) && rv.IsNil() {
+			v = nil // Typed nils get 204 No Content too
+		}
+		return writeResult(c, v)
+	}
+}
+
+// isNilable reports whether values of kind k can be nil.
+func isNilable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// bindRequest binds the request of c into the struct dst points to, see H.
+func bindRequest(c *Ctx, dst interface{}) error {
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		if c.Request.ContentLength != 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			if err := c.Bind().Auto(dst); err != nil {
+				return err
+			}
+		}
+		query := c.queryValues()
+		if err := bindTagged(dst, "query", func(name string) []string { return query[name] }); err != nil {
+			return err
+		}
+	default:
+		if err := c.Bind().Query(dst); err != nil {
+			return err
+		}
+	}
+	return bindTagged(dst, "param", func(name string) []string {
+		if rctx := c.routeContext(); rctx != nil {
+			for i, key := range rctx.URLParams.Keys {
+				if key == name {
+					return []string{rctx.URLParams.Values[i]}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// bindTagged sets the fields of the struct dst points to that are tagged
+// key from the values returned by lookup for the tag's name. Fields without
+// values are left untouched.
+func bindTagged(dst interface{}, key string, lookup func(name string) []string) error {
+	v := reflect.ValueOf(dst).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || !hasTag(f, key) {
+			continue
+		}
+		vals := lookup(tagName(f, key))
+		if len(vals) == 0 {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if err := bindField(field, f, vals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typedProbe is the Ctx given to the Handlers returned by H to ask for
+// their types instead of serving a request, see handlerDoc.
+var typedProbe = &Ctx{}
+
+// typedTypes is returned by the Handlers of H given typedProbe.
+type typedTypes struct {
+	request, response interface{}
+}
+
+func (typedTypes) Error() string { return "owl: typed handler probed" }
+
+// typedHandlerName is the name of the function of every Handler returned by
+// H, whatever its type parameters.
+var typedHandlerName = runtime.FuncForPC(reflect.ValueOf(H[struct{}, struct{}](nil)).Pointer()).Name()
+
+// handlerDoc returns the documentation of a Handler returned by H, for
+// routes of method, or nil. Other Handlers are never called.
+func handlerDoc(h Handler, method string) *Doc {
+	if h == nil {
+		return nil
+	}
+	if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn == nil || fn.Name() != typedHandlerName {
+		return nil
+	}
+	types, ok := h(typedProbe).(typedTypes)
+	if !ok {
+		return nil
+	}
+	status := http.StatusOK
+	if method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	return &Doc{Request: types.request, Responses: map[int]interface{}{status: types.response}}
+}
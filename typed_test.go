@@ -0,0 +1,113 @@
+package owl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createItemRequest struct {
+	Shop   string `param:"shop"`
+	Name   string `json:"name"`
+	Price  int    `json:"price"`
+	DryRun bool   `query:"dry_run"`
+}
+
+func (r *createItemRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type item struct {
+	Shop   string `json:"shop"`
+	Name   string `json:"name"`
+	Price  int    `json:"price"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type listItemsRequest struct {
+	Shop  string   `param:"shop"`
+	Limit int      `query:"limit"`
+	Tags  []string `query:"tag"`
+}
+
+func TestTypedHandler(t *testing.T) {
+	app := New()
+	app.POST("/shops/{shop}/items", H(func(c *Ctx, req createItemRequest) (item, error) {
+		return item{Shop: req.Shop, Name: req.Name, Price: req.Price, DryRun: req.DryRun}, nil
+	}))
+	app.GET("/shops/{shop}/items", H(func(c *Ctx, req *listItemsRequest) ([]string, error) {
+		return append([]string{req.Shop, strings.Repeat("x", req.Limit)}, req.Tags...), nil
+	}))
+	app.DELETE("/shops/{shop}", H(func(c *Ctx, req struct{}) (*item, error) {
+		return nil, nil
+	}))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/shops/owls/items?dry_run=true", `{"name":"perch","price":12}`)
+	if w.Code != http.StatusCreated || w.Body.String() != `{"shop":"owls","name":"perch","price":12,"dry_run":true}`+"\n" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("POST", "/shops/owls/items", `{"price":12}`); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "name is required") {
+		t.Fatalf("expected a validation error, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("POST", "/shops/owls/items", `{"name":`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a binding error, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/shops/owls/items?limit=3&tag=a&tag=b", ""); w.Code != 200 || w.Body.String() != `["owls","xxx","a","b"]`+"\n" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("DELETE", "/shops/owls", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for a nil response, got %d", w.Code)
+	}
+}
+
+func TestTypedHandlerOpenAPI(t *testing.T) {
+	app := New()
+	app.POST("/shops/{shop}/items", H(func(c *Ctx, req createItemRequest) (item, error) {
+		return item{}, nil
+	})).Doc(Doc{Summary: "Add an item"})
+	app.GET("/shops/{shop}/items", H(func(c *Ctx, req listItemsRequest) ([]item, error) {
+		return nil, nil
+	}))
+
+	spec := app.OpenAPI()
+	create := spec.Paths["/shops/{shop}/items"]["post"]
+	if create.Summary != "Add an item" || create.Responses["201"] == nil || create.Responses["201"].Content["application/json"].Schema.Ref != "#/components/schemas/item" {
+		t.Fatalf("expected the types of the handler next to the Doc, got %+v", create)
+	}
+	if len(create.Parameters) != 2 || create.Parameters[0].In != "path" || create.Parameters[1].Name != "dry_run" {
+		t.Fatalf("unexpected parameters %+v", create.Parameters)
+	}
+	body := create.RequestBody.Content["application/json"].Schema
+	if _, ok := body.Properties["Shop"]; ok || len(body.Properties) != 2 {
+		t.Fatalf("expected path and query fields out of the body, got %+v", body.Properties)
+	}
+
+	list := spec.Paths["/shops/{shop}/items"]["get"]
+	if len(list.Parameters) != 3 || list.Parameters[1].Name != "limit" || list.Parameters[2].Name != "tag" || list.Parameters[2].Schema.Type != "array" {
+		t.Fatalf("unexpected parameters %+v", list.Parameters)
+	}
+	if list.Responses["200"].Content["application/json"].Schema.Type != "array" {
+		t.Fatalf("unexpected responses %+v", list.Responses)
+	}
+
+	calls := 0
+	app.DELETE("/shops/{shop}", func(c *Ctx) error { calls++; return nil })
+	if calls != 0 || app.OpenAPI().Paths["/shops/{shop}"]["delete"].RequestBody != nil {
+		t.Fatalf("expected plain handlers to be left alone, got %d calls", calls)
+	}
+}